@@ -0,0 +1,56 @@
+// Command grant-role assigns a role to a user directly in the database. It is
+// meant for bootstrapping the first admin; afterwards roles are managed through
+// the admin API. The change is recorded in the audit trail with the user as
+// its own actor.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/storages/postgres"
+)
+
+func main() {
+	username := flag.String("username", "", "user to grant the role to")
+	role := flag.String("role", string(models.RoleAdmin), "role: user, support or admin")
+	reason := flag.String("reason", "", "reason recorded in the audit trail")
+	flag.Parse()
+
+	if *username == "" || *reason == "" || !models.Role(*role).Valid() {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger.Init()
+	cfg := config.Load()
+
+	db, err := postgres.NewPostgres(cfg.PostgresURL, cfg.DbMaxConns, cfg.DbMinConns, cfg.DbMaxLifetime)
+	if err != nil {
+		logger.L.Fatalw("failed to connect postgres", "error", err.Error())
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	user, err := postgres.NewUserRepo(db).GetUserByUsername(ctx, *username)
+	if err != nil {
+		logger.L.Fatalw("failed to find user", "username", *username, "error", err.Error())
+	}
+
+	entry := &models.AuditEntry{
+		ActorID: user.ID,
+		Action:  models.AuditRoleChange,
+		Reason:  *reason,
+	}
+	if err := postgres.NewAdminRepo(db).SetUserRole(ctx, user.ID, models.Role(*role), entry); err != nil {
+		logger.L.Fatalw("failed to set role", "username", *username, "error", err.Error())
+	}
+
+	fmt.Printf("user %s (%s) now has role %s\n", user.Username, user.ID, *role)
+}
@@ -0,0 +1,53 @@
+// Command ledger-verify recomputes wallet balances from journal lines and
+// reports every balance that drifted from the ledger. It exits with status 1
+// when drift or an unbalanced journal entry is found.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/storages/postgres"
+)
+
+func main() {
+	logger.Init()
+	cfg := config.Load()
+
+	db, err := postgres.NewPostgres(cfg.PostgresURL, cfg.DbMaxConns, cfg.DbMinConns, cfg.DbMaxLifetime)
+	if err != nil {
+		logger.L.Fatalw("failed to connect postgres", "error", err.Error())
+	}
+	defer db.Close()
+
+	report, err := services.NewLedgerService(postgres.NewLedgerRepo(db)).Verify(context.Background())
+	if err != nil {
+		logger.L.Fatalw("failed to verify ledger", "error", err.Error())
+	}
+
+	for _, e := range report.Unbalanced {
+		fmt.Printf("unbalanced entry %s: %s lines sum to %s\n",
+			e.EntryID, e.Currency, models.Money{Currency: e.Currency, Amount: e.Sum})
+	}
+
+	for _, d := range report.Drift {
+		fmt.Printf("drift wallet %s %s: balance %s, ledger %s\n",
+			d.WalletID, d.Currency,
+			models.Money{Currency: d.Currency, Amount: d.Projected},
+			models.Money{Currency: d.Currency, Amount: d.Ledger})
+	}
+
+	if !report.OK() {
+		fmt.Printf("ledger verification failed: %d unbalanced entries, %d drifted balances\n",
+			len(report.Unbalanced), len(report.Drift))
+		db.Close()
+		os.Exit(1)
+	}
+
+	fmt.Println("ledger is consistent with wallet balances")
+}
@@ -5,11 +5,22 @@ import (
 	"os/signal"
 	"syscall"
 
+	"gw-currency-wallet/internal/cleanup"
 	"gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/currencies"
+	"gw-currency-wallet/internal/fees"
 	grpcClient "gw-currency-wallet/internal/grpc"
 	"gw-currency-wallet/internal/handlers"
+	"gw-currency-wallet/internal/holds"
+	"gw-currency-wallet/internal/jwks"
 	"gw-currency-wallet/internal/kafka"
+	"gw-currency-wallet/internal/mailer"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/orders"
+	"gw-currency-wallet/internal/outbox"
 	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/secretbox"
+	"gw-currency-wallet/internal/schedules"
 	"gw-currency-wallet/internal/services"
 	"gw-currency-wallet/internal/storages/postgres"
 	"gw-currency-wallet/internal/transport/http/middleware"
@@ -18,6 +29,7 @@ import (
 	_ "gw-currency-wallet/internal/docs"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"google.golang.org/grpc"
@@ -58,38 +70,261 @@ func main() {
 
 	walletRepo := postgres.NewWalletRepo(db)
 	userRepo := postgres.NewUserRepo(db)
+	transactionRepo := postgres.NewTransactionRepo(db)
+	idempotencyRepo := postgres.NewIdempotencyRepo(db)
+	outboxRepo := postgres.NewOutboxRepo(db)
+	currencyRepo := postgres.NewCurrencyRepo(db)
+	quoteRepo := postgres.NewQuoteRepo(db)
+	tokenRepo := postgres.NewTokenRepo(db)
+	adminRepo := postgres.NewAdminRepo(db)
+	loginRepo := postgres.NewLoginRepo(db)
+	emailTokenRepo := postgres.NewEmailTokenRepo(db)
+	twoFactorRepo := postgres.NewTwoFactorRepo(db)
+	apiKeyRepo := postgres.NewAPIKeyRepo(db)
+	limitRepo := postgres.NewLimitRepo(db)
+	feeRepo := postgres.NewFeeRepo(db)
+	scheduleRepo := postgres.NewScheduleRepo(db)
+	orderRepo := postgres.NewOrderRepo(db)
+	unitOfWork := postgres.NewUnitOfWork(db)
 
 	cache := utils.NewRateCache(cfg.CacheRatesLifetime)
 
-	jwtManager := services.NewJWTManager(cfg.JWTSecret)
-	authService := services.NewAuthService(userRepo, walletRepo, jwtManager)
+	// A new key is published one check interval before it signs, so every instance
+	// has loaded it by then. A key stays published while tokens signed with it may
+	// be alive: the switch can be late by one check interval, and the last token
+	// lives accessTTL more.
+	keys, err := jwks.NewKeySet(cfg.JWTKeysDir, cfg.JWTAlgorithm, cfg.JWTKeyRotateAfter,
+		cfg.JWTKeyRotateAfter+cfg.JWTKeyCheckInterval+cfg.AccessTokenTTL, cfg.JWTKeyCheckInterval)
+	if err != nil {
+		logger.L.Fatalw("failed to load JWT keys", "error", err.Error())
+	}
+
+	// Usernames and IPs share the window and lockout durations; an IP usually
+	// tries many usernames, so it is allowed more failures.
+	userLockout := models.LockoutRule{
+		MaxFailures: cfg.LoginMaxUserFailures,
+		Window:      cfg.LoginFailureWindow,
+		BaseLockout: cfg.LoginLockoutBase,
+		MaxLockout:  cfg.LoginLockoutMax,
+		ResetAfter:  cfg.LoginLockoutResetAfter,
+	}
+	ipLockout := userLockout
+	ipLockout.MaxFailures = cfg.LoginMaxIPFailures
+
+	totpBox, err := secretbox.NewFromBase64(cfg.TOTPEncryptionKey)
+	if err != nil {
+		logger.L.Fatalw("invalid TOTP_ENCRYPTION_KEY", "error", err.Error())
+	}
+
+	stepUpThresholds, err := models.ParseStepUpThresholds(cfg.StepUpAmount, cfg.StepUpAmounts)
+	if err != nil {
+		logger.L.Fatalw("invalid STEP_UP_AMOUNT or STEP_UP_AMOUNTS", "error", err.Error())
+	}
+
+	houseWalletID, err := uuid.Parse(cfg.HouseWalletID)
+	if err != nil {
+		logger.L.Fatalw("invalid HOUSE_WALLET_ID", "error", err.Error())
+	}
+
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, totpBox, cfg.TOTPIssuer, stepUpThresholds,
+		cfg.TwoFactorChallengeTTL, cfg.TwoFactorMaxAttempts, cfg.TwoFactorLockout)
+
+	jwtVerifier := services.NewJWTVerifier(keys)
+	jwtManager := services.NewJWTManager(keys, jwtVerifier, cfg.AccessTokenTTL)
 	exchangeClient := grpcClient.NewExchangeAdapter(grpcConn)
-	walletService := services.NewWalletService(walletRepo, exchangeClient, cache, producer, cfg.WalletMaxInflight)
+	feeService := services.NewFeeService(feeRepo, houseWalletID)
+	walletService := services.NewWalletService(walletRepo, currencyRepo, quoteRepo, exchangeClient, cache, cfg.ExchangeQuoteTTL, cfg.WalletMaxInflight, cfg.MaxWalletsPerUser, feeService)
+	authService := services.NewAuthService(userRepo, walletService, tokenRepo, loginRepo, jwtManager, cfg.RefreshTokenTTL, models.LoginPolicy{Username: userLockout, IP: ipLockout}, twoFactorService, unitOfWork)
+
+	syncCtx, cancelSync := context.WithTimeout(context.Background(), cfg.GRPCExchangeTimeout)
+	if err := walletService.SyncCurrencies(syncCtx); err != nil {
+		logger.L.Warnw("failed to sync currencies with exchanger", "error", err.Error())
+
+		if err := walletService.ReloadCurrencies(syncCtx); err != nil {
+			logger.L.Warnw("failed to load currencies", "error", err.Error())
+		}
+	}
+	cancelSync()
+
+	var mail mailer.Mailer
+	if cfg.Mailer == "smtp" {
+		mail = mailer.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		mail, err = mailer.NewLogMailer(cfg.MailDir, cfg.MailFrom)
+		if err != nil {
+			logger.L.Fatalw("failed to create mail directory", "error", err.Error())
+		}
+	}
+	accountService := services.NewAccountService(userRepo, emailTokenRepo, mail, cfg.PublicBaseURL,
+		cfg.EmailVerificationTTL, cfg.PasswordResetTTL)
+
+	authHandler := handlers.NewAuthHandler(authService, accountService, jwtManager)
+	transactionService := services.NewTransactionService(transactionRepo)
+	transferService := services.NewTransferService(walletRepo, userRepo)
+	holdService := services.NewHoldService(walletRepo, feeService, cfg.HoldDefaultTTL, cfg.HoldMaxTTL)
+	adminService := services.NewAdminService(adminRepo, userRepo, walletRepo, limitRepo, feeRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	limitService := services.NewLimitService(limitRepo)
+	orderService := services.NewOrderService(orderRepo, walletRepo, feeService, unitOfWork, cfg.OrderDefaultTTL, cfg.OrderMaxTTL)
+	scheduleService := services.NewScheduleService(scheduleRepo, walletService, unitOfWork, cfg.MaxSchedulesPerUser, cfg.ScheduleMaxFailures)
 
-	authHandler := handlers.NewAuthHandler(authService, walletService, jwtManager)
 	walletHandler := handlers.NewWalletHandler(walletService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	holdHandler := handlers.NewHoldHandler(holdService)
+	jwksHandler := handlers.NewJWKSHandler(keys)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	limitHandler := handlers.NewLimitHandler(limitService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	orderHandler := handlers.NewOrderHandler(orderService)
 
 	r := gin.Default()
+	// ClientIP reads X-Forwarded-For only from trusted proxies. Otherwise a forged
+	// header would bypass the login lockout and the IP allowlists of API keys.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.L.Fatalw("invalid trusted proxies", "error", err.Error())
+	}
 
 	// Swagger route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
 	r.POST("/api/v1/register", authHandler.Register)
 	r.POST("/api/v1/login", authHandler.Login)
+	r.POST("/api/v1/login/2fa", authHandler.LoginTwoFactor)
+	r.POST("/api/v1/token/refresh", authHandler.Refresh)
+	r.POST("/api/v1/email/verify", accountHandler.VerifyEmail)
+	r.POST("/api/v1/password/forgot", accountHandler.RequestPasswordReset)
+	r.POST("/api/v1/password/reset", accountHandler.ResetPassword)
+	r.GET("/api/v1/currencies", walletHandler.GetCurrencies)
+
+	jwt := middleware.JWT(jwtVerifier, tokenRepo)
+	idempotent := middleware.Idempotency(idempotencyRepo, cfg.IdempotencyLockTimeout)
+	stepUp := middleware.StepUp(twoFactorService)
+
+	// Routes that machine clients may call with an API key of the given scope.
+	machine := r.Group("/")
+	machine.Use(middleware.APIKeyOrJWT(apiKeyService, jwt))
+	{
+		machine.GET("/api/v1/balance", middleware.RequireScope(models.ScopeBalanceRead), walletHandler.GetWallet)
+		machine.POST("/api/v1/wallet/deposit", middleware.RequireScope(models.ScopeWalletDeposit), idempotent, walletHandler.Deposit)
+		machine.POST("/api/v1/wallet/withdraw", middleware.RequireScope(models.ScopeWalletWithdraw),
+			middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, walletHandler.Withdraw)
+		machine.POST("/api/v1/exchange", middleware.RequireScope(models.ScopeWalletExchange), idempotent, walletHandler.Exchange)
+		machine.POST("/api/v1/exchange/quote", middleware.RequireScope(models.ScopeWalletExchange), walletHandler.QuoteExchange)
+		machine.POST("/api/v1/wallets/:id/deposit", middleware.RequireScope(models.ScopeWalletDeposit), idempotent, walletHandler.DepositToWallet)
+		machine.POST("/api/v1/wallets/:id/withdraw", middleware.RequireScope(models.ScopeWalletWithdraw),
+			middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, walletHandler.WithdrawFromWallet)
+		machine.POST("/api/v1/wallets/:id/exchange", middleware.RequireScope(models.ScopeWalletExchange), idempotent, walletHandler.ExchangeInWallet)
+		machine.POST("/api/v1/wallets/:id/exchange/quote", middleware.RequireScope(models.ScopeWalletExchange), walletHandler.QuoteExchangeInWallet)
+	}
 
 	authUser := r.Group("/")
-	authUser.Use(middleware.JWT(cfg.JWTSecret))
+	authUser.Use(jwt)
 	{
-		authUser.GET("/api/v1/balance", walletHandler.GetWallet)
-		authUser.POST("/api/v1/wallet/deposit", walletHandler.Deposit)
-		authUser.POST("/api/v1/wallet/withdraw", walletHandler.Withdraw)
-		authUser.POST("/api/v1/exchange", walletHandler.Exchange)
+		authUser.POST("/api/v1/logout", authHandler.Logout)
+		authUser.POST("/api/v1/logout/all", authHandler.LogoutAll)
+		authUser.POST("/api/v1/email/verification", accountHandler.ResendVerification)
+		authUser.POST("/api/v1/2fa/totp/enroll", twoFactorHandler.EnrollTOTP)
+		authUser.POST("/api/v1/2fa/totp/confirm", twoFactorHandler.ConfirmTOTP)
+		authUser.POST("/api/v1/2fa/totp/disable", twoFactorHandler.DisableTOTP)
+		authUser.POST("/api/v1/api-keys", middleware.RequireTwoFactor(twoFactorService), apiKeyHandler.CreateAPIKey)
+		authUser.GET("/api/v1/api-keys", apiKeyHandler.ListAPIKeys)
+		authUser.DELETE("/api/v1/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+		authUser.GET("/api/v1/wallets", walletHandler.ListWallets)
+		authUser.POST("/api/v1/wallets", walletHandler.CreateWallet)
+		authUser.POST("/api/v1/wallets/transfers", idempotent, walletHandler.TransferBetweenWallets)
+		authUser.GET("/api/v1/wallets/:id", walletHandler.GetWalletByID)
+		authUser.PATCH("/api/v1/wallets/:id", walletHandler.RenameWallet)
+		authUser.POST("/api/v1/wallets/:id/close", walletHandler.CloseWallet)
+
+		authUser.POST("/api/v1/transfers", middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, transferHandler.Transfer)
 		authUser.GET("/api/v1/exchange/rates", walletHandler.GetAllRates)
+		authUser.GET("/api/v1/transactions", transactionHandler.ListTransactions)
+		authUser.GET("/api/v1/limits", limitHandler.GetLimits)
+
+		authUser.POST("/api/v1/holds", middleware.RequireVerifiedEmail(userRepo), idempotent, holdHandler.CreateHold)
+		authUser.GET("/api/v1/holds/:id", holdHandler.GetHold)
+		authUser.POST("/api/v1/holds/:id/capture", middleware.RequireVerifiedEmail(userRepo),
+			idempotent, middleware.HoldCaptureStepUp(twoFactorService, holdService), holdHandler.CaptureHold)
+		authUser.POST("/api/v1/holds/:id/void", idempotent, holdHandler.VoidHold)
+
+		authUser.GET("/api/v1/schedules", scheduleHandler.ListSchedules)
+		authUser.POST("/api/v1/schedules", scheduleHandler.CreateSchedule)
+		authUser.GET("/api/v1/schedules/:id", scheduleHandler.GetSchedule)
+		authUser.PATCH("/api/v1/schedules/:id", scheduleHandler.UpdateSchedule)
+		authUser.DELETE("/api/v1/schedules/:id", scheduleHandler.DeleteSchedule)
+		authUser.GET("/api/v1/schedules/:id/runs", scheduleHandler.ListScheduleRuns)
+
+		authUser.GET("/api/v1/orders", orderHandler.ListOrders)
+		authUser.POST("/api/v1/orders", middleware.RequireVerifiedEmail(userRepo), idempotent, orderHandler.PlaceOrder)
+		authUser.GET("/api/v1/orders/:id", orderHandler.GetOrder)
+		authUser.POST("/api/v1/orders/:id/cancel", idempotent, orderHandler.CancelOrder)
+
+		requireAdmin := middleware.RequireRole(models.RoleAdmin)
+		admin := authUser.Group("/api/v1/admin", middleware.RequireRole(models.RoleSupport))
+		admin.GET("/users", adminHandler.FindUser)
+		admin.GET("/users/:id", adminHandler.GetUser)
+		admin.GET("/users/:id/wallet", adminHandler.GetUserWallet)
+		admin.POST("/users/:id/wallet/freeze", adminHandler.FreezeWallet)
+		admin.POST("/users/:id/wallet/unfreeze", adminHandler.UnfreezeWallet)
+		admin.PUT("/users/:id/wallet/status", requireAdmin, adminHandler.SetWalletStatus)
+		admin.POST("/users/:id/wallet/adjustments", requireAdmin, idempotent, adminHandler.AdjustBalance)
+		admin.GET("/users/:id/wallets", adminHandler.ListUserWallets)
+		admin.GET("/wallets/:walletId", adminHandler.GetWallet)
+		admin.POST("/wallets/:walletId/freeze", adminHandler.FreezeWalletByID)
+		admin.POST("/wallets/:walletId/unfreeze", adminHandler.UnfreezeWalletByID)
+		admin.PUT("/wallets/:walletId/status", requireAdmin, adminHandler.SetWalletStatusByID)
+		admin.POST("/wallets/:walletId/adjustments", requireAdmin, idempotent, adminHandler.AdjustBalanceByID)
+		admin.PUT("/users/:id/role", requireAdmin, adminHandler.SetUserRole)
+		admin.PUT("/users/:id/status", requireAdmin, adminHandler.SetAccountStatus)
+		admin.GET("/users/:id/status-history", adminHandler.GetStatusHistory)
+		admin.POST("/users/:id/unlock", requireAdmin, adminHandler.UnlockLogin)
+		admin.GET("/users/:id/limits", adminHandler.ListUserLimits)
+		admin.PUT("/users/:id/limits", requireAdmin, adminHandler.SetUserLimit)
+		admin.GET("/limits", adminHandler.ListDefaultLimits)
+		admin.PUT("/limits", requireAdmin, adminHandler.SetDefaultLimit)
+		admin.GET("/fees", adminHandler.ListFeeRules)
+		admin.PUT("/fees", requireAdmin, adminHandler.SetFeeRule)
+		admin.DELETE("/fees/:id", requireAdmin, adminHandler.DeleteFeeRule)
+		admin.GET("/audit", requireAdmin, adminHandler.ListAudit)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	relay := outbox.NewRelay(outboxRepo, producer, cfg.OutboxPollInterval, cfg.OutboxBatchSize, cfg.OutboxMaxBackoff)
+	go relay.Run(ctx)
+
+	sweeper := holds.NewSweeper(walletRepo, cfg.HoldSweepInterval, cfg.HoldSweepBatchSize)
+	go sweeper.Run(ctx)
+
+	feeSettler := fees.NewSettler(feeRepo, houseWalletID, cfg.FeeSettleInterval)
+	go feeSettler.Run(ctx)
+
+	idempotencyJanitor := cleanup.NewJanitor("idempotency keys", func(ctx context.Context, limit int) (int, error) {
+		return idempotencyRepo.PurgeIdempotencyKeys(ctx, cfg.IdempotencyKeyTTL, limit)
+	}, cfg.CleanupInterval, cfg.CleanupBatchSize)
+	go idempotencyJanitor.Run(ctx)
+
+	tokenJanitor := cleanup.NewJanitor("expired tokens", tokenRepo.PurgeExpiredTokens, cfg.CleanupInterval, cfg.CleanupBatchSize)
+	go tokenJanitor.Run(ctx)
+
+	scheduleRunner := schedules.NewRunner(scheduleService, cfg.SchedulePollInterval, cfg.ScheduleBatchSize)
+	go scheduleRunner.Run(ctx)
+
+	currencyRefresher := currencies.NewRefresher(walletService, cfg.CurrencyRefreshInterval)
+	go currencyRefresher.Run(ctx)
+
+	orderWatcher := orders.NewWatcher(orderService, exchangeClient, cfg.OrderPollInterval, cfg.GRPCExchangeTimeout, cfg.OrderBatchSize)
+	go orderWatcher.Run(ctx)
+
+	go keys.Run(ctx, cfg.JWTKeyCheckInterval)
+
 	go func() {
 		if err := r.Run(cfg.HTTPAddr); err != nil {
 			logger.L.Fatalw("failed to run HTTP server", "error", err.Error())
@@ -0,0 +1,63 @@
+package cleanup
+
+import (
+	"context"
+	"gw-currency-wallet/internal/pkg/logger"
+	"time"
+)
+
+// PurgeFunc deletes up to limit outdated rows and returns their number.
+type PurgeFunc func(ctx context.Context, limit int) (int, error)
+
+// Janitor periodically deletes rows that are no longer needed, such as old
+// idempotency keys, so that their tables do not grow without bound.
+type Janitor struct {
+	name      string
+	purge     PurgeFunc
+	interval  time.Duration
+	batchSize int
+}
+
+// NewJanitor creates a janitor; name describes the purged rows in the logs.
+func NewJanitor(name string, purge PurgeFunc, interval time.Duration, batchSize int) *Janitor {
+	return &Janitor{
+		name:      name,
+		purge:     purge,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run purges rows until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	logger.L.Infow("Janitor started", "rows", j.name)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for j.sweep(ctx) == j.batchSize {
+			// Полная пачка: вероятно, устарели ещё строки, продолжаем сразу.
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.L.Infow("Janitor stopped", "rows", j.name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep purges one batch and returns the number of deleted rows.
+func (j *Janitor) sweep(ctx context.Context) int {
+	purged, err := j.purge(ctx, j.batchSize)
+	if err != nil && ctx.Err() == nil {
+		logger.L.Errorw("Failed to purge rows", "rows", j.name, "purged", purged, "error", err.Error())
+	}
+	if purged > 0 {
+		logger.L.Infow("Outdated rows purged", "rows", j.name, "count", purged)
+	}
+
+	return purged
+}
@@ -0,0 +1,53 @@
+package cron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/cron"
+)
+
+func TestNext(t *testing.T) {
+	// Friday, 2026-10-16 10:30 UTC.
+	from := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * FRI", time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 1st of the month or any Monday.
+		{"0 0 1 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		s, err := cron.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@yearly"} {
+		if _, err := cron.Parse(expr); !errors.Is(err, cron.ErrInvalidExpression) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidExpression", expr, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package currencies
+
+import (
+	"context"
+	"gw-currency-wallet/internal/pkg/logger"
+	"time"
+)
+
+// Loader reloads the currencies registry from the database.
+type Loader interface {
+	ReloadCurrencies(ctx context.Context) error
+}
+
+// Refresher periodically reloads the currencies registry, so that currencies
+// added, disabled or rescaled in the database by another instance or by an
+// operator reach this process without waiting for an exchanger sync.
+type Refresher struct {
+	loader   Loader
+	interval time.Duration
+}
+
+func NewRefresher(loader Loader, interval time.Duration) *Refresher {
+	return &Refresher{
+		loader:   loader,
+		interval: interval,
+	}
+}
+
+// Run reloads the registry until ctx is cancelled.
+func (r *Refresher) Run(ctx context.Context) {
+	logger.L.Info("Currency refresher started")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Currency refresher stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// При ошибке остаётся прежний реестр, следующая попытка — через interval.
+		if err := r.loader.ReloadCurrencies(ctx); err != nil && ctx.Err() == nil {
+			logger.L.Errorw("Failed to reload currencies", "error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package fees
+
+import (
+	"context"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Settler periodically credits the fees collected on the fee account to the
+// house wallet. Operations only post their fees to the fee account, so they
+// never wait for each other on the house wallet balance.
+type Settler struct {
+	store         storages.FeeStorage
+	houseWalletID uuid.UUID
+	interval      time.Duration
+}
+
+func NewSettler(store storages.FeeStorage, houseWalletID uuid.UUID, interval time.Duration) *Settler {
+	return &Settler{
+		store:         store,
+		houseWalletID: houseWalletID,
+		interval:      interval,
+	}
+}
+
+// Run settles fees until ctx is cancelled.
+func (s *Settler) Run(ctx context.Context) {
+	logger.L.Info("Fee settler started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.settle(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Fee settler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Settler) settle(ctx context.Context) {
+	settled, err := s.store.SettleFees(ctx, s.houseWalletID)
+	if err != nil && ctx.Err() == nil {
+		logger.L.Errorw("Failed to settle fees", "houseWalletID", s.houseWalletID, "error", err.Error())
+	}
+	if settled > 0 {
+		logger.L.Infow("Fees settled to the house wallet", "currencies", settled)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service *services.AccountService
+}
+
+func NewAccountHandler(service *services.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Confirm the user's email with the token from the verification email. Each token works once
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        request body models.VerifyEmailRequest true "Verification token"
+// @Success      200 {object} models.Response "Email verified"
+// @Failure      400 {object} models.Response "Invalid, expired or already used token"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /email/verify [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		emailTokenError(c, "Email verification failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "Email verified"},
+	})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification email to the current user. Links from earlier emails stop working
+// @Tags         account
+// @Security     BearerAuth
+// @Produce      json
+// @Success      202 {object} models.Response "Email sent"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      409 {object} models.Response "Email is already verified"
+// @Failure      500 {object} models.Response "Failed to send email"
+// @Router       /email/verification [post]
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	err := h.service.SendVerificationEmail(c.Request.Context(), userID)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrEmailAlreadyVerified):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgEmailVerified,
+		})
+		return
+	default:
+		logger.L.Errorw("Failed to send verification email", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgEmailSendFailed,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "Verification email sent"},
+	})
+}
+
+// RequestPasswordReset godoc
+// @Summary      Request password reset
+// @Description  Send a password reset link to the email if it belongs to an account. The response is the same for unknown emails
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        request body models.PasswordResetRequest true "Account email"
+// @Success      202 {object} models.Response "Reset email sent if the account exists"
+// @Failure      400 {object} models.Response "Invalid request data"
+// @Router       /password/forgot [post]
+func (h *AccountHandler) RequestPasswordReset(c *gin.Context) {
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// Ошибка отправки не раскрывается клиенту: иначе по ней можно
+	// отличить зарегистрированный адрес от незарегистрированного.
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		logger.L.Errorw("Failed to send password reset email", "error", err.Error())
+	}
+
+	c.JSON(http.StatusAccepted, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "If the email is registered, a reset link has been sent"},
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password with the token from the reset email. All sessions of the user are ended
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success      200 {object} models.Response "Password changed"
+// @Failure      400 {object} models.Response "Invalid request data or invalid, expired or already used token"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /password/reset [post]
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		emailTokenError(c, "Password reset failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "Password changed, please log in again"},
+	})
+}
+
+func emailTokenError(c *gin.Context, msg string, err error) {
+	if errors.Is(err, models.ErrInvalidEmailToken) {
+		logger.L.Warnw(msg, "ip", c.ClientIP(), "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidEmailToken,
+		})
+		return
+	}
+
+	logger.L.Errorw(msg, "error", err.Error())
+	c.JSON(http.StatusInternalServerError, models.Response{
+		Success: false,
+		Error:   messages.MsgInternalError,
+	})
+}
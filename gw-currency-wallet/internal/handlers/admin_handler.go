@@ -0,0 +1,1039 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	service *services.AdminService
+}
+
+func NewAdminHandler(service *services.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+// FindUser godoc
+// @Summary      Find user
+// @Description  Look up a user by username (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username query string true "Username"
+// @Success      200 {object} models.Response{data=models.User} "User found"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users [get]
+func (h *AdminHandler) FindUser(c *gin.Context) {
+	username := c.Query("username")
+	if username == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: "username is required",
+		})
+		return
+	}
+
+	user, err := h.service.FindUser(c, uuid.Nil, username)
+	if err != nil {
+		adminError(c, "Find user failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: user})
+}
+
+// GetUser godoc
+// @Summary      Get user
+// @Description  Get a user by id (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} models.Response{data=models.User} "User found"
+// @Failure      400 {object} models.Response "Invalid user id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.FindUser(c, userID, "")
+	if err != nil {
+		adminError(c, "Get user failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: user})
+}
+
+// GetUserWallet godoc
+// @Summary      Get user main wallet
+// @Description  Get the user's main wallet with balances, held amounts and status (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet"
+// @Failure      400 {object} models.Response "Invalid user id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/wallet [get]
+func (h *AdminHandler) GetUserWallet(c *gin.Context) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.GetUserWallet(c, userID)
+	if err != nil {
+		adminError(c, "Get user wallet failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// ListUserWallets godoc
+// @Summary      List user wallets
+// @Description  List all wallets of a user, including closed ones, with balances and status (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} models.Response{data=[]models.Wallet} "Wallets"
+// @Failure      400 {object} models.Response "Invalid user id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/wallets [get]
+func (h *AdminHandler) ListUserWallets(c *gin.Context) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.service.ListUserWallets(c, userID)
+	if err != nil {
+		adminError(c, "List user wallets failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallets})
+}
+
+// GetWallet godoc
+// @Summary      Get wallet
+// @Description  Get any wallet by id with balances, held amounts and status (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        walletId path string true "Wallet ID"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet"
+// @Failure      400 {object} models.Response "Invalid wallet id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Router       /admin/wallets/{walletId} [get]
+func (h *AdminHandler) GetWallet(c *gin.Context) {
+	walletID, ok := targetWalletID(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.GetWallet(c, walletID)
+	if err != nil {
+		adminError(c, "Get wallet failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// FreezeWallet godoc
+// @Summary      Freeze main wallet
+// @Description  Block every money movement on the user's main wallet (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet frozen"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Failure      409 {object} models.Response "Wallet is already frozen or closed"
+// @Router       /admin/users/{id}/wallet/freeze [post]
+func (h *AdminHandler) FreezeWallet(c *gin.Context) {
+	h.setWalletFrozen(c, h.mainWalletID, true)
+}
+
+// UnfreezeWallet godoc
+// @Summary      Unfreeze main wallet
+// @Description  Return the user's main wallet to the active status (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet unfrozen"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Failure      409 {object} models.Response "Wallet is already active or closed"
+// @Router       /admin/users/{id}/wallet/unfreeze [post]
+func (h *AdminHandler) UnfreezeWallet(c *gin.Context) {
+	h.setWalletFrozen(c, h.mainWalletID, false)
+}
+
+// FreezeWalletByID godoc
+// @Summary      Freeze wallet
+// @Description  Block every money movement on the wallet (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        walletId path string true "Wallet ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet frozen"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Wallet is already frozen or closed"
+// @Router       /admin/wallets/{walletId}/freeze [post]
+func (h *AdminHandler) FreezeWalletByID(c *gin.Context) {
+	h.setWalletFrozen(c, targetWalletID, true)
+}
+
+// UnfreezeWalletByID godoc
+// @Summary      Unfreeze wallet
+// @Description  Return the wallet to the active status (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        walletId path string true "Wallet ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet unfrozen"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Wallet is already active or closed"
+// @Router       /admin/wallets/{walletId}/unfreeze [post]
+func (h *AdminHandler) UnfreezeWalletByID(c *gin.Context) {
+	h.setWalletFrozen(c, targetWalletID, false)
+}
+
+func (h *AdminHandler) setWalletFrozen(c *gin.Context, target walletTarget, frozen bool) {
+	actorID, ok := adminActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgReasonRequired,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	walletID, ok := target(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.SetWalletFrozen(c, actorID, walletID, frozen, req.Reason)
+	if err != nil {
+		adminError(c, "Wallet freeze change failed", err)
+		return
+	}
+
+	logger.L.Infow("Wallet freeze changed", "actorID", actorID, "userID", wallet.UserID, "walletID", wallet.ID, "frozen", frozen)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// SetWalletStatus godoc
+// @Summary      Set main wallet status
+// @Description  Move the user's main wallet to another status: active, debit_blocked (incoming money only), frozen or closed. Only a wallet without funds can be closed, and a closed wallet stays closed (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.SetStatusRequest true "Status"
+// @Success      200 {object} models.Response{data=models.Wallet} "Status changed"
+// @Failure      400 {object} models.Response "Invalid request or status"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Failure      409 {object} models.Response "Transition not allowed or wallet has funds"
+// @Router       /admin/users/{id}/wallet/status [put]
+func (h *AdminHandler) SetWalletStatus(c *gin.Context) {
+	h.setWalletStatus(c, h.mainWalletID)
+}
+
+// SetWalletStatusByID godoc
+// @Summary      Set wallet status
+// @Description  Move the wallet to another status: active, debit_blocked (incoming money only), frozen or closed. Only a wallet without funds can be closed, and a closed wallet stays closed (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        walletId path string true "Wallet ID"
+// @Param        request body models.SetStatusRequest true "Status"
+// @Success      200 {object} models.Response{data=models.Wallet} "Status changed"
+// @Failure      400 {object} models.Response "Invalid request or status"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Transition not allowed or wallet has funds"
+// @Router       /admin/wallets/{walletId}/status [put]
+func (h *AdminHandler) SetWalletStatusByID(c *gin.Context) {
+	h.setWalletStatus(c, targetWalletID)
+}
+
+func (h *AdminHandler) setWalletStatus(c *gin.Context, target walletTarget) {
+	actorID, ok := adminActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	walletID, ok := target(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.SetWalletStatus(c, actorID, walletID, req.Status, req.Reason)
+	if err != nil {
+		adminError(c, "Wallet status change failed", err)
+		return
+	}
+
+	logger.L.Infow("Wallet status changed", "actorID", actorID, "userID", wallet.UserID, "walletID", wallet.ID, "status", req.Status)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// AdjustBalance godoc
+// @Summary      Adjust main wallet balance
+// @Description  Post a manual balance correction to the user's main wallet, recorded in the audit trail; a negative amount debits the wallet (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.AdjustBalanceRequest true "Adjustment"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response{data=models.Wallet} "Balance adjusted"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Router       /admin/users/{id}/wallet/adjustments [post]
+func (h *AdminHandler) AdjustBalance(c *gin.Context) {
+	h.adjustBalance(c, h.mainWalletID)
+}
+
+// AdjustBalanceByID godoc
+// @Summary      Adjust wallet balance
+// @Description  Post a manual balance correction to the wallet, recorded in the audit trail; a negative amount debits the wallet (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        walletId path string true "Wallet ID"
+// @Param        request body models.AdjustBalanceRequest true "Adjustment"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response{data=models.Wallet} "Balance adjusted"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Wallet is closed, or request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Router       /admin/wallets/{walletId}/adjustments [post]
+func (h *AdminHandler) AdjustBalanceByID(c *gin.Context) {
+	h.adjustBalance(c, targetWalletID)
+}
+
+func (h *AdminHandler) adjustBalance(c *gin.Context, target walletTarget) {
+	actorID, ok := adminActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdjustBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	delta, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	walletID, ok := target(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.AdjustBalance(c, actorID, walletID, delta, req.Reason)
+	if err != nil {
+		adminError(c, "Balance adjustment failed", err)
+		return
+	}
+
+	logger.L.Infow("Balance adjusted", "actorID", actorID, "userID", wallet.UserID, "walletID", wallet.ID,
+		"currency", delta.Currency, "amount", delta.String())
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// SetUserRole godoc
+// @Summary      Set user role
+// @Description  Grant a role to a user (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.SetRoleRequest true "Role"
+// @Success      200 {object} models.Response{data=models.User} "Role changed"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/role [put]
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	actorID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.SetUserRole(c, actorID, userID, req.Role, req.Reason)
+	if err != nil {
+		adminError(c, "Role change failed", err)
+		return
+	}
+
+	logger.L.Infow("User role changed", "actorID", actorID, "userID", userID, "role", req.Role)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: user})
+}
+
+// SetAccountStatus godoc
+// @Summary      Set account status
+// @Description  Move the user's account to another status: active, debit_blocked, frozen or closed. The account status applies to all its wallets on top of their own. Only an account without funds can be closed, and a closed account stays closed (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.SetStatusRequest true "Status"
+// @Success      200 {object} models.Response{data=models.User} "Status changed"
+// @Failure      400 {object} models.Response "Invalid request or status"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Failure      409 {object} models.Response "Transition not allowed or wallets have funds"
+// @Router       /admin/users/{id}/status [put]
+func (h *AdminHandler) SetAccountStatus(c *gin.Context) {
+	actorID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.SetAccountStatus(c, actorID, userID, req.Status, req.Reason)
+	if err != nil {
+		adminError(c, "Account status change failed", err)
+		return
+	}
+
+	logger.L.Infow("Account status changed", "actorID", actorID, "userID", userID, "status", req.Status)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: user})
+}
+
+// GetStatusHistory godoc
+// @Summary      Status history
+// @Description  List status changes of the user's account and wallets with the staff member and reason, newest first (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} models.Response{data=[]models.StatusTransition} "Status history"
+// @Failure      400 {object} models.Response "Invalid user id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/status-history [get]
+func (h *AdminHandler) GetStatusHistory(c *gin.Context) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.StatusHistory(c, userID)
+	if err != nil {
+		adminError(c, "Get status history failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: history})
+}
+
+// UnlockLogin godoc
+// @Summary      Unlock login
+// @Description  Lift the lockout imposed on the user's username after repeated failed logins and reset the failure counter (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response "Login unlocked"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockLogin(c *gin.Context) {
+	actorID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgReasonRequired,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wasLocked, err := h.service.UnlockLogin(c, actorID, userID, req.Reason)
+	if err != nil {
+		adminError(c, "Login unlock failed", err)
+		return
+	}
+
+	logger.L.Infow("Login unlocked", "actorID", actorID, "userID", userID, "wasLocked", wasLocked)
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data: gin.H{
+			"user_id":    userID.String(),
+			"was_locked": wasLocked,
+		},
+	})
+}
+
+// ListDefaultLimits godoc
+// @Summary      Default spending limits
+// @Description  List system default spending limits that apply to users without their own (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.SpendingLimit} "Default limits"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Router       /admin/limits [get]
+func (h *AdminHandler) ListDefaultLimits(c *gin.Context) {
+	limits, err := h.service.ListLimits(c, nil)
+	if err != nil {
+		adminError(c, "List default limits failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: limits})
+}
+
+// SetDefaultLimit godoc
+// @Summary      Set default spending limit
+// @Description  Set or, with a null amount, remove a system default spending limit (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.SetLimitRequest true "Limit"
+// @Success      200 {object} models.Response{data=models.SpendingLimit} "Limit set"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Limit not found"
+// @Router       /admin/limits [put]
+func (h *AdminHandler) SetDefaultLimit(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	h.setLimit(c, actorID, nil)
+}
+
+// ListUserLimits godoc
+// @Summary      User spending limits
+// @Description  List spending limits set for the user that override the system defaults (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} models.Response{data=[]models.SpendingLimit} "User limits"
+// @Failure      400 {object} models.Response "Invalid user id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User not found"
+// @Router       /admin/users/{id}/limits [get]
+func (h *AdminHandler) ListUserLimits(c *gin.Context) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return
+	}
+
+	limits, err := h.service.ListLimits(c, &userID)
+	if err != nil {
+		adminError(c, "List user limits failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: limits})
+}
+
+// SetUserLimit godoc
+// @Summary      Set user spending limit
+// @Description  Override a system default spending limit for the user or, with a null amount, remove the override (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        request body models.SetLimitRequest true "Limit"
+// @Success      200 {object} models.Response{data=models.SpendingLimit} "Limit set"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "User or limit not found"
+// @Router       /admin/users/{id}/limits [put]
+func (h *AdminHandler) SetUserLimit(c *gin.Context) {
+	actorID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	h.setLimit(c, actorID, &userID)
+}
+
+func (h *AdminHandler) setLimit(c *gin.Context, actorID uuid.UUID, userID *uuid.UUID) {
+	var req models.SetLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	limit, err := h.service.SetLimit(c, actorID, userID, req)
+	if err != nil {
+		adminError(c, "Limit change failed", err)
+		return
+	}
+
+	logger.L.Infow("Spending limit changed", "actorID", actorID, "userID", userID,
+		"operation", req.Operation, "currency", req.Currency, "period", req.Period, "removed", limit == nil)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: limit})
+}
+
+// ListFeeRules godoc
+// @Summary      Fee rules
+// @Description  List withdrawal and exchange fee rules with their volume tiers (support and admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.FeeRule} "Fee rules"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Router       /admin/fees [get]
+func (h *AdminHandler) ListFeeRules(c *gin.Context) {
+	rules, err := h.service.ListFeeRules(c)
+	if err != nil {
+		adminError(c, "List fee rules failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: rules})
+}
+
+// SetFeeRule godoc
+// @Summary      Set fee rule
+// @Description  Create a fee rule or replace the rule with the same operation, currencies and min_volume tier (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.SetFeeRuleRequest true "Fee rule"
+// @Success      200 {object} models.Response{data=models.FeeRule} "Fee rule set"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Router       /admin/fees [put]
+func (h *AdminHandler) SetFeeRule(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var req models.SetFeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.service.SetFeeRule(c, actorID, req)
+	if err != nil {
+		adminError(c, "Fee rule change failed", err)
+		return
+	}
+
+	logger.L.Infow("Fee rule changed", "actorID", actorID, "ruleID", rule.ID,
+		"operation", rule.Operation, "from", rule.FromCurrency, "to", rule.ToCurrency)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: rule})
+}
+
+// DeleteFeeRule godoc
+// @Summary      Delete fee rule
+// @Description  Delete a fee rule; operations it priced become free unless another rule matches (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Fee rule ID"
+// @Param        request body models.AdminActionRequest true "Reason"
+// @Success      200 {object} models.Response "Fee rule deleted"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Failure      404 {object} models.Response "Fee rule not found"
+// @Router       /admin/fees/{id} [delete]
+func (h *AdminHandler) DeleteFeeRule(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidFeeID,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.DeleteFeeRule(c, actorID, ruleID, req.Reason); err != nil {
+		adminError(c, "Fee rule deletion failed", err)
+		return
+	}
+
+	logger.L.Infow("Fee rule deleted", "actorID", actorID, "ruleID", ruleID)
+	c.JSON(http.StatusOK, models.Response{Success: true})
+}
+
+// ListAudit godoc
+// @Summary      Audit trail
+// @Description  List administrative actions, newest first (admin only)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        user_id query string false "Only actions on this user"
+// @Param        limit query int false "Page size, at most 100"
+// @Success      200 {object} models.Response{data=[]models.AuditEntry} "Audit entries"
+// @Failure      400 {object} models.Response "Invalid request"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Insufficient permissions"
+// @Router       /admin/audit [get]
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	var target *uuid.UUID
+	if s := c.Query("user_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidUserID,
+			})
+			return
+		}
+		target = &id
+	}
+
+	limit := 0
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidRequest,
+				Details: "limit must be a number",
+			})
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.service.ListAudit(c, target, limit)
+	if err != nil {
+		adminError(c, "List audit failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: entries})
+}
+
+func targetUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidUserID,
+			Details: err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// adminTarget returns the acting staff member and the user the action is applied to.
+func adminTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, ok := adminActor(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, ok := targetUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID, userID, true
+}
+
+func adminActor(c *gin.Context) (uuid.UUID, bool) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, false
+	}
+
+	return actorID, true
+}
+
+// walletTarget resolves the wallet an admin action is applied to and writes
+// the error response when it cannot.
+type walletTarget func(c *gin.Context) (uuid.UUID, bool)
+
+func targetWalletID(c *gin.Context) (uuid.UUID, bool) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidWalletID,
+			Details: err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	return walletID, true
+}
+
+// mainWalletID is the walletTarget of the user-keyed routes: the main wallet of the user.
+func (h *AdminHandler) mainWalletID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := targetUserID(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	wallet, err := h.service.GetUserWallet(c, userID)
+	if err != nil {
+		adminError(c, "Get user wallet failed", err)
+		return uuid.Nil, false
+	}
+
+	return wallet.ID, true
+}
+
+func adminError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgUserNotFound,
+		})
+	case errors.Is(err, models.ErrWalletNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgWalletNotFound,
+		})
+	case errors.Is(err, models.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgReasonRequired,
+		})
+	case errors.Is(err, models.ErrInvalidStatus):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidStatus,
+		})
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidStatusTransition,
+		})
+	case errors.Is(err, models.ErrWalletNotEmpty):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgWalletNotEmpty,
+		})
+	case errors.Is(err, models.ErrWalletClosed):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgWalletClosed,
+		})
+	case errors.Is(err, models.ErrLimitNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgLimitNotFound,
+		})
+	case errors.Is(err, models.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidLimit,
+		})
+	case errors.Is(err, models.ErrFeeRuleNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgFeeRuleNotFound,
+		})
+	case errors.Is(err, models.ErrInvalidFeeRule):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidFeeRule,
+		})
+	case errors.Is(err, models.ErrInvalidRole), errors.Is(err, services.ErrSelfRoleChange):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRole,
+			Details: err.Error(),
+		})
+	case errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, models.ErrUnsupportedCurrency):
+		logger.L.Warnw(msg, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgAdminActionFailed,
+			Details: err.Error(),
+		})
+	default:
+		logger.L.Errorw(msg, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+	}
+}
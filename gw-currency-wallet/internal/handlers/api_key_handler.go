@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// CreateAPIKey godoc
+// @Summary      Create API key
+// @Description  Create a named API key for a machine client. Send it in the X-API-Key header instead of a bearer token. Scopes: balance:read, wallet:deposit, wallet:withdraw, wallet:exchange. The key is shown only in this response. Requires a TOTP code
+// @Tags         api-keys
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        X-2FA-Code header string true "TOTP code"
+// @Param        request body models.CreateAPIKeyRequest true "Key settings"
+// @Success      201 {object} models.Response{data=models.CreatedAPIKey} "API key created"
+// @Failure      400 {object} models.Response "Invalid name, scope, allowed IP or expiry"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "A valid TOTP code is required or 2FA is not set up"
+// @Failure      429 {object} models.Response "Too many invalid TOTP codes"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	key, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		var statusCode int
+		var errorMsg string
+
+		switch {
+		case errors.Is(err, models.ErrInvalidAPIKeyScope):
+			statusCode = http.StatusBadRequest
+			errorMsg = messages.MsgInvalidAPIKeyScope
+		case errors.Is(err, models.ErrInvalidAllowedIP):
+			statusCode = http.StatusBadRequest
+			errorMsg = messages.MsgInvalidAllowedIP
+		case errors.Is(err, models.ErrInvalidAPIKeyTTL):
+			statusCode = http.StatusBadRequest
+			errorMsg = messages.MsgInvalidAPIKeyExpiry
+		default:
+			logger.L.Errorw("Failed to create API key", "userID", userID, "error", err.Error())
+			statusCode = http.StatusInternalServerError
+			errorMsg = messages.MsgInternalError
+		}
+
+		c.JSON(statusCode, models.Response{
+			Success: false,
+			Error:   errorMsg,
+		})
+		return
+	}
+
+	logger.L.Infow("API key created", "userID", userID, "keyID", key.ID, "scopes", key.Scopes)
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Data:    key,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  List the current user's API keys, including revoked and expired ones. Keys themselves are never returned
+// @Tags         api-keys
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.APIKey} "API keys"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	keys, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		logger.L.Errorw("Failed to list API keys", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    keys,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  Revoke an API key of the current user. Requests with it are rejected right away
+// @Tags         api-keys
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "API key ID"
+// @Success      200 {object} models.Response "API key revoked"
+// @Failure      400 {object} models.Response "Invalid key id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "API key not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	err = h.service.Revoke(c.Request.Context(), userID, keyID)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgAPIKeyNotFound,
+		})
+		return
+	default:
+		logger.L.Errorw("Failed to revoke API key", "userID", userID, "keyID", keyID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+		return
+	}
+
+	logger.L.Infow("API key revoked", "userID", userID, "keyID", keyID)
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "API key revoked"},
+	})
+}
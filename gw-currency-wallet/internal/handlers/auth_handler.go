@@ -1,32 +1,37 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/pkg/logger"
 	"gw-currency-wallet/internal/pkg/messages"
 	"gw-currency-wallet/internal/services"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-	authService   *services.AuthService
-	jwtManager    *services.JWTManager
-	walletService *services.WalletService
+	authService    *services.AuthService
+	jwtManager     *services.JWTManager
+	accountService *services.AccountService
 }
 
-func NewAuthHandler(authService *services.AuthService, walletService *services.WalletService, jwtManager *services.JWTManager) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService, jwtManager *services.JWTManager) *AuthHandler {
 	return &AuthHandler{
-		authService:   authService,
-		walletService: walletService,
-		jwtManager:    jwtManager,
+		authService:    authService,
+		accountService: accountService,
+		jwtManager:     jwtManager,
 	}
 }
 
 // Register godoc
 // @Summary      Register new user
-// @Description  Create a new user account with wallet
+// @Description  Create a new user account with wallet and send an email verification link. Withdrawals require a verified email
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -77,16 +82,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	_, err = h.walletService.CreateWallet(c.Request.Context(), userID)
-	if err != nil {
-		logger.L.Errorw("Failed to create wallet after registration",
+	// Письмо можно запросить повторно, поэтому ошибка отправки не отменяет регистрацию.
+	if err := h.accountService.SendVerificationEmail(c.Request.Context(), userID); err != nil {
+		logger.L.Errorw("Failed to send verification email after registration",
 			"userID", userID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Success: false,
-			Error:   messages.MsgInternalError,
-			Details: "User registered but wallet creation failed",
-		})
-		return
 	}
 
 	logger.L.Infow("User registered successfully", "userID", userID, "username", req.Username)
@@ -101,14 +100,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user and return JWT token
+// @Description  Authenticate user and return a short-lived access token with a refresh token. If two-factor authentication is enabled, a login challenge is returned instead; complete it at /login/2fa
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.LoginRequest true "Login credentials"
-// @Success      200 {object} models.Response "Login successful"
+// @Success      200 {object} models.Response{data=models.TokenPair} "Login successful"
+// @Success      202 {object} models.Response{data=models.LoginChallenge} "Password accepted, two-factor code required"
 // @Failure      400 {object} models.Response "Invalid request data"
 // @Failure      401 {object} models.Response "Invalid credentials"
+// @Failure      429 {object} models.Response "Too many failed attempts for this username or IP; see the Retry-After header"
 // @Failure      500 {object} models.Response "Internal server error"
 // @Router       /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -124,15 +125,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(c.Request.Context(), req)
+	// ClientIP is the peer address unless it is a trusted proxy, so the IP
+	// lockout cannot be reset by sending a new X-Forwarded-For.
+	pair, challenge, err := h.authService.Login(c.Request.Context(), req, c.ClientIP())
 	if err != nil {
-		logger.L.Warnw("Login failed", "username", req.Username, "error", err.Error())
+		logger.L.Warnw("Login failed", "username", req.Username, "ip", c.ClientIP(), "error", err.Error())
 
 		var statusCode int
 		var errorMsg string
+		var locked *models.LoginLockedError
 
-		switch err {
-		case services.ErrInvalidCredentials:
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			statusCode = http.StatusTooManyRequests
+			errorMsg = messages.MsgLoginLocked
+		case errors.Is(err, services.ErrInvalidCredentials):
 			statusCode = http.StatusUnauthorized
 			errorMsg = messages.MsgInvalidCredentials
 		default:
@@ -147,12 +155,195 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if challenge != nil {
+		logger.L.Infow("Two-factor code requested", "username", req.Username)
+		c.JSON(http.StatusAccepted, models.Response{
+			Success: true,
+			Data:    challenge,
+		})
+		return
+	}
+
 	logger.L.Infow("User logged successfully", "username", req.Username)
 
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    pair,
+	})
+}
+
+// LoginTwoFactor godoc
+// @Summary      Complete two-factor login
+// @Description  Exchange the login challenge and a TOTP or recovery code for an access token with a refresh token. A challenge survives mistyped codes until it expires, but repeated invalid codes lock two-factor verification for a while
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.LoginTwoFactorRequest true "Challenge token and code"
+// @Success      200 {object} models.Response{data=models.TokenPair} "Login successful"
+// @Failure      400 {object} models.Response "Invalid request data"
+// @Failure      401 {object} models.Response "Invalid code or invalid, expired or used challenge"
+// @Failure      429 {object} models.Response "Too many invalid codes"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.LoginTwoFactorRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	pair, err := h.authService.LoginTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		logger.L.Warnw("Two-factor login failed", "ip", c.ClientIP(), "error", err.Error())
+
+		var statusCode int
+		var errorMsg string
+
+		switch {
+		case errors.Is(err, models.ErrInvalidChallenge), errors.Is(err, models.ErrTwoFactorNotEnabled):
+			// 2FA могли отключить, пока вызов ждал кода.
+			statusCode = http.StatusUnauthorized
+			errorMsg = messages.MsgInvalidLoginChallenge
+		case errors.Is(err, models.ErrInvalidTwoFactorCode), errors.Is(err, models.ErrTwoFactorRequired):
+			statusCode = http.StatusUnauthorized
+			errorMsg = messages.MsgInvalidTwoFactorCode
+		case errors.Is(err, models.ErrTwoFactorLocked):
+			statusCode = http.StatusTooManyRequests
+			errorMsg = messages.MsgTwoFactorLocked
+		default:
+			statusCode = http.StatusInternalServerError
+			errorMsg = messages.MsgInternalError
+		}
+
+		c.JSON(statusCode, models.Response{
+			Success: false,
+			Error:   errorMsg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    pair,
+	})
+}
+
+// Refresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access and refresh token pair. The presented refresh token becomes invalid; presenting it again revokes the whole session
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.RefreshRequest true "Refresh token"
+// @Success      200 {object} models.Response{data=models.TokenPair} "Tokens refreshed"
+// @Failure      400 {object} models.Response "Invalid request data"
+// @Failure      401 {object} models.Response "Invalid, expired or reused refresh token"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /token/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.L.Warnw("Invalid refresh request", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	pair, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		var statusCode int
+		var errorMsg string
+
+		switch {
+		case errors.Is(err, models.ErrInvalidRefreshToken):
+			logger.L.Warnw("Refresh failed", "error", err.Error())
+			statusCode = http.StatusUnauthorized
+			errorMsg = messages.MsgInvalidRefreshToken
+		case errors.Is(err, models.ErrRefreshTokenReused):
+			logger.L.Warnw("Refresh token reuse detected", "ip", c.ClientIP())
+			statusCode = http.StatusUnauthorized
+			errorMsg = messages.MsgRefreshTokenReused
+		default:
+			logger.L.Errorw("Refresh failed", "error", err.Error())
+			statusCode = http.StatusInternalServerError
+			errorMsg = messages.MsgInternalError
+		}
+
+		c.JSON(statusCode, models.Response{
+			Success: false,
+			Error:   errorMsg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    pair,
+	})
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke the current access token and the refresh tokens of its session
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response "Logged out"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	h.logout(c, h.authService.Logout)
+}
+
+// LogoutAll godoc
+// @Summary      Log out of all sessions
+// @Description  Revoke the current access token and every refresh token of the user
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response "Logged out of all sessions"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /logout/all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	h.logout(c, h.authService.LogoutAll)
+}
+
+func (h *AuthHandler) logout(c *gin.Context, revoke func(ctx context.Context, userID uuid.UUID, claims *models.AccessClaims) error) {
+	userID, ok := getUserID(c)
+	claims, hasClaims := c.Get("access_claims")
+	if !ok || !hasClaims {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	if err := revoke(c.Request.Context(), userID, claims.(*models.AccessClaims)); err != nil {
+		logger.L.Errorw("Logout failed", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgLogoutFailed,
+		})
+		return
+	}
+
+	logger.L.Infow("User logged out", "userID", userID)
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
 		Data: gin.H{
-			"token": token,
+			"message": "Logged out successfully",
 		},
 	})
 }
@@ -2,23 +2,37 @@ package handlers_test
 
 import (
 	"context"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/handlers"
-	"gw-currency-wallet/internal/kafka"
+	"gw-currency-wallet/internal/jwks"
+	"gw-currency-wallet/internal/mailer"
+	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/secretbox"
 	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/storages"
 	"gw-currency-wallet/internal/storages/postgres"
+	"gw-currency-wallet/internal/totp"
 	"gw-currency-wallet/internal/transport/http/middleware"
 	"gw-currency-wallet/internal/utils"
 
 	"gw-currency-wallet/gw-exchanger/proto/exchange"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/tidwall/gjson"
 )
 
@@ -34,6 +48,42 @@ func (f *mockExchangeClient) GetAllRates(ctx context.Context) ([]*exchange.GetRa
 	}, nil
 }
 
+// memoryMailer keeps sent emails so tests can follow the links in them.
+type memoryMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *memoryMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+var tokenInLink = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// lastToken returns the token from the latest email sent to "to".
+func (m *memoryMailer) lastToken(to string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			if match := tokenInLink.FindStringSubmatch(m.messages[i].Body); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
+
+var testMailer = &memoryMailer{}
+
+var testStepUp = models.StepUpThresholds{
+	Default:    models.NewDecimal(1000, 0),
+	Currencies: map[models.Currency]models.Decimal{models.RUB: models.NewDecimal(100000, 0)},
+}
+
 func performRequest(r http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(method, path, strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -58,31 +108,185 @@ func setupTestServer(t *testing.T) (*gin.Engine, *services.JWTManager) {
 
 	exchangeClient := &mockExchangeClient{}
 
-	producer := kafka.NewProducer(cfg.KafkaBroker, cfg.KafkaTopic, cfg.KafkaBatchSize, cfg.KafkaBatchTimeout)
-
 	walletRepo := postgres.NewWalletRepo(db)
 	userRepo := postgres.NewUserRepo(db)
+	transactionRepo := postgres.NewTransactionRepo(db)
+	idempotencyRepo := postgres.NewIdempotencyRepo(db)
+	currencyRepo := postgres.NewCurrencyRepo(db)
+	quoteRepo := postgres.NewQuoteRepo(db)
+	tokenRepo := postgres.NewTokenRepo(db)
+	adminRepo := postgres.NewAdminRepo(db)
+	loginRepo := postgres.NewLoginRepo(db)
+	emailTokenRepo := postgres.NewEmailTokenRepo(db)
+	twoFactorRepo := postgres.NewTwoFactorRepo(db)
+	apiKeyRepo := postgres.NewAPIKeyRepo(db)
+	limitRepo := postgres.NewLimitRepo(db)
+	feeRepo := postgres.NewFeeRepo(db)
+	scheduleRepo := postgres.NewScheduleRepo(db)
+	orderRepo := postgres.NewOrderRepo(db)
+	unitOfWork := postgres.NewUnitOfWork(db)
+
+	keys, err := jwks.NewKeySet(t.TempDir(), cfg.JWTAlgorithm, cfg.JWTKeyRotateAfter, 2*cfg.JWTKeyRotateAfter, cfg.JWTKeyCheckInterval)
+	if err != nil {
+		t.Fatalf("failed to create JWT keys: %v", err)
+	}
+
+	jwtVerifier := services.NewJWTVerifier(keys)
+	jwtManager := services.NewJWTManager(keys, jwtVerifier, cfg.AccessTokenTTL)
+
+	lockout := models.LockoutRule{
+		MaxFailures: cfg.LoginMaxUserFailures,
+		Window:      cfg.LoginFailureWindow,
+		BaseLockout: cfg.LoginLockoutBase,
+		MaxLockout:  cfg.LoginLockoutMax,
+		ResetAfter:  cfg.LoginLockoutResetAfter,
+	}
+	ipLockout := lockout
+	ipLockout.MaxFailures = cfg.LoginMaxIPFailures
+
+	totpBox, err := secretbox.New(make([]byte, secretbox.KeySize))
+	if err != nil {
+		t.Fatalf("failed to create secret box: %v", err)
+	}
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, totpBox, cfg.TOTPIssuer, testStepUp,
+		cfg.TwoFactorChallengeTTL, cfg.TwoFactorMaxAttempts, cfg.TwoFactorLockout)
+
+	feeService := services.NewFeeService(feeRepo, uuid.MustParse(cfg.HouseWalletID))
+	walletService := services.NewWalletService(walletRepo, currencyRepo, quoteRepo, exchangeClient, cache, cfg.ExchangeQuoteTTL, cfg.WalletMaxInflight, cfg.MaxWalletsPerUser, feeService)
+	authService := services.NewAuthService(userRepo, walletService, tokenRepo, loginRepo, jwtManager, cfg.RefreshTokenTTL,
+		models.LoginPolicy{Username: lockout, IP: ipLockout}, twoFactorService, unitOfWork)
 
-	jwtManager := services.NewJWTManager(cfg.JWTSecret)
+	accountService := services.NewAccountService(userRepo, emailTokenRepo, testMailer, cfg.PublicBaseURL,
+		cfg.EmailVerificationTTL, cfg.PasswordResetTTL)
 
-	authService := services.NewAuthService(userRepo, walletRepo, jwtManager)
-	walletService := services.NewWalletService(walletRepo, exchangeClient, cache, producer, cfg.WalletMaxInflight)
+	authHandler := handlers.NewAuthHandler(authService, accountService, jwtManager)
+	transactionService := services.NewTransactionService(transactionRepo)
+	transferService := services.NewTransferService(walletRepo, userRepo)
+	holdService := services.NewHoldService(walletRepo, feeService, cfg.HoldDefaultTTL, cfg.HoldMaxTTL)
+	adminService := services.NewAdminService(adminRepo, userRepo, walletRepo, limitRepo, feeRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	limitService := services.NewLimitService(limitRepo)
+	orderService := services.NewOrderService(orderRepo, walletRepo, feeService, unitOfWork, cfg.OrderDefaultTTL, cfg.OrderMaxTTL)
+	scheduleService := services.NewScheduleService(scheduleRepo, walletService, unitOfWork, cfg.MaxSchedulesPerUser, cfg.ScheduleMaxFailures)
 
-	authHandler := handlers.NewAuthHandler(authService, walletService, jwtManager)
 	walletHandler := handlers.NewWalletHandler(walletService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	holdHandler := handlers.NewHoldHandler(holdService)
+	jwksHandler := handlers.NewJWKSHandler(keys)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	limitHandler := handlers.NewLimitHandler(limitService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	orderHandler := handlers.NewOrderHandler(orderService)
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("failed to set trusted proxies: %v", err)
+	}
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
 	r.POST("/api/v1/register", authHandler.Register)
 	r.POST("/api/v1/login", authHandler.Login)
+	r.POST("/api/v1/login/2fa", authHandler.LoginTwoFactor)
+	r.POST("/api/v1/token/refresh", authHandler.Refresh)
+	r.POST("/api/v1/email/verify", accountHandler.VerifyEmail)
+	r.POST("/api/v1/password/forgot", accountHandler.RequestPasswordReset)
+	r.POST("/api/v1/password/reset", accountHandler.ResetPassword)
+	r.GET("/api/v1/currencies", walletHandler.GetCurrencies)
+
+	jwt := middleware.JWT(jwtVerifier, tokenRepo)
+	idempotent := middleware.Idempotency(idempotencyRepo, cfg.IdempotencyLockTimeout)
+	stepUp := middleware.StepUp(twoFactorService)
+
+	// Routes that machine clients may call with an API key of the given scope.
+	machine := r.Group("/")
+	machine.Use(middleware.APIKeyOrJWT(apiKeyService, jwt))
+	{
+		machine.GET("/api/v1/balance", middleware.RequireScope(models.ScopeBalanceRead), walletHandler.GetWallet)
+		machine.POST("/api/v1/wallet/deposit", middleware.RequireScope(models.ScopeWalletDeposit), idempotent, walletHandler.Deposit)
+		machine.POST("/api/v1/wallet/withdraw", middleware.RequireScope(models.ScopeWalletWithdraw),
+			middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, walletHandler.Withdraw)
+		machine.POST("/api/v1/exchange", middleware.RequireScope(models.ScopeWalletExchange), idempotent, walletHandler.Exchange)
+		machine.POST("/api/v1/exchange/quote", middleware.RequireScope(models.ScopeWalletExchange), walletHandler.QuoteExchange)
+		machine.POST("/api/v1/wallets/:id/deposit", middleware.RequireScope(models.ScopeWalletDeposit), idempotent, walletHandler.DepositToWallet)
+		machine.POST("/api/v1/wallets/:id/withdraw", middleware.RequireScope(models.ScopeWalletWithdraw),
+			middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, walletHandler.WithdrawFromWallet)
+		machine.POST("/api/v1/wallets/:id/exchange", middleware.RequireScope(models.ScopeWalletExchange), idempotent, walletHandler.ExchangeInWallet)
+		machine.POST("/api/v1/wallets/:id/exchange/quote", middleware.RequireScope(models.ScopeWalletExchange), walletHandler.QuoteExchangeInWallet)
+	}
 
 	authUser := r.Group("/")
-	authUser.Use(middleware.JWT(cfg.JWTSecret))
+	authUser.Use(jwt)
 	{
-		authUser.GET("/api/v1/balance", walletHandler.GetWallet)
-		authUser.POST("/api/v1/wallet/deposit", walletHandler.Deposit)
-		authUser.POST("/api/v1/wallet/withdraw", walletHandler.Withdraw)
-		authUser.POST("/api/v1/exchange", walletHandler.Exchange)
+		authUser.POST("/api/v1/logout", authHandler.Logout)
+		authUser.POST("/api/v1/logout/all", authHandler.LogoutAll)
+		authUser.POST("/api/v1/email/verification", accountHandler.ResendVerification)
+		authUser.POST("/api/v1/2fa/totp/enroll", twoFactorHandler.EnrollTOTP)
+		authUser.POST("/api/v1/2fa/totp/confirm", twoFactorHandler.ConfirmTOTP)
+		authUser.POST("/api/v1/2fa/totp/disable", twoFactorHandler.DisableTOTP)
+		authUser.POST("/api/v1/api-keys", middleware.RequireTwoFactor(twoFactorService), apiKeyHandler.CreateAPIKey)
+		authUser.GET("/api/v1/api-keys", apiKeyHandler.ListAPIKeys)
+		authUser.DELETE("/api/v1/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+		authUser.GET("/api/v1/wallets", walletHandler.ListWallets)
+		authUser.POST("/api/v1/wallets", walletHandler.CreateWallet)
+		authUser.POST("/api/v1/wallets/transfers", idempotent, walletHandler.TransferBetweenWallets)
+		authUser.GET("/api/v1/wallets/:id", walletHandler.GetWalletByID)
+		authUser.PATCH("/api/v1/wallets/:id", walletHandler.RenameWallet)
+		authUser.POST("/api/v1/wallets/:id/close", walletHandler.CloseWallet)
+
+		authUser.POST("/api/v1/transfers", middleware.RequireVerifiedEmail(userRepo), idempotent, stepUp, transferHandler.Transfer)
 		authUser.GET("/api/v1/exchange/rates", walletHandler.GetAllRates)
+		authUser.GET("/api/v1/transactions", transactionHandler.ListTransactions)
+		authUser.GET("/api/v1/limits", limitHandler.GetLimits)
+
+		authUser.POST("/api/v1/holds", middleware.RequireVerifiedEmail(userRepo), idempotent, holdHandler.CreateHold)
+		authUser.GET("/api/v1/holds/:id", holdHandler.GetHold)
+		authUser.POST("/api/v1/holds/:id/capture", middleware.RequireVerifiedEmail(userRepo),
+			idempotent, middleware.HoldCaptureStepUp(twoFactorService, holdService), holdHandler.CaptureHold)
+		authUser.POST("/api/v1/holds/:id/void", idempotent, holdHandler.VoidHold)
+
+		authUser.GET("/api/v1/schedules", scheduleHandler.ListSchedules)
+		authUser.POST("/api/v1/schedules", scheduleHandler.CreateSchedule)
+		authUser.GET("/api/v1/schedules/:id", scheduleHandler.GetSchedule)
+		authUser.PATCH("/api/v1/schedules/:id", scheduleHandler.UpdateSchedule)
+		authUser.DELETE("/api/v1/schedules/:id", scheduleHandler.DeleteSchedule)
+		authUser.GET("/api/v1/schedules/:id/runs", scheduleHandler.ListScheduleRuns)
+
+		authUser.GET("/api/v1/orders", orderHandler.ListOrders)
+		authUser.POST("/api/v1/orders", middleware.RequireVerifiedEmail(userRepo), idempotent, orderHandler.PlaceOrder)
+		authUser.GET("/api/v1/orders/:id", orderHandler.GetOrder)
+		authUser.POST("/api/v1/orders/:id/cancel", idempotent, orderHandler.CancelOrder)
+
+		requireAdmin := middleware.RequireRole(models.RoleAdmin)
+		admin := authUser.Group("/api/v1/admin", middleware.RequireRole(models.RoleSupport))
+		admin.GET("/users", adminHandler.FindUser)
+		admin.GET("/users/:id", adminHandler.GetUser)
+		admin.GET("/users/:id/wallet", adminHandler.GetUserWallet)
+		admin.POST("/users/:id/wallet/freeze", adminHandler.FreezeWallet)
+		admin.POST("/users/:id/wallet/unfreeze", adminHandler.UnfreezeWallet)
+		admin.PUT("/users/:id/wallet/status", requireAdmin, adminHandler.SetWalletStatus)
+		admin.POST("/users/:id/wallet/adjustments", requireAdmin, idempotent, adminHandler.AdjustBalance)
+		admin.GET("/users/:id/wallets", adminHandler.ListUserWallets)
+		admin.GET("/wallets/:walletId", adminHandler.GetWallet)
+		admin.POST("/wallets/:walletId/freeze", adminHandler.FreezeWalletByID)
+		admin.POST("/wallets/:walletId/unfreeze", adminHandler.UnfreezeWalletByID)
+		admin.PUT("/wallets/:walletId/status", requireAdmin, adminHandler.SetWalletStatusByID)
+		admin.POST("/wallets/:walletId/adjustments", requireAdmin, idempotent, adminHandler.AdjustBalanceByID)
+		admin.PUT("/users/:id/role", requireAdmin, adminHandler.SetUserRole)
+		admin.PUT("/users/:id/status", requireAdmin, adminHandler.SetAccountStatus)
+		admin.GET("/users/:id/status-history", adminHandler.GetStatusHistory)
+		admin.POST("/users/:id/unlock", requireAdmin, adminHandler.UnlockLogin)
+		admin.GET("/users/:id/limits", adminHandler.ListUserLimits)
+		admin.PUT("/users/:id/limits", requireAdmin, adminHandler.SetUserLimit)
+		admin.GET("/limits", adminHandler.ListDefaultLimits)
+		admin.PUT("/limits", requireAdmin, adminHandler.SetDefaultLimit)
+		admin.GET("/fees", adminHandler.ListFeeRules)
+		admin.PUT("/fees", requireAdmin, adminHandler.SetFeeRule)
+		admin.DELETE("/fees/:id", requireAdmin, adminHandler.DeleteFeeRule)
+		admin.GET("/audit", requireAdmin, adminHandler.ListAudit)
 	}
 
 	return r, jwtManager
@@ -134,4 +338,602 @@ func TestWalletHandlers(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("exchange failed: %d %s", w.Code, w.Body.String())
 	}
+
+	for i := 0; i < 2; i++ {
+		w = performRequest(r, "GET", "/api/v1/currencies", "", "")
+		if w.Code != http.StatusOK || !gjson.Get(w.Body.String(), `data.#(code=="USD")`).Exists() {
+			t.Errorf("currencies failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	// История операций проверяется на новых пользователях, чтобы на неё
+	// не влияли прошлые прогоны.
+	login := func() string {
+		username := "history_" + uuid.NewString()[:8]
+		w := performRequest(r, "POST", "/api/v1/register",
+			`{"username":"`+username+`","password":"12345678","email":"`+username+`@mail.ru"}`, "")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+		}
+		w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+		}
+		return gjson.Get(w.Body.String(), "data.token").String()
+	}
+	history := login()
+	for _, op := range []struct{ path, body string }{
+		{"/api/v1/wallet/deposit", `{"currency":"USD","amount":100}`},
+		{"/api/v1/wallet/deposit", `{"currency":"EUR","amount":20}`},
+		{"/api/v1/wallet/withdraw", `{"currency":"USD","amount":30}`},
+		{"/api/v1/wallet/deposit", `{"currency":"USD","amount":5}`},
+	} {
+		if w := performRequest(r, "POST", op.path, op.body, history); w.Code != http.StatusOK {
+			t.Fatalf("%s failed: %d %s", op.path, w.Code, w.Body.String())
+		}
+	}
+	transactions := func(query, token string) gjson.Result {
+		t.Helper()
+		w := performRequest(r, "GET", "/api/v1/transactions"+query, "", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("transactions%s failed: %d %s", query, w.Code, w.Body.String())
+		}
+		return gjson.Get(w.Body.String(), "data")
+	}
+
+	// Страницы идут от новых операций к старым и не пересекаются.
+	first := transactions("?limit=3", history)
+	items := first.Get("items").Array()
+	cursor := first.Get("next_cursor").String()
+	if len(items) != 3 || cursor == "" {
+		t.Fatalf("first page: %d items, cursor %q", len(items), cursor)
+	}
+	if items[0].Get("type").String() != "deposit" || items[0].Get("amount.amount").String() != "5.00" ||
+		items[1].Get("type").String() != "withdraw" {
+		t.Errorf("first page is not newest first: %s", first.Raw)
+	}
+	second := transactions("?limit=3&cursor="+cursor, history)
+	rest := second.Get("items").Array()
+	if len(rest) != 1 || second.Get("next_cursor").Exists() {
+		t.Fatalf("second page: %s", second.Raw)
+	}
+	if rest[0].Get("amount.amount").String() != "100.00" || rest[0].Get("id").String() == items[2].Get("id").String() {
+		t.Errorf("second page repeats or skips operations: %s", second.Raw)
+	}
+
+	for query, want := range map[string]int{
+		"?type=withdraw":             1,
+		"?type=deposit":              3,
+		"?currency=EUR":              1,
+		"?currency=USD&type=deposit": 2,
+		"?from=" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339): 4,
+		"?from=" + time.Now().Add(48*time.Hour).Format(time.DateOnly):    0,
+		"?to=2000-01-01": 0,
+	} {
+		if got := len(transactions(query, history).Get("items").Array()); got != want {
+			t.Errorf("transactions%s: %d items, expected %d", query, got, want)
+		}
+	}
+
+	for _, query := range []string{"?type=bogus", "?limit=abc", "?cursor=bogus", "?from=yesterday", "?from=2030-01-02&to=2030-01-01"} {
+		if w := performRequest(r, "GET", "/api/v1/transactions"+query, "", history); w.Code != http.StatusBadRequest {
+			t.Errorf("transactions%s: expected 400, got %d", query, w.Code)
+		}
+	}
+
+	// Пользователь видит только свои операции.
+	if items := transactions("", login()).Get("items"); !items.IsArray() || len(items.Array()) != 0 {
+		t.Errorf("another user sees transactions: %s", items.Raw)
+	}
+	if w := performRequest(r, "GET", "/api/v1/transactions", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("transactions without token: expected 401, got %d", w.Code)
+	}
+}
+
+func TestAuthHandlers_RefreshAndLogout(t *testing.T) {
+	r, _ := setupTestServer(t)
+
+	username := "refresh_" + uuid.NewString()[:8]
+	w := performRequest(r, "POST", "/api/v1/register",
+		`{"username":"`+username+`","password":"12345678","email":"`+username+`@mail.ru"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login",
+		`{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+	}
+	token := gjson.Get(w.Body.String(), "data.token").String()
+	refresh := gjson.Get(w.Body.String(), "data.refresh_token").String()
+
+	w = performRequest(r, "POST", "/api/v1/token/refresh", `{"refresh_token":"`+refresh+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh failed: %d %s", w.Code, w.Body.String())
+	}
+	rotatedToken := gjson.Get(w.Body.String(), "data.token").String()
+	rotated := gjson.Get(w.Body.String(), "data.refresh_token").String()
+
+	if w = performRequest(r, "GET", "/api/v1/balance", "", rotatedToken); w.Code != http.StatusOK {
+		t.Fatalf("balance with refreshed token failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Повторное предъявление заменённого токена отзывает всё семейство.
+	if w = performRequest(r, "POST", "/api/v1/token/refresh", `{"refresh_token":"`+refresh+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/token/refresh", `{"refresh_token":"`+rotated+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token of revoked family: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "GET", "/api/v1/balance", "", rotatedToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("access token of revoked family: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "GET", "/api/v1/balance", "", token); w.Code != http.StatusUnauthorized {
+		t.Errorf("first access token of revoked family: expected 401, got %d", w.Code)
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login",
+		`{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("second login failed: %d %s", w.Code, w.Body.String())
+	}
+	token = gjson.Get(w.Body.String(), "data.token").String()
+	refresh = gjson.Get(w.Body.String(), "data.refresh_token").String()
+
+	if w = performRequest(r, "POST", "/api/v1/logout", "", token); w.Code != http.StatusOK {
+		t.Fatalf("logout failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "GET", "/api/v1/balance", "", token); w.Code != http.StatusUnauthorized {
+		t.Errorf("access token after logout: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/token/refresh", `{"refresh_token":"`+refresh+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token after logout: expected 401, got %d", w.Code)
+	}
+}
+
+func TestAuthHandlers_RegisterConcurrent(t *testing.T) {
+	r, _ := setupTestServer(t)
+
+	// Одно имя регистрируют одновременно: проходит ровно одна попытка,
+	// остальные получают 409, а не 500.
+	username := "race_" + uuid.NewString()[:8]
+	codes := make([]int, 10)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := performRequest(r, "POST", "/api/v1/register",
+				fmt.Sprintf(`{"username":"%s","password":"12345678","email":"%s_%d@mail.ru"}`, username, username, i), "")
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	created, winner := 0, 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created, winner = created+1, i
+		case http.StatusConflict:
+		default:
+			t.Errorf("unexpected register status %d", code)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("registered %d times, expected once", created)
+	}
+
+	w := performRequest(r, "POST", "/api/v1/register",
+		fmt.Sprintf(`{"username":"%s_2","password":"12345678","email":"%s_%d@mail.ru"}`, username, username, winner), "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate email: expected 409, got %d", w.Code)
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login",
+		`{"username":"`+username+`","password":"12345678"}`, "")
+	token := gjson.Get(w.Body.String(), "data.token").String()
+	if w = performRequest(r, "GET", "/api/v1/balance", "", token); w.Code != http.StatusOK {
+		t.Errorf("registered user has no wallet: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// failingWalletRepo fails every wallet creation.
+type failingWalletRepo struct {
+	storages.WalletStorage
+}
+
+func (r failingWalletRepo) CreateWallet(ctx context.Context, wallet *models.Wallet) error {
+	return errors.New("wallet storage unavailable")
+}
+
+func TestAuthHandlers_RegisterRollsBackUser(t *testing.T) {
+	r, _ := setupTestServer(t)
+	cfg := config.Load()
+
+	db, err := postgres.NewPostgres(cfg.PostgresURL, cfg.DbMaxConns, cfg.DbMinConns, cfg.DbMaxLifetime)
+	if err != nil {
+		t.Fatalf("failed to connect postgres: %v", err)
+	}
+	defer db.Close()
+
+	// Registration whose wallet cannot be created must not leave the user behind.
+	userRepo := postgres.NewUserRepo(db)
+	walletService := services.NewWalletService(failingWalletRepo{postgres.NewWalletRepo(db)}, postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &mockExchangeClient{}, utils.NewRateCache(cfg.CacheRatesLifetime), cfg.ExchangeQuoteTTL,
+		cfg.WalletMaxInflight, cfg.MaxWalletsPerUser, nil)
+	authService := services.NewAuthService(userRepo, walletService, postgres.NewTokenRepo(db), postgres.NewLoginRepo(db),
+		nil, cfg.RefreshTokenTTL, models.LoginPolicy{}, nil, postgres.NewUnitOfWork(db))
+
+	failing := gin.New()
+	failing.POST("/api/v1/register", handlers.NewAuthHandler(authService, nil, nil).Register)
+
+	username := "rollback_" + uuid.NewString()[:8]
+	body := `{"username":"` + username + `","password":"12345678","email":"` + username + `@mail.ru"}`
+	if w := performRequest(failing, "POST", "/api/v1/register", body, ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("register without wallet: expected 500, got %d %s", w.Code, w.Body.String())
+	}
+	if _, err := userRepo.GetUserByUsername(context.Background(), username); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("user without wallet was not rolled back: %v", err)
+	}
+
+	// The username and email stay free, and a working registration gets a wallet.
+	if w := performRequest(r, "POST", "/api/v1/register", body, ""); w.Code != http.StatusCreated {
+		t.Fatalf("register after rollback: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	w := performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+	token := gjson.Get(w.Body.String(), "data.token").String()
+	if w = performRequest(r, "GET", "/api/v1/balance", "", token); w.Code != http.StatusOK {
+		t.Errorf("registered user has no wallet: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandlers_LoginLockout(t *testing.T) {
+	r, _ := setupTestServer(t)
+	cfg := config.Load()
+
+	username := "lockout_" + uuid.NewString()[:8]
+	w := performRequest(r, "POST", "/api/v1/register",
+		`{"username":"`+username+`","password":"12345678","email":"`+username+`@mail.ru"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Каждый прогон идёт со своего адреса, чтобы не упереться в лимит по IP.
+	ip := fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(256))
+	login := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/login",
+			strings.NewReader(`{"username":"`+username+`","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":40000"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 1; i < cfg.LoginMaxUserFailures; i++ {
+		if w := login("wrong-password"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failed attempt %d: expected 401, got %d", i, w.Code)
+		}
+	}
+
+	w = login("wrong-password")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("attempt reaching the limit: expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("lockout response without Retry-After header")
+	}
+
+	// Пока блокировка действует, не помогает и верный пароль.
+	if w := login("12345678"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("correct password while locked out: expected 429, got %d", w.Code)
+	}
+}
+
+func TestAccountHandlers_VerifyEmailAndResetPassword(t *testing.T) {
+	r, _ := setupTestServer(t)
+
+	username := "account_" + uuid.NewString()[:8]
+	email := username + "@mail.ru"
+	w := performRequest(r, "POST", "/api/v1/register",
+		`{"username":"`+username+`","password":"12345678","email":"`+email+`"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+	}
+	token := gjson.Get(w.Body.String(), "data.token").String()
+
+	if w = performRequest(r, "POST", "/api/v1/wallet/deposit", `{"currency":"USD","amount":100}`, token); w.Code != http.StatusOK {
+		t.Fatalf("deposit failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/wallet/withdraw", `{"currency":"USD","amount":10}`, token); w.Code != http.StatusForbidden {
+		t.Errorf("withdraw with unverified email: expected 403, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/holds", `{"currency":"USD","amount":10}`, token); w.Code != http.StatusForbidden {
+		t.Errorf("hold with unverified email: expected 403, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/transfers", `{"to_username":"Usertest","currency":"USD","amount":10}`, token); w.Code != http.StatusForbidden {
+		t.Errorf("transfer with unverified email: expected 403, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/orders", `{"currency":"USD","amount":10,"to_currency":"EUR","target_rate":"0.9"}`, token); w.Code != http.StatusForbidden {
+		t.Errorf("order with unverified email: expected 403, got %d", w.Code)
+	}
+
+	verifyToken := testMailer.lastToken(email)
+	if verifyToken == "" {
+		t.Fatal("no verification email sent on registration")
+	}
+	if w = performRequest(r, "POST", "/api/v1/email/verify", `{"token":"`+verifyToken+`"}`, ""); w.Code != http.StatusOK {
+		t.Fatalf("verify email failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/email/verify", `{"token":"`+verifyToken+`"}`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("reused verification token: expected 400, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/wallet/withdraw", `{"currency":"USD","amount":10}`, token); w.Code != http.StatusOK {
+		t.Errorf("withdraw after verification failed: %d %s", w.Code, w.Body.String())
+	}
+
+	if w = performRequest(r, "POST", "/api/v1/password/forgot", `{"email":"unknown_`+email+`"}`, ""); w.Code != http.StatusAccepted {
+		t.Errorf("reset for unknown email: expected 202, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/password/forgot", `{"email":"`+email+`"}`, ""); w.Code != http.StatusAccepted {
+		t.Fatalf("reset request failed: %d %s", w.Code, w.Body.String())
+	}
+	resetToken := testMailer.lastToken(email)
+	if resetToken == "" || resetToken == verifyToken {
+		t.Fatal("no password reset email sent")
+	}
+
+	w = performRequest(r, "POST", "/api/v1/password/reset", `{"token":"`+resetToken+`","password":"new-password"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("password reset failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Сброс пароля завершает все сессии.
+	if w = performRequest(r, "GET", "/api/v1/balance", "", token); w.Code != http.StatusUnauthorized {
+		t.Errorf("access token after password reset: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("login with old password: expected 401, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"new-password"}`, ""); w.Code != http.StatusOK {
+		t.Errorf("login with new password failed: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTwoFactorHandlers_LoginAndStepUp(t *testing.T) {
+	r, _ := setupTestServer(t)
+
+	username := "totp_" + uuid.NewString()[:8]
+	email := username + "@mail.ru"
+	w := performRequest(r, "POST", "/api/v1/register",
+		`{"username":"`+username+`","password":"12345678","email":"`+email+`"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/email/verify", `{"token":"`+testMailer.lastToken(email)+`"}`, ""); w.Code != http.StatusOK {
+		t.Fatalf("verify email failed: %d %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+	}
+	token := gjson.Get(w.Body.String(), "data.token").String()
+
+	w = performRequest(r, "POST", "/api/v1/2fa/totp/enroll", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("enroll failed: %d %s", w.Code, w.Body.String())
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(gjson.Get(w.Body.String(), "data.secret").String())
+	if err != nil {
+		t.Fatalf("invalid secret: %v", err)
+	}
+
+	// Каждый код принимается один раз, поэтому шаги берутся по возрастанию
+	// в пределах допустимого расхождения часов.
+	counter := totp.Counter(time.Now())
+	code := func(step int64) string { return totp.Code(secret, counter+step) }
+
+	w = performRequest(r, "POST", "/api/v1/2fa/totp/confirm", `{"code":"`+code(-1)+`"}`, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm failed: %d %s", w.Code, w.Body.String())
+	}
+	recovery := gjson.Get(w.Body.String(), "data.recovery_codes").Array()
+	if len(recovery) == 0 {
+		t.Fatal("no recovery codes returned")
+	}
+
+	w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusAccepted || gjson.Get(w.Body.String(), "data.token").Exists() {
+		t.Fatalf("login with 2FA: expected 202 challenge, got %d %s", w.Code, w.Body.String())
+	}
+	challenge := gjson.Get(w.Body.String(), "data.challenge_token").String()
+
+	if w = performRequest(r, "POST", "/api/v1/login/2fa", `{"challenge_token":"`+challenge+`","code":"`+code(-1)+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("replayed code: expected 401, got %d", w.Code)
+	}
+	w = performRequest(r, "POST", "/api/v1/login/2fa", `{"challenge_token":"`+challenge+`","code":"`+code(0)+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("second login step failed: %d %s", w.Code, w.Body.String())
+	}
+	token = gjson.Get(w.Body.String(), "data.token").String()
+	if w = performRequest(r, "POST", "/api/v1/login/2fa", `{"challenge_token":"`+challenge+`","code":"`+code(1)+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused challenge: expected 401, got %d", w.Code)
+	}
+
+	if w = performRequest(r, "POST", "/api/v1/wallet/deposit", `{"currency":"USD","amount":5000}`, token); w.Code != http.StatusOK {
+		t.Fatalf("deposit failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/wallet/withdraw", `{"currency":"USD","amount":10}`, token); w.Code != http.StatusOK {
+		t.Errorf("withdraw below step-up amount failed: %d %s", w.Code, w.Body.String())
+	}
+
+	withdraw := func(code, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/wallet/withdraw", strings.NewReader(`{"currency":"USD","amount":1500}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if code != "" {
+			req.Header.Set(models.TwoFactorCodeHeader, code)
+		}
+		if key != "" {
+			req.Header.Set(models.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Отказ без кода не занимает ключ идемпотентности, а повтор выполненного
+	// запроса возвращает сохранённый ответ без нового кода.
+	key := uuid.NewString()
+	if w = withdraw("", key); w.Code != http.StatusForbidden {
+		t.Errorf("large withdraw without code: expected 403, got %d", w.Code)
+	}
+	if w = withdraw(code(1), key); w.Code != http.StatusOK {
+		t.Errorf("large withdraw with code failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = withdraw(code(1), key); w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("repeated large withdraw: expected replayed 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w = withdraw(code(1), ""); w.Code != http.StatusForbidden {
+		t.Errorf("large withdraw with replayed code: expected 403, got %d", w.Code)
+	}
+
+	// Порог сравнивается в валюте операции.
+	if w = performRequest(r, "POST", "/api/v1/wallet/deposit", `{"currency":"RUB","amount":5000}`, token); w.Code != http.StatusOK {
+		t.Fatalf("RUB deposit failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/wallet/withdraw", `{"currency":"RUB","amount":1500}`, token); w.Code != http.StatusOK {
+		t.Errorf("RUB withdraw below RUB step-up amount failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Списание холда подтверждается так же, как вывод.
+	w = performRequest(r, "POST", "/api/v1/holds", `{"currency":"USD","amount":1500}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create hold failed: %d %s", w.Code, w.Body.String())
+	}
+	holdID := gjson.Get(w.Body.String(), "data.id").String()
+	if w = performRequest(r, "POST", "/api/v1/holds/"+holdID+"/capture", "", token); w.Code != http.StatusForbidden {
+		t.Errorf("large hold capture without code: expected 403, got %d", w.Code)
+	}
+	if w = performRequest(r, "POST", "/api/v1/holds/"+holdID+"/capture", `{"amount":100}`, token); w.Code != http.StatusOK {
+		t.Errorf("hold capture below step-up amount failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Код восстановления подходит для входа, но только один раз.
+	login := func() string {
+		w := performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+		return gjson.Get(w.Body.String(), "data.challenge_token").String()
+	}
+	if w = performRequest(r, "POST", "/api/v1/login/2fa", `{"challenge_token":"`+login()+`","code":"`+recovery[0].String()+`"}`, ""); w.Code != http.StatusOK {
+		t.Errorf("login with recovery code failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = performRequest(r, "POST", "/api/v1/login/2fa", `{"challenge_token":"`+login()+`","code":"`+recovery[0].String()+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: expected 401, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyHandlers_Scopes(t *testing.T) {
+	r, _ := setupTestServer(t)
+
+	username := "apikey_" + uuid.NewString()[:8]
+	w := performRequest(r, "POST", "/api/v1/register",
+		`{"username":"`+username+`","password":"12345678","email":"`+username+`@mail.ru"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: %d %s", w.Code, w.Body.String())
+	}
+	w = performRequest(r, "POST", "/api/v1/login", `{"username":"`+username+`","password":"12345678"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+	}
+	token := gjson.Get(w.Body.String(), "data.token").String()
+
+	// Ключ даёт долгосрочный доступ, поэтому создаётся только с кодом из приложения.
+	if w = performRequest(r, "POST", "/api/v1/api-keys", `{"name":"reporting","scopes":["balance:read"]}`, token); w.Code != http.StatusForbidden {
+		t.Errorf("create API key without 2FA: expected 403, got %d", w.Code)
+	}
+	w = performRequest(r, "POST", "/api/v1/2fa/totp/enroll", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("enroll failed: %d %s", w.Code, w.Body.String())
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(gjson.Get(w.Body.String(), "data.secret").String())
+	if err != nil {
+		t.Fatalf("invalid secret: %v", err)
+	}
+	counter := totp.Counter(time.Now())
+	code := func(step int64) string { return totp.Code(secret, counter+step) }
+	if w = performRequest(r, "POST", "/api/v1/2fa/totp/confirm", `{"code":"`+code(-1)+`"}`, token); w.Code != http.StatusOK {
+		t.Fatalf("confirm failed: %d %s", w.Code, w.Body.String())
+	}
+
+	createKey := func(body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/api-keys", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if code != "" {
+			req.Header.Set(models.TwoFactorCodeHeader, code)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w = createKey(`{"name":"reporting","scopes":["balance:read"]}`, ""); w.Code != http.StatusForbidden {
+		t.Errorf("create API key without code: expected 403, got %d", w.Code)
+	}
+	w = createKey(`{"name":"reporting","scopes":["balance:read"]}`, code(0))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create API key failed: %d %s", w.Code, w.Body.String())
+	}
+	key := gjson.Get(w.Body.String(), "data.key").String()
+	keyID := gjson.Get(w.Body.String(), "data.id").String()
+
+	withKey := func(method, path, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(models.APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w = withKey("GET", "/api/v1/balance", "", key); w.Code != http.StatusOK {
+		t.Errorf("balance with API key failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = withKey("POST", "/api/v1/wallet/deposit", `{"currency":"USD","amount":100}`, key); w.Code != http.StatusForbidden {
+		t.Errorf("deposit without scope: expected 403, got %d", w.Code)
+	}
+	if w = withKey("POST", "/api/v1/api-keys", `{"name":"escalation","scopes":["wallet:withdraw"]}`, key); w.Code != http.StatusUnauthorized {
+		t.Errorf("API key management with API key: expected 401, got %d", w.Code)
+	}
+	if w = withKey("GET", "/api/v1/balance", "", key+"x"); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown API key: expected 401, got %d", w.Code)
+	}
+
+	w = performRequest(r, "GET", "/api/v1/api-keys", "", token)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), key) {
+		t.Errorf("list API keys: got %d %s", w.Code, w.Body.String())
+	}
+
+	w = createKey(`{"name":"office","scopes":["balance:read"],"allowed_ips":["203.0.113.10"]}`, code(1))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create API key with allowlist failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = withKey("GET", "/api/v1/balance", "", gjson.Get(w.Body.String(), "data.key").String()); w.Code != http.StatusForbidden {
+		t.Errorf("API key from an address outside the allowlist: expected 403, got %d", w.Code)
+	}
+
+	if w = performRequest(r, "DELETE", "/api/v1/api-keys/"+keyID, "", token); w.Code != http.StatusOK {
+		t.Fatalf("revoke API key failed: %d %s", w.Code, w.Body.String())
+	}
+	if w = withKey("GET", "/api/v1/balance", "", key); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked API key: expected 401, got %d", w.Code)
+	}
 }
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldHandler struct {
+	service *services.HoldService
+}
+
+func NewHoldHandler(service *services.HoldService) *HoldHandler {
+	return &HoldHandler{service: service}
+}
+
+// CreateHold godoc
+// @Summary      Create hold
+// @Description  Reserve funds: the available balance decreases, the total balance does not
+// @Tags         holds
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateHoldRequest true "Hold data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      201 {object} models.Response{data=models.Hold} "Hold created"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, or email is not verified"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /holds [post]
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	var req models.CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.L.Warnw("Hold request invalid", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	hold, err := h.service.CreateHold(c, userID, amount, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		holdError(c, userID, "Create hold failed", err)
+		return
+	}
+
+	logger.L.Infow("Hold created", "userID", userID, "holdID", hold.ID)
+	c.JSON(http.StatusCreated, models.Response{Success: true, Data: hold})
+}
+
+// GetHold godoc
+// @Summary      Get hold
+// @Description  Get hold of authenticated user
+// @Tags         holds
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Hold ID"
+// @Success      200 {object} models.Response{data=models.Hold} "Hold retrieved"
+// @Failure      400 {object} models.Response "Invalid hold id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Hold not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /holds/{id} [get]
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	userID, holdID, ok := holdParams(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.GetHold(c, userID, holdID)
+	if err != nil {
+		holdError(c, userID, "Get hold failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: hold})
+}
+
+// CaptureHold godoc
+// @Summary      Capture hold
+// @Description  Charge the whole remaining hold, or a part of it when amount is set. The withdraw fee is charged on top from the available balance
+// @Tags         holds
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Hold ID"
+// @Param        request body models.CaptureHoldRequest false "Capture data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Param        X-2FA-Code header string false "TOTP code, required from the step-up amount"
+// @Success      200 {object} models.Response{data=models.Hold} "Hold captured"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds for the withdraw fee"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, spending limit exceeded, email is not verified, or a valid TOTP code is required"
+// @Failure      404 {object} models.Response "Hold not found"
+// @Failure      409 {object} models.Response "Hold is not active, has expired or reserves a limit order"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /holds/{id}/capture [post]
+func (h *HoldHandler) CaptureHold(c *gin.Context) {
+	var req models.CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, holdID, ok := holdParams(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.CaptureHold(c, userID, holdID, req.Amount)
+	if err != nil {
+		holdError(c, userID, "Capture hold failed", err)
+		return
+	}
+
+	logger.L.Infow("Hold captured", "userID", userID, "holdID", hold.ID, "status", hold.Status)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: hold})
+}
+
+// VoidHold godoc
+// @Summary      Void hold
+// @Description  Cancel hold and return the remaining reserve to the available balance
+// @Tags         holds
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Hold ID"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response{data=models.Hold} "Hold voided"
+// @Failure      400 {object} models.Response "Invalid hold id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Hold not found"
+// @Failure      409 {object} models.Response "Hold is not active, has expired or reserves a limit order"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /holds/{id}/void [post]
+func (h *HoldHandler) VoidHold(c *gin.Context) {
+	userID, holdID, ok := holdParams(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.VoidHold(c, userID, holdID)
+	if err != nil {
+		holdError(c, userID, "Void hold failed", err)
+		return
+	}
+
+	logger.L.Infow("Hold voided", "userID", userID, "holdID", hold.ID)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: hold})
+}
+
+func holdParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, holdID, true
+}
+
+func holdError(c *gin.Context, userID uuid.UUID, msg string, err error) {
+	switch {
+	case errors.Is(err, models.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgHoldNotFound,
+		})
+	case errors.Is(err, models.ErrHoldNotActive), errors.Is(err, models.ErrHoldExpired):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgHoldNotActive,
+			Details: err.Error(),
+		})
+	case errors.Is(err, models.ErrHoldOwnedByOrder):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgHoldOwnedByOrder,
+		})
+	case walletStatusMessage(err) != "":
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		respondWalletStatus(c, err)
+	case errors.Is(err, models.ErrLimitExceeded):
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		respondLimitExceeded(c, err)
+	case errors.Is(err, models.ErrInvalidHoldTTL),
+		errors.Is(err, models.ErrCaptureExceedsHold),
+		errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, models.ErrTooPrecise),
+		errors.Is(err, models.ErrUnsupportedCurrency):
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgHoldFailed,
+			Details: err.Error(),
+		})
+	default:
+		logger.L.Errorw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+	}
+}
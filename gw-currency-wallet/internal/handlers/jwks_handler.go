@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"gw-currency-wallet/internal/jwks"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keys *jwks.KeySet
+}
+
+func NewJWKSHandler(keys *jwks.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS serves the public keys that verify access tokens in the standard
+// JWK Set format, so other services can check tokens without the private keys.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
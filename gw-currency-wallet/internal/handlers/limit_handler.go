@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LimitHandler struct {
+	service *services.LimitService
+}
+
+func NewLimitHandler(service *services.LimitService) *LimitHandler {
+	return &LimitHandler{service: service}
+}
+
+// GetLimits godoc
+// @Summary      Get spending limits
+// @Description  List daily and monthly withdraw and exchange limits in effect for the current user with the amount left in the current period. Exchanges count the sold currency
+// @Tags         wallet
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.LimitUsage} "Spending limits"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /limits [get]
+func (h *LimitHandler) GetLimits(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	limits, err := h.service.GetLimits(c.Request.Context(), userID)
+	if err != nil {
+		logger.L.Errorw("Get limits failed", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: limits})
+}
@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListWallets godoc
+// @Summary      List wallets
+// @Description  List all wallets of the current user with their balances, the main wallet first. Closed wallets are included
+// @Tags         wallets
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.Wallet} "Wallets"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets [get]
+func (h *WalletHandler) ListWallets(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	wallets, err := h.service.ListWallets(c, userID)
+	if err != nil {
+		logger.L.Errorw("Failed to list wallets", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallets})
+}
+
+// CreateWallet godoc
+// @Summary      Open wallet
+// @Description  Open an extra named wallet with its own balances. Names are case-insensitively unique among the user's open wallets
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.WalletNameRequest true "Wallet name"
+// @Success      201 {object} models.Response{data=models.Wallet} "Wallet opened"
+// @Failure      400 {object} models.Response "Invalid name"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      409 {object} models.Response "Name taken or too many open wallets"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets [post]
+func (h *WalletHandler) CreateWallet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var req models.WalletNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, err := h.service.OpenWallet(c, userID, req.Name)
+	if err != nil {
+		walletError(c, "Failed to open wallet", userID, err)
+		return
+	}
+
+	logger.L.Infow("Wallet opened", "userID", userID, "walletID", wallet.ID)
+	c.JSON(http.StatusCreated, models.Response{Success: true, Data: wallet})
+}
+
+// GetWalletByID godoc
+// @Summary      Get wallet
+// @Description  Get one wallet of the current user with its balances
+// @Tags         wallets
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet"
+// @Failure      400 {object} models.Response "Invalid wallet id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets/{id} [get]
+func (h *WalletHandler) GetWalletByID(c *gin.Context) {
+	userID, walletID, ok := ownWallet(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.GetWallet(c, userID, walletID)
+	if err != nil {
+		walletError(c, "Failed to get wallet", userID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// RenameWallet godoc
+// @Summary      Rename wallet
+// @Description  Rename an open wallet of the current user
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body models.WalletNameRequest true "New name"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet renamed"
+// @Failure      400 {object} models.Response "Invalid wallet id or name"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Name taken or wallet is closed"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets/{id} [patch]
+func (h *WalletHandler) RenameWallet(c *gin.Context) {
+	userID, walletID, ok := ownWallet(c)
+	if !ok {
+		return
+	}
+
+	var req models.WalletNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, err := h.service.RenameWallet(c, userID, walletID, req.Name)
+	if err != nil {
+		walletError(c, "Failed to rename wallet", userID, err)
+		return
+	}
+
+	logger.L.Infow("Wallet renamed", "userID", userID, "walletID", walletID)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// CloseWallet godoc
+// @Summary      Close wallet
+// @Description  Close an extra wallet of the current user. The wallet must have no funds and must not be frozen or blocked; the main wallet cannot be closed. Closing is final
+// @Tags         wallets
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Success      200 {object} models.Response{data=models.Wallet} "Wallet closed"
+// @Failure      400 {object} models.Response "Invalid wallet id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Main wallet or wallet has funds"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets/{id}/close [post]
+func (h *WalletHandler) CloseWallet(c *gin.Context) {
+	userID, walletID, ok := ownWallet(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.service.CloseWallet(c, userID, walletID)
+	if err != nil {
+		walletError(c, "Failed to close wallet", userID, err)
+		return
+	}
+
+	logger.L.Infow("Wallet closed", "userID", userID, "walletID", walletID)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// TransferBetweenWallets godoc
+// @Summary      Transfer between own wallets
+// @Description  Move money of one currency between two wallets of the caller. Internal transfers are free and need no TOTP code
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.InternalTransferRequest true "Transfer data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response{data=models.Wallet} "Transfer successful, returns the source wallet"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /wallets/transfers [post]
+func (h *WalletHandler) TransferBetweenWallets(c *gin.Context) {
+	var req models.InternalTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	fromID, errFrom := uuid.Parse(req.FromWalletID)
+	toID, errTo := uuid.Parse(req.ToWalletID)
+	if err := errors.Join(errFrom, errTo); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidWalletID,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, err := h.service.TransferBetween(c, userID, fromID, toID, amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrWalletNotFound):
+			respondWalletNotFound(c, err)
+		case walletStatusMessage(err) != "":
+			logger.L.Warnw("Internal transfer failed", "userID", userID, "error", err.Error())
+			respondWalletStatus(c, err)
+		case errors.Is(err, models.ErrSelfTransfer),
+			errors.Is(err, models.ErrInvalidAmount),
+			errors.Is(err, models.ErrInsufficientFunds),
+			errors.Is(err, models.ErrUnsupportedCurrency):
+			logger.L.Warnw("Internal transfer failed", "userID", userID, "error", err.Error())
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgTransferFailed,
+				Details: err.Error(),
+			})
+		default:
+			logger.L.Errorw("Internal transfer failed", "userID", userID, "error", err.Error())
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+		}
+		return
+	}
+
+	logger.L.Infow("Internal transfer successful", "userID", userID, "from", fromID, "to", toID, "currency", amount.Currency)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet})
+}
+
+// DepositToWallet godoc
+// @Summary      Deposit funds to a wallet
+// @Description  Deposit money to the given wallet of the current user
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body models.WalletOperationReq true "Deposit data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response "Deposit successful"
+// @Failure      400 {object} models.Response "Invalid request or deposit failed"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen or closed"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Router       /wallets/{id}/deposit [post]
+func (h *WalletHandler) DepositToWallet(c *gin.Context) {
+	if walletID, ok := walletIDParam(c); ok {
+		h.deposit(c, walletID)
+	}
+}
+
+// WithdrawFromWallet godoc
+// @Summary      Withdraw funds from a wallet
+// @Description  Withdraw money from the given wallet of the current user. A withdrawal fee, if any, is charged on top of the amount. Requires a verified email
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body models.WalletOperationReq true "Withdraw data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Param        X-2FA-Code header string false "TOTP code, required from the step-up amount"
+// @Success      200 {object} models.Response "Withdraw successful"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, spending limit exceeded, email is not verified, or a valid TOTP code is required"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Router       /wallets/{id}/withdraw [post]
+func (h *WalletHandler) WithdrawFromWallet(c *gin.Context) {
+	if walletID, ok := walletIDParam(c); ok {
+		h.withdraw(c, walletID)
+	}
+}
+
+// ExchangeInWallet godoc
+// @Summary      Exchange currency in a wallet
+// @Description  Exchange money between currencies inside the given wallet, at the current rate or at the rate and fee of a quote issued for this wallet
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body models.ExchangeRequest true "Exchange data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} object "Exchange successful"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, or spending limit exceeded"
+// @Failure      404 {object} models.Response "Wallet or quote not found"
+// @Failure      409 {object} models.Response "Quote expired or already used, or request with this idempotency key is in progress"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Router       /wallets/{id}/exchange [post]
+func (h *WalletHandler) ExchangeInWallet(c *gin.Context) {
+	if walletID, ok := walletIDParam(c); ok {
+		h.exchange(c, walletID)
+	}
+}
+
+// QuoteExchangeInWallet godoc
+// @Summary      Get exchange quote for a wallet
+// @Description  Lock the current exchange rate and fee for an amount in the given wallet; pass the returned quote_id to /wallets/{id}/exchange before it expires
+// @Tags         wallets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body models.ExchangeQuoteRequest true "Quote data"
+// @Success      200 {object} models.Response{data=models.ExchangeQuote} "Quote created"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Router       /wallets/{id}/exchange/quote [post]
+func (h *WalletHandler) QuoteExchangeInWallet(c *gin.Context) {
+	if walletID, ok := walletIDParam(c); ok {
+		h.quoteExchange(c, walletID)
+	}
+}
+
+// walletIDParam parses the wallet id from the path.
+func walletIDParam(c *gin.Context) (uuid.UUID, bool) {
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidWalletID,
+			Details: err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	return walletID, true
+}
+
+// ownWallet returns the current user and the wallet id from the path.
+func ownWallet(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	walletID, ok := walletIDParam(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, walletID, true
+}
+
+func walletError(c *gin.Context, msg string, userID uuid.UUID, err error) {
+	var statusCode int
+	var errorMsg string
+
+	switch {
+	case errors.Is(err, models.ErrWalletNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = messages.MsgWalletNotFound
+	case errors.Is(err, models.ErrInvalidWalletName):
+		statusCode = http.StatusBadRequest
+		errorMsg = messages.MsgInvalidWalletName
+	case errors.Is(err, models.ErrWalletNameTaken):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgWalletNameTaken
+	case errors.Is(err, models.ErrTooManyWallets):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgTooManyWallets
+	case errors.Is(err, models.ErrDefaultWallet):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgDefaultWallet
+	case errors.Is(err, models.ErrWalletNotEmpty):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgWalletNotEmpty
+	case errors.Is(err, models.ErrWalletClosed):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgWalletClosed
+	case walletStatusMessage(err) != "":
+		statusCode = http.StatusForbidden
+		errorMsg = walletStatusMessage(err)
+	default:
+		logger.L.Errorw(msg, "userID", userID, "error", err.Error())
+		statusCode = http.StatusInternalServerError
+		errorMsg = messages.MsgInternalError
+	}
+
+	c.JSON(statusCode, models.Response{
+		Success: false,
+		Error:   errorMsg,
+	})
+}
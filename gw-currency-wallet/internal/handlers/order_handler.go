@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrderHandler struct {
+	service *services.OrderService
+}
+
+func NewOrderHandler(service *services.OrderService) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// PlaceOrder godoc
+// @Summary      Place limit order
+// @Description  Reserve amount and fee and convert them once the rate reaches target_rate. The order is filled at the current rate of the exchanger
+// @Tags         orders
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.PlaceOrderRequest true "Order data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      201 {object} models.Response{data=models.LimitOrder} "Order placed"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, or email is not verified"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /orders [post]
+func (h *OrderHandler) PlaceOrder(c *gin.Context) {
+	var req models.PlaceOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.L.Warnw("Order request invalid", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	walletID := uuid.Nil
+	if req.WalletID != "" {
+		var err error
+		if walletID, err = uuid.Parse(req.WalletID); err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidWalletID,
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	order, err := h.service.PlaceOrder(c, userID, walletID, amount, req.ToCurrency, req.TargetRate,
+		time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		orderError(c, userID, "Place order failed", err)
+		return
+	}
+
+	logger.L.Infow("Order placed", "userID", userID, "orderID", order.ID, "targetRate", order.TargetRate.String())
+	c.JSON(http.StatusCreated, models.Response{Success: true, Data: order})
+}
+
+// ListOrders godoc
+// @Summary      List limit orders
+// @Description  Get limit orders of authenticated user, newest first
+// @Tags         orders
+// @Security     BearerAuth
+// @Produce      json
+// @Param        status query string false "Filter by status: open, filled, cancelled, expired or failed"
+// @Success      200 {object} models.Response{data=[]models.LimitOrder} "Orders retrieved"
+// @Failure      400 {object} models.Response "Invalid status"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /orders [get]
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	orders, err := h.service.ListOrders(c, userID, models.OrderStatus(c.Query("status")))
+	if err != nil {
+		orderError(c, userID, "List orders failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: orders})
+}
+
+// GetOrder godoc
+// @Summary      Get limit order
+// @Description  Get limit order of authenticated user
+// @Tags         orders
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Order ID"
+// @Success      200 {object} models.Response{data=models.LimitOrder} "Order retrieved"
+// @Failure      400 {object} models.Response "Invalid order id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Order not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /orders/{id} [get]
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	userID, orderID, ok := orderParams(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(c, userID, orderID)
+	if err != nil {
+		orderError(c, userID, "Get order failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: order})
+}
+
+// CancelOrder godoc
+// @Summary      Cancel limit order
+// @Description  Cancel open limit order and return the reserve to the available balance
+// @Tags         orders
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Order ID"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Success      200 {object} models.Response{data=models.LimitOrder} "Order cancelled"
+// @Failure      400 {object} models.Response "Invalid order id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Order not found"
+// @Failure      409 {object} models.Response "Order is already filled, cancelled or expired"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	userID, orderID, ok := orderParams(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.CancelOrder(c, userID, orderID)
+	if err != nil {
+		orderError(c, userID, "Cancel order failed", err)
+		return
+	}
+
+	logger.L.Infow("Order cancelled", "userID", userID, "orderID", order.ID)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: order})
+}
+
+func orderParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidOrderID,
+			Details: err.Error(),
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, orderID, true
+}
+
+func orderError(c *gin.Context, userID uuid.UUID, msg string, err error) {
+	switch {
+	case errors.Is(err, models.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgOrderNotFound,
+		})
+	case respondWalletNotFound(c, err):
+	case errors.Is(err, models.ErrOrderNotOpen):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgOrderNotOpen,
+		})
+	case walletStatusMessage(err) != "":
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		respondWalletStatus(c, err)
+	case errors.Is(err, models.ErrInvalidOrderTTL),
+		errors.Is(err, models.ErrInvalidOrder),
+		errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, models.ErrSameCurrency),
+		errors.Is(err, models.ErrTooPrecise),
+		errors.Is(err, models.ErrUnsupportedCurrency):
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgOrderFailed,
+			Details: err.Error(),
+		})
+	default:
+		logger.L.Errorw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScheduleHandler struct {
+	service *services.ScheduleService
+}
+
+func NewScheduleHandler(service *services.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+// ListSchedules godoc
+// @Summary      List standing orders
+// @Description  Get all standing orders of authenticated user
+// @Tags         schedules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.ScheduledTransfer} "Standing orders retrieved"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	schedules, err := h.service.ListSchedules(c, userID)
+	if err != nil {
+		scheduleError(c, userID, "List schedules failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: schedules})
+}
+
+// CreateSchedule godoc
+// @Summary      Create standing order
+// @Description  Create a recurring transfer between own wallets or exchange inside a wallet, run at the times of a cron expression in UTC
+// @Tags         schedules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateScheduleRequest true "Standing order data"
+// @Success      201 {object} models.Response{data=models.ScheduledTransfer} "Standing order created"
+// @Failure      400 {object} models.Response "Invalid request, amount or cron expression"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is closed"
+// @Failure      404 {object} models.Response "Wallet not found"
+// @Failure      409 {object} models.Response "Maximum number of standing orders reached"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req models.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	schedule := &models.ScheduledTransfer{
+		UserID:     userID,
+		Kind:       req.Kind,
+		ToCurrency: req.ToCurrency,
+		Cron:       req.Cron,
+	}
+
+	var errFrom, errTo error
+	if req.WalletID != "" {
+		schedule.WalletID, errFrom = uuid.Parse(req.WalletID)
+	}
+	if req.ToWalletID != "" {
+		var toID uuid.UUID
+		toID, errTo = uuid.Parse(req.ToWalletID)
+		schedule.ToWalletID = &toID
+	}
+	if err := errors.Join(errFrom, errTo); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidWalletID,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+	schedule.Amount = amount
+
+	if err := h.service.CreateSchedule(c, schedule); err != nil {
+		scheduleError(c, userID, "Create schedule failed", err)
+		return
+	}
+
+	logger.L.Infow("Schedule created", "userID", userID, "scheduleID", schedule.ID, "kind", schedule.Kind)
+	c.JSON(http.StatusCreated, models.Response{Success: true, Data: schedule})
+}
+
+// GetSchedule godoc
+// @Summary      Get standing order
+// @Description  Get standing order of authenticated user
+// @Tags         schedules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Standing order ID"
+// @Success      200 {object} models.Response{data=models.ScheduledTransfer} "Standing order retrieved"
+// @Failure      400 {object} models.Response "Invalid standing order id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Standing order not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules/{id} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	userID, scheduleID, ok := scheduleParams(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(c, userID, scheduleID)
+	if err != nil {
+		scheduleError(c, userID, "Get schedule failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: schedule})
+}
+
+// UpdateSchedule godoc
+// @Summary      Update standing order
+// @Description  Change amount or cron expression, pause or resume a standing order. Resuming resets the failure count
+// @Tags         schedules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Standing order ID"
+// @Param        request body models.UpdateScheduleRequest true "Fields to change"
+// @Success      200 {object} models.Response{data=models.ScheduledTransfer} "Standing order updated"
+// @Failure      400 {object} models.Response "Invalid request, amount or cron expression"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Standing order not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules/{id} [patch]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	var req models.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, scheduleID, ok := scheduleParams(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.UpdateSchedule(c, userID, scheduleID, req)
+	if err != nil {
+		scheduleError(c, userID, "Update schedule failed", err)
+		return
+	}
+
+	logger.L.Infow("Schedule updated", "userID", userID, "scheduleID", schedule.ID, "status", schedule.Status)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: schedule})
+}
+
+// DeleteSchedule godoc
+// @Summary      Delete standing order
+// @Description  Delete standing order together with its run history
+// @Tags         schedules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Standing order ID"
+// @Success      200 {object} models.Response "Standing order deleted"
+// @Failure      400 {object} models.Response "Invalid standing order id"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Standing order not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules/{id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	userID, scheduleID, ok := scheduleParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSchedule(c, userID, scheduleID); err != nil {
+		scheduleError(c, userID, "Delete schedule failed", err)
+		return
+	}
+
+	logger.L.Infow("Schedule deleted", "userID", userID, "scheduleID", scheduleID)
+	c.JSON(http.StatusOK, models.Response{Success: true})
+}
+
+// ListScheduleRuns godoc
+// @Summary      List standing order runs
+// @Description  Get run history of a standing order, newest first
+// @Tags         schedules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Standing order ID"
+// @Param        limit query int false "Maximum number of runs, up to 100"
+// @Success      200 {object} models.Response{data=[]models.ScheduleRun} "Runs retrieved"
+// @Failure      400 {object} models.Response "Invalid standing order id or limit"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      404 {object} models.Response "Standing order not found"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /schedules/{id}/runs [get]
+func (h *ScheduleHandler) ListScheduleRuns(c *gin.Context) {
+	userID, scheduleID, ok := scheduleParams(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidRequest,
+				Details: "limit must be a number",
+			})
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.service.ListRuns(c, userID, scheduleID, limit)
+	if err != nil {
+		scheduleError(c, userID, "List schedule runs failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: runs})
+}
+
+func scheduleParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidScheduleID,
+			Details: err.Error(),
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, scheduleID, true
+}
+
+func scheduleError(c *gin.Context, userID uuid.UUID, msg string, err error) {
+	switch {
+	case errors.Is(err, models.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Error:   messages.MsgScheduleNotFound,
+		})
+	case respondWalletNotFound(c, err):
+	case errors.Is(err, models.ErrTooManySchedules):
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Error:   messages.MsgTooManySchedules,
+		})
+	case walletStatusMessage(err) != "":
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		respondWalletStatus(c, err)
+	case errors.Is(err, models.ErrInvalidSchedule),
+		errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, models.ErrSelfTransfer),
+		errors.Is(err, models.ErrSameCurrency),
+		errors.Is(err, models.ErrTooPrecise),
+		errors.Is(err, models.ErrUnsupportedCurrency):
+		logger.L.Warnw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidSchedule,
+			Details: err.Error(),
+		})
+	default:
+		logger.L.Errorw(msg, "userID", userID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransactionHandler struct {
+	service *services.TransactionService
+}
+
+func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+	return &TransactionHandler{service: service}
+}
+
+// ListTransactions godoc
+// @Summary      Get transaction history
+// @Description  Get wallet operations of authenticated user, newest first, with cursor pagination
+// @Tags         transactions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        type      query string false "Operation type" Enums(deposit, withdraw, exchange, transfer_in, transfer_out, hold_capture)
+// @Param        currency  query string false "Currency code"
+// @Param        from      query string false "Start of date range (RFC3339 or YYYY-MM-DD), inclusive"
+// @Param        to        query string false "End of date range (RFC3339 or YYYY-MM-DD), exclusive"
+// @Param        cursor    query string false "Cursor from the previous page"
+// @Param        limit     query int    false "Page size (max 100)"
+// @Success      200 {object} models.Response{data=models.TransactionPage} "Transactions retrieved"
+// @Failure      400 {object} models.Response "Invalid filter"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /transactions [get]
+func (h *TransactionHandler) ListTransactions(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		logger.L.Warnw("Invalid transactions filter", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.service.ListTransactions(c, userID, filter)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTransactionType, services.ErrInvalidDateRange:
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidRequest,
+				Details: err.Error(),
+			})
+		default:
+			logger.L.Errorw("Failed to list transactions", "userID", userID, "error", err.Error())
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: page})
+}
+
+func parseTransactionFilter(c *gin.Context) (models.TransactionFilter, error) {
+	filter := models.TransactionFilter{
+		Type:     models.TransactionType(c.Query("type")),
+		Currency: models.Currency(c.Query("currency")),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = limit
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := models.DecodeTransactionCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := parseTime(v)
+		if err != nil {
+			return filter, err
+		}
+		*target = &t
+	}
+
+	return filter, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+
+	return time.Parse(time.DateOnly, v)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TransferHandler struct {
+	service *services.TransferService
+}
+
+func NewTransferHandler(service *services.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// Transfer godoc
+// @Summary      Transfer funds to another user
+// @Description  Move money of one currency from the caller's wallet to another user found by username or user id
+// @Tags         wallet
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TransferRequest true "Transfer data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Param        X-2FA-Code header string false "TOTP code, required from the step-up amount"
+// @Success      200 {object} models.Response "Transfer successful"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, spending limit exceeded, email is not verified, or a valid TOTP code is required"
+// @Failure      404 {object} models.Response "Recipient not found"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /transfers [post]
+func (h *TransferHandler) Transfer(c *gin.Context) {
+	var req models.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.L.Warnw("Transfer request invalid", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var toUserID uuid.UUID
+	if req.ToUserID != "" {
+		parsed, err := uuid.Parse(req.ToUserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidUserID,
+				Details: err.Error(),
+			})
+			return
+		}
+		toUserID = parsed
+	}
+
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		logger.L.Warnw("Transfer amount invalid", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, err := h.service.Transfer(c, userID, req.ToUsername, toUserID, amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRecipientNotFound), errors.Is(err, models.ErrWalletNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success: false,
+				Error:   messages.MsgRecipientNotFound,
+			})
+		case walletStatusMessage(err) != "":
+			logger.L.Warnw("Transfer failed", "userID", userID, "error", err.Error())
+			respondWalletStatus(c, err)
+		case errors.Is(err, models.ErrLimitExceeded):
+			logger.L.Warnw("Transfer failed", "userID", userID, "error", err.Error())
+			respondLimitExceeded(c, err)
+		case errors.Is(err, services.ErrInvalidRecipient),
+			errors.Is(err, models.ErrSelfTransfer),
+			errors.Is(err, models.ErrInvalidAmount),
+			errors.Is(err, models.ErrInsufficientFunds),
+			errors.Is(err, models.ErrUnsupportedCurrency):
+			logger.L.Warnw("Transfer failed", "userID", userID, "error", err.Error())
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgTransferFailed,
+				Details: err.Error(),
+			})
+		default:
+			logger.L.Errorw("Transfer failed", "userID", userID, "error", err.Error())
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+		}
+		return
+	}
+
+	logger.L.Infow("Transfer successful", "userID", userID, "currency", amount.Currency)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet.GetAllBalances()})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	service *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service}
+}
+
+// EnrollTOTP godoc
+// @Summary      Start TOTP enrollment
+// @Description  Generate a new authenticator app secret. Show provisioning_uri as a QR code or let the user type the secret, then confirm with the first code. Repeating enrollment replaces an unconfirmed secret
+// @Tags         2fa
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.Response{data=models.TOTPEnrollment} "Secret generated"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      409 {object} models.Response "Two-factor authentication is already enabled"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /2fa/totp/enroll [post]
+func (h *TwoFactorHandler) EnrollTOTP(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	enrollment, err := h.service.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorError(c, "TOTP enrollment failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    enrollment,
+	})
+}
+
+// ConfirmTOTP godoc
+// @Summary      Confirm TOTP enrollment
+// @Description  Enable two-factor authentication with the first code from the authenticator app. Returns single-use recovery codes that are not shown again
+// @Tags         2fa
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TOTPCodeRequest true "Code from the authenticator app"
+// @Success      200 {object} models.Response{data=models.RecoveryCodes} "Two-factor authentication enabled"
+// @Failure      400 {object} models.Response "Invalid request data or enrollment not started"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Invalid code"
+// @Failure      409 {object} models.Response "Two-factor authentication is already enabled"
+// @Failure      429 {object} models.Response "Too many invalid codes"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /2fa/totp/confirm [post]
+func (h *TwoFactorHandler) ConfirmTOTP(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.service.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorError(c, "TOTP confirmation failed", err)
+		return
+	}
+
+	logger.L.Infow("Two-factor authentication enabled", "userID", userID)
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    codes,
+	})
+}
+
+// DisableTOTP godoc
+// @Summary      Disable TOTP
+// @Description  Turn two-factor authentication off with a code from the authenticator app or a recovery code. Remaining recovery codes stop working
+// @Tags         2fa
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TOTPCodeRequest true "TOTP or recovery code"
+// @Success      200 {object} models.Response "Two-factor authentication disabled"
+// @Failure      400 {object} models.Response "Invalid request data or two-factor authentication is not enabled"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Invalid code"
+// @Failure      429 {object} models.Response "Too many invalid codes"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /2fa/totp/disable [post]
+func (h *TwoFactorHandler) DisableTOTP(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		twoFactorError(c, "TOTP disabling failed", err)
+		return
+	}
+
+	logger.L.Infow("Two-factor authentication disabled", "userID", userID)
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    gin.H{"message": "Two-factor authentication disabled"},
+	})
+}
+
+func twoFactorError(c *gin.Context, msg string, err error) {
+	userID, _ := c.Get("user_id")
+
+	var statusCode int
+	var errorMsg string
+
+	switch {
+	case errors.Is(err, models.ErrInvalidTwoFactorCode), errors.Is(err, models.ErrTwoFactorRequired):
+		logger.L.Warnw(msg, "userID", userID, "ip", c.ClientIP(), "error", err.Error())
+		statusCode = http.StatusForbidden
+		errorMsg = messages.MsgInvalidTwoFactorCode
+	case errors.Is(err, models.ErrTwoFactorLocked):
+		statusCode = http.StatusTooManyRequests
+		errorMsg = messages.MsgTwoFactorLocked
+	case errors.Is(err, models.ErrTwoFactorNotEnabled):
+		statusCode = http.StatusBadRequest
+		errorMsg = messages.MsgTwoFactorNotEnabled
+	case errors.Is(err, models.ErrTwoFactorAlreadyEnabled):
+		statusCode = http.StatusConflict
+		errorMsg = messages.MsgTwoFactorAlreadyEnabled
+	case errors.Is(err, services.ErrUserNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = messages.MsgUserNotFound
+	default:
+		logger.L.Errorw(msg, "userID", userID, "error", err.Error())
+		statusCode = http.StatusInternalServerError
+		errorMsg = messages.MsgInternalError
+	}
+
+	c.JSON(statusCode, models.Response{
+		Success: false,
+		Error:   errorMsg,
+	})
+}
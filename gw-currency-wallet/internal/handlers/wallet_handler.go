@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/pkg/logger"
 	"gw-currency-wallet/internal/pkg/messages"
@@ -21,11 +22,11 @@ func NewWalletHandler(service *services.WalletService) *WalletHandler {
 
 // GetWallet godoc
 // @Summary      Get wallet balances
-// @Description  Get all currency balances for authenticated user
+// @Description  Get available, held and total balances per currency for authenticated user
 // @Tags         wallet
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200 {object} models.Response "Wallet balances retrieved"
+// @Success      200 {object} models.Response{data=map[string]models.BalanceView} "Wallet balances retrieved"
 // @Failure      401 {object} models.Response "Unauthorized"
 // @Failure      500 {object} models.Response "Internal server error"
 // @Router       /balance [get]
@@ -53,7 +54,7 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 	}
 
 	logger.L.Infow("Wallet retrieved", "userID", userID)
-	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet.GetAllBalances()})
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet.BalanceDetails()})
 }
 
 // Deposit godoc
@@ -64,11 +65,19 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request body models.WalletOperationReq true "Deposit data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
 // @Success      200 {object} models.Response "Deposit successful"
 // @Failure      400 {object} models.Response "Invalid request or deposit failed"
 // @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen or closed"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
 // @Router       /deposit [post]
 func (h *WalletHandler) Deposit(c *gin.Context) {
+	h.deposit(c, uuid.Nil)
+}
+
+func (h *WalletHandler) deposit(c *gin.Context, walletID uuid.UUID) {
 	var req models.WalletOperationReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.L.Warnw("Deposit request invalid", "error", err.Error())
@@ -91,9 +100,25 @@ func (h *WalletHandler) Deposit(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.service.DepositWallet(c, userID, models.Currency(req.Currency), req.Amount)
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		logger.L.Warnw("Deposit amount invalid", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+
+		return
+	}
+
+	wallet, err := h.service.DepositTo(c, userID, walletID, amount)
 	if err != nil {
 		logger.L.Warnw("Deposit failed", "userID", userID, "error", err.Error())
+		if respondWalletNotFound(c, err) || respondWalletStatus(c, err) {
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, models.Response{
 			Success: false,
 			Error:   messages.MsgDepositFailed,
@@ -109,18 +134,27 @@ func (h *WalletHandler) Deposit(c *gin.Context) {
 
 // Withdraw godoc
 // @Summary      Withdraw funds
-// @Description  Withdraw money from wallet in specified currency
+// @Description  Withdraw money from wallet in specified currency. A withdrawal fee, if any, is charged on top of the amount and returned in details. Requires a verified email
 // @Tags         wallet
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.WalletOperationReq true "Withdraw data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
+// @Param        X-2FA-Code header string false "TOTP code, required from the step-up amount"
 // @Success      200 {object} models.Response "Withdraw successful"
 // @Failure      400 {object} models.Response "Invalid request or insufficient funds"
 // @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, spending limit exceeded, email is not verified, or a valid TOTP code is required"
+// @Failure      409 {object} models.Response "Request with this idempotency key is in progress or did not finish"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
 // @Failure      500 {object} models.Response "Internal server error"
 // @Router       /withdraw [post]
 func (h *WalletHandler) Withdraw(c *gin.Context) {
+	h.withdraw(c, uuid.Nil)
+}
+
+func (h *WalletHandler) withdraw(c *gin.Context, walletID uuid.UUID) {
 	var req models.WalletOperationReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.L.Warnw("Withdraw request invalid", "error", err.Error())
@@ -143,9 +177,25 @@ func (h *WalletHandler) Withdraw(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.service.WithdrawWallet(c, userID, models.Currency(req.Currency), req.Amount)
+	amount, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		logger.L.Warnw("Withdraw amount invalid", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+
+		return
+	}
+
+	wallet, fee, err := h.service.WithdrawFrom(c, userID, walletID, amount)
 	if err != nil {
 		logger.L.Warnw("Withdraw failed", "userID", userID, "error", err.Error())
+		if respondWalletNotFound(c, err) || respondWalletStatus(c, err) || respondLimitExceeded(c, err) {
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, models.Response{
 			Success: false,
 			Error:   messages.MsgWithdrawFailed,
@@ -155,23 +205,36 @@ func (h *WalletHandler) Withdraw(c *gin.Context) {
 		return
 	}
 
-	logger.L.Infow("Withdraw successful", "userID", userID)
-	c.JSON(http.StatusOK, models.Response{Success: true, Data: wallet.GetAllBalances()})
+	logger.L.Infow("Withdraw successful", "userID", userID, "fee", fee.String())
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Data:    wallet.GetAllBalances(),
+		Details: gin.H{"fee": fee},
+	})
 }
 
 // Exchange godoc
 // @Summary      Exchange currency
-// @Description  Exchange money between currencies
+// @Description  Exchange money between currencies at the current rate, or at the rate and fee locked by a quote when quote_id is set. The fee is charged in the sold currency on top of the amount
 // @Tags         wallet
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.ExchangeRequest true "Exchange data"
+// @Param        Idempotency-Key header string false "Key to safely retry the request"
 // @Success      200 {object} object "Exchange successful"
 // @Failure      400 {object} models.Response "Invalid request or insufficient funds"
 // @Failure      401 {object} models.Response "Unauthorized"
+// @Failure      403 {object} models.Response "Wallet is frozen, closed or blocked for outgoing payments, or spending limit exceeded"
+// @Failure      404 {object} models.Response "Quote not found"
+// @Failure      409 {object} models.Response "Quote expired or already used, or request with this idempotency key is in progress"
+// @Failure      422 {object} models.Response "Idempotency key reused with a different request"
 // @Router       /exchange [post]
 func (h *WalletHandler) Exchange(c *gin.Context) {
+	h.exchange(c, uuid.Nil)
+}
+
+func (h *WalletHandler) exchange(c *gin.Context, walletID uuid.UUID) {
 	var req models.ExchangeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.L.Warnw("Exchange failed: invalid request", "error", err.Error())
@@ -192,9 +255,38 @@ func (h *WalletHandler) Exchange(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.service.ExchangeCurrency(c, userID, models.Currency(req.FromCurrency), models.Currency(req.ToCurrency), req.Amount)
+	if req.QuoteID != "" {
+		h.exchangeByQuote(c, userID, walletID, req.QuoteID)
+		return
+	}
+
+	if req.FromCurrency == "" || req.ToCurrency == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: "either quote_id or from_currency, to_currency and amount are required",
+		})
+		return
+	}
+
+	amount, err := models.NewMoney(req.Amount, req.FromCurrency)
+	if err != nil {
+		logger.L.Warnw("Exchange amount invalid", "userID", userID, "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, fee, err := h.service.ExchangeIn(c, userID, walletID, amount, req.ToCurrency)
 	if err != nil {
 		logger.L.Warnw("Exchange failed", "userID", userID, "from", req.FromCurrency, "to", req.ToCurrency, "error", err.Error())
+		if respondWalletNotFound(c, err) || respondWalletStatus(c, err) || respondLimitExceeded(c, err) {
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, models.Response{
 			Success: false,
 			Error:   messages.MsgExchangeFailed,
@@ -203,19 +295,138 @@ func (h *WalletHandler) Exchange(c *gin.Context) {
 		return
 	}
 
+	logger.L.Infow("Exchange successful", "userID", userID)
+	exchangeResponse(c, wallet, amount, fee, req.ToCurrency)
+}
+
+func (h *WalletHandler) exchangeByQuote(c *gin.Context, userID, walletID uuid.UUID, rawQuoteID string) {
+	quoteID, err := uuid.Parse(rawQuoteID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	wallet, quote, err := h.service.ExchangeByQuoteIn(c, userID, walletID, quoteID)
+	if err != nil {
+		logger.L.Warnw("Exchange by quote failed", "userID", userID, "quoteID", quoteID, "error", err.Error())
+		switch {
+		case errors.Is(err, models.ErrQuoteNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success: false,
+				Error:   messages.MsgQuoteNotFound,
+			})
+		case errors.Is(err, models.ErrQuoteExpired):
+			c.JSON(http.StatusConflict, models.Response{
+				Success: false,
+				Error:   messages.MsgQuoteExpired,
+			})
+		case errors.Is(err, models.ErrQuoteUsed):
+			c.JSON(http.StatusConflict, models.Response{
+				Success: false,
+				Error:   messages.MsgQuoteUsed,
+			})
+		case walletStatusMessage(err) != "":
+			respondWalletStatus(c, err)
+		case errors.Is(err, models.ErrLimitExceeded):
+			respondLimitExceeded(c, err)
+		default:
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgExchangeFailed,
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	logger.L.Infow("Exchange by quote successful", "userID", userID, "quoteID", quoteID)
+	exchangeResponse(c, wallet, quote.Amount, quote.Fee, quote.Converted.Currency)
+}
+
+func exchangeResponse(c *gin.Context, wallet *models.Wallet, amount, fee models.Money, to models.Currency) {
 	balances := wallet.GetAllBalances()
 
-	logger.L.Infow("Exchange successful", "userID", userID)
 	c.JSON(http.StatusOK, gin.H{
 		"message":          "Exchange successful",
-		"exchanged_amount": req.Amount,
+		"exchanged_amount": amount,
+		"fee":              fee,
 		"new_balance": gin.H{
-			string(req.FromCurrency): balances[req.FromCurrency],
-			string(req.ToCurrency):   balances[req.ToCurrency],
+			string(amount.Currency): balances[amount.Currency],
+			string(to):              balances[to],
 		},
 	})
 }
 
+// QuoteExchange godoc
+// @Summary      Get exchange quote
+// @Description  Lock the current exchange rate and fee for an amount; pass the returned quote_id to /exchange before it expires
+// @Tags         wallet
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ExchangeQuoteRequest true "Quote data"
+// @Success      200 {object} models.Response{data=models.ExchangeQuote} "Quote created"
+// @Failure      400 {object} models.Response "Invalid request or insufficient funds"
+// @Failure      401 {object} models.Response "Unauthorized"
+// @Router       /exchange/quote [post]
+func (h *WalletHandler) QuoteExchange(c *gin.Context) {
+	h.quoteExchange(c, uuid.Nil)
+}
+
+func (h *WalletHandler) quoteExchange(c *gin.Context, walletID uuid.UUID) {
+	var req models.ExchangeQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.L.Warnw("Quote request invalid", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return
+	}
+
+	amount, err := models.NewMoney(req.Amount, req.FromCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidAmount,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	quote, err := h.service.QuoteExchangeIn(c, userID, walletID, amount, req.ToCurrency)
+	if err != nil {
+		logger.L.Warnw("Quote failed", "userID", userID, "from", req.FromCurrency, "to", req.ToCurrency, "error", err.Error())
+		if respondWalletNotFound(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgQuoteFailed,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	logger.L.Infow("Quote created", "userID", userID, "quoteID", quote.ID)
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: quote})
+}
+
 // GetAllRates godoc
 // @Summary      Get exchange rates
 // @Description  Get all available currency exchange rates
@@ -244,6 +455,86 @@ func (h *WalletHandler) GetAllRates(c *gin.Context) {
 	})
 }
 
+// GetCurrencies godoc
+// @Summary      Get supported currencies
+// @Description  List enabled currencies with their precision and symbol
+// @Tags         wallet
+// @Produce      json
+// @Success      200 {object} models.Response{data=[]models.CurrencyInfo} "Currencies retrieved"
+// @Failure      500 {object} models.Response "Internal server error"
+// @Router       /currencies [get]
+func (h *WalletHandler) GetCurrencies(c *gin.Context) {
+	currencies, err := h.service.ListCurrencies(c)
+	if err != nil {
+		logger.L.Errorw("Get currencies failed", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Error:   messages.MsgInternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{Success: true, Data: currencies})
+}
+
+// walletStatusMessage returns the message for an error caused by the status
+// of a wallet or its owner's account, or "" for other errors.
+func walletStatusMessage(err error) string {
+	switch {
+	case errors.Is(err, models.ErrWalletFrozen):
+		return messages.MsgWalletFrozen
+	case errors.Is(err, models.ErrWalletDebitBlocked):
+		return messages.MsgWalletDebitBlocked
+	case errors.Is(err, models.ErrWalletClosed):
+		return messages.MsgWalletClosed
+	}
+	return ""
+}
+
+// respondWalletStatus answers 403 if err was caused by the status of a
+// wallet or its owner's account.
+func respondWalletStatus(c *gin.Context, err error) bool {
+	msg := walletStatusMessage(err)
+	if msg == "" {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, models.Response{
+		Success: false,
+		Error:   msg,
+	})
+	return true
+}
+
+// respondWalletNotFound answers 404 if the wallet named in the path does
+// not exist or belongs to another user.
+func respondWalletNotFound(c *gin.Context, err error) bool {
+	if !errors.Is(err, models.ErrWalletNotFound) {
+		return false
+	}
+
+	c.JSON(http.StatusNotFound, models.Response{
+		Success: false,
+		Error:   messages.MsgWalletNotFound,
+	})
+	return true
+}
+
+// respondLimitExceeded answers 403 if err was caused by a spending limit,
+// telling how much of the limit is left.
+func respondLimitExceeded(c *gin.Context, err error) bool {
+	if !errors.Is(err, models.ErrLimitExceeded) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, models.Response{
+		Success: false,
+		Error:   messages.MsgLimitExceeded,
+		Details: err.Error(),
+	})
+	return true
+}
+
 func getUserID(c *gin.Context) (uuid.UUID, bool) {
 	userIDStr, exists := c.Get("user_id")
 	if !exists {
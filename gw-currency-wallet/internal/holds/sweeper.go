@@ -0,0 +1,58 @@
+package holds
+
+import (
+	"context"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/storages"
+	"time"
+)
+
+// Sweeper periodically releases holds whose TTL has passed, returning the
+// reserved money to the available balance.
+type Sweeper struct {
+	store     storages.WalletStorage
+	interval  time.Duration
+	batchSize int
+}
+
+func NewSweeper(store storages.WalletStorage, interval time.Duration, batchSize int) *Sweeper {
+	return &Sweeper{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run expires holds until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	logger.L.Info("Hold sweeper started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for s.sweep(ctx) == s.batchSize {
+			// Полная пачка: вероятно, истекли ещё холды, продолжаем сразу.
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Hold sweeper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep expires one batch and returns the number of released holds.
+func (s *Sweeper) sweep(ctx context.Context) int {
+	expired, err := s.store.ExpireHolds(ctx, s.batchSize)
+	if err != nil && ctx.Err() == nil {
+		logger.L.Errorw("Failed to expire holds", "expired", expired, "error", err.Error())
+	}
+	if expired > 0 {
+		logger.L.Infow("Expired holds released", "count", expired)
+	}
+
+	return expired
+}
@@ -0,0 +1,345 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/pkg/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const keyExt = ".pem"
+
+var (
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrUnsupportedKey   = errors.New("unsupported private key type")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidKeyFormat = errors.New("invalid PEM private key")
+)
+
+// Key is a private signing key identified by its kid. The kid is the file
+// name without the .pem extension, the creation time is the file's mtime.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// KeySet holds the signing keys loaded from a directory of PEM files.
+// Every loaded key verifies tokens and is published in the JWKS; the newest
+// key published for at least publishAhead signs new ones. The replacement of
+// a key is generated publishAhead before the key turns rotateAfter old, so
+// that every instance sharing the directory and reloading it at least once
+// per publishAhead knows the new key before any instance signs with it.
+// Keys are deleted once older than retireAfter, which must cover the
+// lifetime of tokens signed just before the rotation.
+type KeySet struct {
+	dir          string
+	algorithm    string
+	rotateAfter  time.Duration
+	retireAfter  time.Duration
+	publishAhead time.Duration
+
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing *Key
+}
+
+// NewKeySet loads keys from dir, generating the first key with algorithm
+// (RS256 or EdDSA) when the directory is empty. The first key signs at once,
+// as there are no tokens yet that other instances would have to verify.
+func NewKeySet(dir, algorithm string, rotateAfter, retireAfter, publishAhead time.Duration) (*KeySet, error) {
+	if _, err := signingMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{
+		dir:          dir,
+		algorithm:    algorithm,
+		rotateAfter:  rotateAfter,
+		retireAfter:  retireAfter,
+		publishAhead: publishAhead,
+	}
+
+	if err := s.Rotate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (s *KeySet) SigningKey() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.signing
+}
+
+// Key returns the key with the given kid.
+func (s *KeySet) Key(kid string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Rotate reloads the directory, switches to the newest key once it has been
+// published long enough, generates the next key when the signing one is due
+// for rotation and removes keys past retirement.
+func (s *KeySet) Rotate() error {
+	keys, err := loadKeys(s.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	newest := newestKey(keys)
+	signing := newestKey(publishedKeys(keys, now.Add(-s.publishAhead)))
+
+	// A key newer than the signing one means another instance has already
+	// generated the next key.
+	if newest == nil || newest == signing && now.Sub(newest.CreatedAt) >= s.rotateAfter-s.publishAhead {
+		key, err := generateKey(s.dir, s.algorithm)
+		if err != nil {
+			return err
+		}
+		logger.L.Infow("Generated JWT signing key", "kid", key.ID, "alg", key.Method.Alg(),
+			"signsFrom", key.CreatedAt.Add(s.publishAhead))
+
+		keys[key.ID] = key
+		newest = key
+	}
+	if signing == nil {
+		signing = newest
+	}
+
+	for kid, key := range keys {
+		if key == signing || key == newest || now.Sub(key.CreatedAt) < s.retireAfter {
+			continue
+		}
+
+		// Ключ мог уже удалить другой экземпляр сервиса с тем же каталогом.
+		err := os.Remove(filepath.Join(s.dir, kid+keyExt))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		logger.L.Infow("Retired JWT signing key", "kid", kid)
+
+		delete(keys, kid)
+	}
+
+	s.mu.Lock()
+	s.keys, s.signing = keys, signing
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Run rotates keys every interval until ctx is cancelled. Keys put into the
+// directory by operators or other instances are picked up on the same schedule.
+func (s *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Rotate(); err != nil {
+				logger.L.Errorw("Failed to rotate JWT keys", "error", err.Error())
+			}
+		}
+	}
+}
+
+func loadKeys(dir string) (map[string]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), keyExt) {
+			continue
+		}
+
+		key, err := loadKey(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load key %s: %w", entry.Name(), err)
+		}
+		keys[key.ID] = key
+	}
+
+	return keys, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidKeyFormat
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, ErrInvalidKeyFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		ID:        strings.TrimSuffix(filepath.Base(path), keyExt),
+		CreatedAt: info.ModTime(),
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	return key, nil
+}
+
+// generateKey creates a key and writes it to dir. The file is renamed into
+// place only when fully written, so concurrent loads never see a partial key.
+func generateKey(dir, algorithm string) (*Key, error) {
+	method, err := signingMethod(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	switch method {
+	case jwt.SigningMethodRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	kid := now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+
+	tmp, err := os.CreateTemp(dir, ".key-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, kid+keyExt)); err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: kid, Method: method, Private: private, CreatedAt: now}, nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256, nil
+	case jwt.SigningMethodEdDSA.Alg():
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+}
+
+// publishedKeys returns the keys created no later than before.
+func publishedKeys(keys map[string]*Key, before time.Time) map[string]*Key {
+	published := make(map[string]*Key, len(keys))
+	for kid, key := range keys {
+		if !key.CreatedAt.After(before) {
+			published[kid] = key
+		}
+	}
+
+	return published
+}
+
+func newestKey(keys map[string]*Key) *Key {
+	sorted := make([]*Key, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	return sorted[0]
+}
@@ -0,0 +1,140 @@
+package jwks_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/jwks"
+	"gw-currency-wallet/internal/pkg/logger"
+)
+
+func TestKeySet_Rotation(t *testing.T) {
+	logger.Init()
+
+	dir := t.TempDir()
+	keys, err := jwks.NewKeySet(dir, "RS256", time.Hour, 2*time.Hour, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("create key set: %v", err)
+	}
+
+	first := keys.SigningKey()
+	if err := keys.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if keys.SigningKey().ID != first.ID || len(keys.JWKS().Keys) != 1 {
+		t.Fatalf("fresh key rotated: %s -> %s", first.ID, keys.SigningKey().ID)
+	}
+
+	// За publishAhead до rotateAfter создаётся следующий ключ: он уже
+	// опубликован и проверяет токены, но ещё не подписывает.
+	age(t, dir, first.ID, 55*time.Minute)
+	if err := keys.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if keys.SigningKey().ID != first.ID {
+		t.Fatalf("pending key signs before it is published long enough")
+	}
+
+	set := keys.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Kid != first.ID {
+		t.Fatalf("unexpected JWKS: %+v", set)
+	}
+	second := set.Keys[1].Kid
+	if _, err := keys.Key(second); err != nil {
+		t.Errorf("pending key must already verify: %v", err)
+	}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || jwk.Alg != "RS256" || jwk.N == "" || jwk.E != "AQAB" {
+			t.Errorf("unexpected JWK: %+v", jwk)
+		}
+	}
+
+	// Опубликованный publishAhead назад ключ начинает подписывать,
+	// прежний ещё проверяет выданные им токены.
+	age(t, dir, second, 10*time.Minute)
+	if err := keys.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if keys.SigningKey().ID != second {
+		t.Fatalf("published key does not sign: %s", keys.SigningKey().ID)
+	}
+	if _, err := keys.Key(first.ID); err != nil {
+		t.Errorf("rotated key must still verify: %v", err)
+	}
+	if len(keys.JWKS().Keys) != 2 {
+		t.Errorf("expected two published keys, got %d", len(keys.JWKS().Keys))
+	}
+
+	// После retireAfter ключ удаляется из набора и с диска.
+	age(t, dir, first.ID, 3*time.Hour)
+	if err := keys.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := keys.Key(first.ID); err == nil {
+		t.Error("retired key still loaded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, first.ID+".pem")); !os.IsNotExist(err) {
+		t.Errorf("retired key file not removed: %v", err)
+	}
+	if len(keys.JWKS().Keys) != 1 {
+		t.Errorf("expected one published key, got %d", len(keys.JWKS().Keys))
+	}
+}
+
+func TestKeySet_SharedDirectory(t *testing.T) {
+	logger.Init()
+
+	dir := t.TempDir()
+	a, err := jwks.NewKeySet(dir, "EdDSA", time.Hour, 2*time.Hour, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("create key set: %v", err)
+	}
+
+	b, err := jwks.NewKeySet(dir, "EdDSA", time.Hour, 2*time.Hour, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("create second key set: %v", err)
+	}
+
+	if b.SigningKey().ID != a.SigningKey().ID {
+		t.Fatalf("instances sharing a directory sign with different keys")
+	}
+
+	// Следующий ключ создаёт первый заметивший экземпляр, второй его
+	// подхватывает и не создаёт свой.
+	first := a.SigningKey().ID
+	age(t, dir, first, 55*time.Minute)
+	if err := a.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if err := b.Rotate(); err != nil {
+		t.Fatalf("rotate second: %v", err)
+	}
+	if len(a.JWKS().Keys) != 2 || len(b.JWKS().Keys) != 2 {
+		t.Fatalf("instances must share one pending key: %d and %d keys", len(a.JWKS().Keys), len(b.JWKS().Keys))
+	}
+	if a.SigningKey().ID != first || b.SigningKey().ID != first {
+		t.Fatalf("pending key signs before it is published long enough")
+	}
+
+	jwk := b.SigningKey().JWK()
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" || jwk.X == "" {
+		t.Errorf("unexpected JWK: %+v", jwk)
+	}
+}
+
+func TestNewKeySet_UnsupportedAlgorithm(t *testing.T) {
+	if _, err := jwks.NewKeySet(t.TempDir(), "HS256", time.Hour, 2*time.Hour, 0); err != jwks.ErrUnsupportedAlg {
+		t.Fatalf("expected ErrUnsupportedAlg, got %v", err)
+	}
+}
+
+func age(t *testing.T, dir, kid string, d time.Duration) {
+	t.Helper()
+
+	at := time.Now().Add(-d)
+	if err := os.Chtimes(filepath.Join(dir, kid+".pem"), at, at); err != nil {
+		t.Fatalf("age key: %v", err)
+	}
+}
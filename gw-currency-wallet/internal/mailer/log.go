@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"gw-currency-wallet/internal/pkg/logger"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer is a mailer for local development: it logs every email and,
+// if dir is set, also writes it to dir as an .eml file.
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+func NewLogMailer(dir, from string) (*LogMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LogMailer{from: from, dir: dir}, nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	data, err := render(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		logger.L.Infow("Email", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+		return nil
+	}
+
+	name := filepath.Join(m.dir, fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), filepath.Base(msg.To)))
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return err
+	}
+
+	logger.L.Infow("Email written", "to", msg.To, "subject", msg.Subject, "file", name)
+	return nil
+}
@@ -0,0 +1,69 @@
+package mailer_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gw-currency-wallet/internal/mailer"
+	"gw-currency-wallet/internal/pkg/logger"
+)
+
+func TestLogMailerWritesMessage(t *testing.T) {
+	logger.Init()
+	dir := t.TempDir()
+
+	m, err := mailer.NewLogMailer(dir, "Wallet <no-reply@wallet.local>")
+	if err != nil {
+		t.Fatalf("NewLogMailer: %v", err)
+	}
+
+	err = m.Send(context.Background(), mailer.Message{
+		To:      "user@mail.ru",
+		Subject: "Подтвердите почту",
+		Body:    "line one\nline two",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %d", len(files))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(data)
+
+	for _, want := range []string{
+		"From: Wallet <no-reply@wallet.local>\r\n",
+		"To: user@mail.ru\r\n",
+		"Subject: =?utf-8?q?",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message does not contain %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestSendRejectsHeaderInjection(t *testing.T) {
+	m, err := mailer.NewLogMailer(t.TempDir(), "no-reply@wallet.local")
+	if err != nil {
+		t.Fatalf("NewLogMailer: %v", err)
+	}
+
+	err = m.Send(context.Background(), mailer.Message{
+		To:      "user@mail.ru\r\nBcc: victim@mail.ru",
+		Subject: "Reset your password",
+	})
+	if !errors.Is(err, mailer.ErrInvalidHeader) {
+		t.Errorf("expected ErrInvalidHeader, got %v", err)
+	}
+}
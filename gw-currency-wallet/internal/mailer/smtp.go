@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer sends emails through an SMTP relay. Credentials are optional;
+// when set, PLAIN auth is used, which net/smtp allows only over TLS or to localhost.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{addr: addr, from: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := render(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp не принимает контекст, поэтому учитывается только уже отменённый запрос.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Заголовок From может содержать имя, а в конверт идёт только адрес.
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, sender.Address, []string{msg.To}, data)
+}
@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	key := &models.APIKey{AllowedIPs: []string{"203.0.113.10", "198.51.100.0/24", "2001:db8::/32"}}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"203.0.113.10", true},
+		{"203.0.113.11", false},
+		{"198.51.100.200", true},
+		{"198.51.101.1", false},
+		{"2001:db8::1", true},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		if got := key.AllowsIP(tt.ip); got != tt.want {
+			t.Errorf("AllowsIP(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+
+	if open := (&models.APIKey{}); !open.AllowsIP("192.0.2.1") {
+		t.Error("key without allowlist rejected an address")
+	}
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	key := &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeBalanceRead, models.ScopeWalletDeposit}}
+
+	if !key.HasScope(models.ScopeWalletDeposit) {
+		t.Error("granted scope rejected")
+	}
+	if key.HasScope(models.ScopeWalletWithdraw) {
+		t.Error("scope that was not granted accepted")
+	}
+	if models.APIKeyScope("wallet:*").Valid() {
+		t.Error("unknown scope reported as valid")
+	}
+}
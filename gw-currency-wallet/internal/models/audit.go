@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditWalletFreeze      AuditAction = "wallet_freeze"
+	AuditWalletUnfreeze    AuditAction = "wallet_unfreeze"
+	AuditBalanceAdjustment AuditAction = "balance_adjustment"
+	AuditRoleChange        AuditAction = "role_change"
+	AuditLoginUnlock       AuditAction = "login_unlock"
+	AuditLimitChange       AuditAction = "limit_change"
+	AuditWalletStatus      AuditAction = "wallet_status_change"
+	AuditAccountStatus     AuditAction = "account_status_change"
+	AuditFeeChange         AuditAction = "fee_change"
+)
+
+// AuditEntry records an action taken by staff on behalf of or against a user.
+// @Description Audit trail record of an administrative action
+type AuditEntry struct {
+	ID           uuid.UUID      `db:"id" json:"id"`
+	ActorID      uuid.UUID      `db:"actor_id" json:"actor_id"`
+	Action       AuditAction    `db:"action" json:"action"`
+	TargetUserID *uuid.UUID     `db:"target_user_id" json:"target_user_id,omitempty"`
+	WalletID     *uuid.UUID     `db:"wallet_id" json:"wallet_id,omitempty"`
+	Reason       string         `db:"reason" json:"reason"`
+	Details      map[string]any `db:"details" json:"details,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter selects audit entries, newest first.
+type AuditFilter struct {
+	TargetUserID *uuid.UUID
+	Limit        int
+}
+
+var ErrReasonRequired = errors.New("reason is required")
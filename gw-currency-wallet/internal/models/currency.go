@@ -0,0 +1,109 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMinorUnits is used for currencies that are not yet in the registry.
+const DefaultMinorUnits int32 = 2
+
+// CurrencyInfo is an entry of the currencies registry
+// @Description Currency known to the wallet service with its precision
+type CurrencyInfo struct {
+	Code       Currency  `db:"code" json:"code"`
+	MinorUnits int32     `db:"minor_units" json:"minor_units"`
+	Symbol     string    `db:"symbol" json:"symbol"`
+	Enabled    bool      `db:"enabled" json:"enabled"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// CurrencyRegistry keeps currency metadata loaded from the currencies table.
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[Currency]CurrencyInfo
+}
+
+func NewCurrencyRegistry(currencies ...CurrencyInfo) *CurrencyRegistry {
+	r := &CurrencyRegistry{}
+	r.Replace(currencies)
+	return r
+}
+
+// Replace atomically swaps the registry contents.
+func (r *CurrencyRegistry) Replace(currencies []CurrencyInfo) {
+	m := make(map[Currency]CurrencyInfo, len(currencies))
+	for _, c := range currencies {
+		m[c.Code] = c
+	}
+
+	r.mu.Lock()
+	r.currencies = m
+	r.mu.Unlock()
+}
+
+// Lookup returns metadata of an enabled currency.
+func (r *CurrencyRegistry) Lookup(code Currency) (CurrencyInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.currencies[code]
+	if !ok || !info.Enabled {
+		return CurrencyInfo{}, false
+	}
+
+	return info, true
+}
+
+// List returns enabled currencies ordered by code.
+func (r *CurrencyRegistry) List() []CurrencyInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]CurrencyInfo, 0, len(r.currencies))
+	for _, info := range r.currencies {
+		if info.Enabled {
+			list = append(list, info)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (r *CurrencyRegistry) minorUnits(code Currency) int32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if info, ok := r.currencies[code]; ok {
+		return info.MinorUnits
+	}
+
+	return DefaultMinorUnits
+}
+
+// Currencies is the registry of the running service, used by the package-level
+// money functions. It starts with the built-in currencies and is reloaded from
+// the database at startup, on exchanger sync and periodically. Code that needs
+// other currencies, such as tests, builds its own registry with
+// NewCurrencyRegistry instead of replacing this one.
+var Currencies = NewCurrencyRegistry(
+	CurrencyInfo{Code: USD, MinorUnits: 2, Symbol: "$", Enabled: true},
+	CurrencyInfo{Code: EUR, MinorUnits: 2, Symbol: "€", Enabled: true},
+	CurrencyInfo{Code: RUB, MinorUnits: 2, Symbol: "₽", Enabled: true},
+)
+
+// Exponent returns the number of digits after the decimal point for the currency.
+func (c Currency) Exponent() int32 {
+	return Currencies.minorUnits(c)
+}
+
+// Validate checks that the currency is registered and enabled.
+func (c Currency) Validate() error {
+	if _, ok := Currencies.Lookup(c); !ok {
+		return ErrUnsupportedCurrency
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // RegisterRequest represents user registration data
 // @Description User registration request
 type RegisterRequest struct {
@@ -15,6 +17,53 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshRequest represents token refresh request
+// @Description Refresh token issued on login or previous refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LoginTwoFactorRequest represents the second login step
+// @Description Challenge token returned by login with a TOTP or recovery code
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+// TOTPCodeRequest represents a code from the authenticator app
+// @Description TOTP code, disabling also accepts a recovery code
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// CreateAPIKeyRequest represents API key creation request
+// @Description Named API key with scopes, an optional expiry and an optional IP allowlist
+type CreateAPIKeyRequest struct {
+	Name       string        `json:"name" binding:"required,max=100" example:"Accounting export"`
+	Scopes     []APIKeyScope `json:"scopes" binding:"required,min=1" swaggertype:"array,string" example:"balance:read"`
+	AllowedIPs []string      `json:"allowed_ips" example:"203.0.113.10,198.51.100.0/24"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+}
+
+// VerifyEmailRequest represents email verification request
+// @Description Token from the verification email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// PasswordResetRequest represents request to send a password reset email
+// @Description Email of the account to recover
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents password reset confirmation
+// @Description Token from the password reset email and the new password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
 // Response represents standard API response
 // @Description Standard API response format
 type Response struct {
@@ -28,13 +77,143 @@ type Response struct {
 // @Description Wallet deposit/withdraw request
 type WalletOperationReq struct {
 	Currency string  `json:"currency" binding:"required"`
-	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	Amount   Decimal `json:"amount" binding:"required" swaggertype:"string" example:"100.50"`
 }
 
 // ExchangeRequest represents currency exchange request
-// @Description Currency exchange operation request
+// @Description Currency exchange operation request, set either quote_id or the currencies and amount
 type ExchangeRequest struct {
+	QuoteID      string   `json:"quote_id"`
+	FromCurrency Currency `json:"from_currency"`
+	ToCurrency   Currency `json:"to_currency"`
+	Amount       Decimal  `json:"amount" swaggertype:"string" example:"100.50"`
+}
+
+// ExchangeQuoteRequest represents exchange quote request
+// @Description Request to lock an exchange rate for a short time
+type ExchangeQuoteRequest struct {
 	FromCurrency Currency `json:"from_currency" binding:"required"`
 	ToCurrency   Currency `json:"to_currency" binding:"required"`
-	Amount       float64  `json:"amount" binding:"required,gt=0"`
+	Amount       Decimal  `json:"amount" binding:"required" swaggertype:"string" example:"100.50"`
+}
+
+// TransferRequest represents peer-to-peer transfer request
+// @Description Transfer to another user, set either to_username or to_user_id
+type TransferRequest struct {
+	ToUsername string  `json:"to_username"`
+	ToUserID   string  `json:"to_user_id"`
+	Currency   string  `json:"currency" binding:"required"`
+	Amount     Decimal `json:"amount" binding:"required" swaggertype:"string" example:"100.50"`
+}
+
+// WalletNameRequest represents wallet creation or rename request
+// @Description Name of a wallet, unique among the user's open wallets
+type WalletNameRequest struct {
+	Name string `json:"name" binding:"required" example:"savings"`
+}
+
+// InternalTransferRequest represents transfer between the user's own wallets
+// @Description Free transfer between two wallets of the caller
+type InternalTransferRequest struct {
+	FromWalletID string  `json:"from_wallet_id" binding:"required"`
+	ToWalletID   string  `json:"to_wallet_id" binding:"required"`
+	Currency     string  `json:"currency" binding:"required"`
+	Amount       Decimal `json:"amount" binding:"required" swaggertype:"string" example:"100.50"`
+}
+
+// SetFeeRuleRequest represents fee rule creation or change
+// @Description Fee rule for withdraw or exchange in from_currency. Amounts are in from_currency; max and to_currency are optional
+type SetFeeRuleRequest struct {
+	Operation    FeeOperation `json:"operation" binding:"required" example:"exchange"`
+	FromCurrency string       `json:"from_currency" binding:"required" example:"USD"`
+	ToCurrency   string       `json:"to_currency" example:"EUR"`
+	Percent      Decimal      `json:"percent" swaggertype:"string" example:"0.5"`
+	Fixed        Decimal      `json:"fixed" swaggertype:"string" example:"0.30"`
+	Min          Decimal      `json:"min" swaggertype:"string" example:"1.00"`
+	Max          *Decimal     `json:"max" swaggertype:"string" example:"50.00"`
+	MinVolume    Decimal      `json:"min_volume" swaggertype:"string" example:"0"`
+	Reason       string       `json:"reason" binding:"required" example:"New pricing"`
+}
+
+// CreateHoldRequest represents hold creation request
+// @Description Request to reserve funds, ttl_seconds defaults to the server setting
+type CreateHoldRequest struct {
+	Currency   string  `json:"currency" binding:"required"`
+	Amount     Decimal `json:"amount" binding:"required" swaggertype:"string" example:"100.50"`
+	TTLSeconds int     `json:"ttl_seconds" example:"900"`
+}
+
+// CaptureHoldRequest represents hold capture request
+// @Description Capture request, the whole remaining hold is captured when amount is omitted
+type CaptureHoldRequest struct {
+	Amount *Decimal `json:"amount" swaggertype:"string" example:"50.25"`
+}
+
+// AdminActionRequest represents an administrative action that needs a reason
+// @Description Reason recorded in the audit trail
+type AdminActionRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Suspicious activity reported by the customer"`
+}
+
+// AdjustBalanceRequest represents manual balance adjustment
+// @Description Manual balance correction, a negative amount debits the wallet
+type AdjustBalanceRequest struct {
+	Currency string  `json:"currency" binding:"required"`
+	Amount   Decimal `json:"amount" binding:"required" swaggertype:"string" example:"-10.00"`
+	Reason   string  `json:"reason" binding:"required" example:"Refund of a duplicated withdrawal"`
+}
+
+// SetRoleRequest represents role change request
+// @Description New role of the user: user, support or admin
+type SetRoleRequest struct {
+	Role   Role   `json:"role" binding:"required" example:"support"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SetStatusRequest represents account or wallet status change
+// @Description New status: active, debit_blocked, frozen or closed. Closed is final
+type SetStatusRequest struct {
+	Status AccountStatus `json:"status" binding:"required" example:"debit_blocked"`
+	Reason string        `json:"reason" binding:"required" example:"Account holder reported deceased"`
+}
+
+// SetLimitRequest represents spending limit change
+// @Description Spending limit for an operation (withdraw or exchange) and period (daily or monthly); a null amount removes it
+type SetLimitRequest struct {
+	Operation LimitOperation `json:"operation" binding:"required" example:"withdraw"`
+	Currency  string         `json:"currency" binding:"required" example:"USD"`
+	Period    LimitPeriod    `json:"period" binding:"required" example:"daily"`
+	Amount    *Decimal       `json:"amount" swaggertype:"string" example:"5000.00"`
+	Reason    string         `json:"reason" binding:"required" example:"Raised after KYC review"`
+}
+
+// CreateScheduleRequest represents standing order creation request
+// @Description Standing order. A transfer needs to_wallet_id, an exchange needs to_currency; wallet_id defaults to the main wallet. cron has five fields in UTC
+type CreateScheduleRequest struct {
+	Kind       ScheduleKind `json:"kind" binding:"required" example:"transfer"`
+	WalletID   string       `json:"wallet_id"`
+	ToWalletID string       `json:"to_wallet_id"`
+	Currency   string       `json:"currency" binding:"required" example:"USD"`
+	Amount     Decimal      `json:"amount" binding:"required" swaggertype:"string" example:"100.00"`
+	ToCurrency Currency     `json:"to_currency" example:"EUR"`
+	Cron       string       `json:"cron" binding:"required" example:"0 9 1 * *"`
+}
+
+// UpdateScheduleRequest represents standing order change request
+// @Description Fields to change; resuming a paused order resets its failure count
+type UpdateScheduleRequest struct {
+	Amount *Decimal        `json:"amount" swaggertype:"string" example:"150.00"`
+	Cron   *string         `json:"cron" example:"0 9 * * FRI"`
+	Status *ScheduleStatus `json:"status" example:"paused"`
+}
+
+// PlaceOrderRequest represents limit order request
+// @Description Convert amount to to_currency once the rate reaches target_rate. wallet_id defaults to the main wallet, ttl_seconds to the server setting
+type PlaceOrderRequest struct {
+	WalletID   string   `json:"wallet_id"`
+	Currency   string   `json:"currency" binding:"required" example:"USD"`
+	Amount     Decimal  `json:"amount" binding:"required" swaggertype:"string" example:"1000.00"`
+	ToCurrency Currency `json:"to_currency" binding:"required" example:"EUR"`
+	TargetRate Decimal  `json:"target_rate" binding:"required" swaggertype:"string" example:"0.95"`
+	TTLSeconds int      `json:"ttl_seconds" example:"86400"`
 }
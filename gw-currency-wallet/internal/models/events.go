@@ -12,16 +12,34 @@ const (
 	Deposit  EventType = "deposit"
 	Withdraw EventType = "withdraw"
 	Exchange EventType = "exchange"
+	Transfer EventType = "transfer"
+
+	// LoginLockoutEvent is a security event: a username or an IP was locked
+	// out after repeated failed logins.
+	LoginLockoutEvent EventType = "login_lockout"
+
+	// Limit order events report every change of an order status.
+	OrderPlacedEvent    EventType = "order_placed"
+	OrderFilledEvent    EventType = "order_filled"
+	OrderCancelledEvent EventType = "order_cancelled"
+	OrderExpiredEvent   EventType = "order_expired"
+	OrderFailedEvent    EventType = "order_failed"
 )
 
-const EventAmount float64 = 30000
+// EventAmount is the threshold in major units from which operations are reported to Kafka.
+var EventAmount = NewDecimal(30000, 0)
+
+// IsLargeAmount reports whether the operation amount reaches EventAmount.
+func IsLargeAmount(amount Money) bool {
+	return amount.Decimal().Cmp(EventAmount) >= 0
+}
 
 type EventMessage struct {
 	EventID   uuid.UUID `json:"event_id" bson:"event_id"`
 	Event     EventType `json:"event" bson:"event"`
 	UserID    uuid.UUID `json:"user_id" bson:"user_id"`
 	WalletID  uuid.UUID `json:"wallet_id" bson:"wallet_id"`
-	Amount    float64   `json:"amount" bson:"amount"`
+	Amount    Money     `json:"amount" bson:"amount"`
 	Currency  string    `json:"currency" bson:"currency"`
 	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
 	Details   string    `json:"details,omitempty" bson:"details,omitempty"`
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeeOperation is an operation type fees are charged for. Values match the
+// transaction types the monthly volume for tiers is summed over.
+type FeeOperation string
+
+const (
+	FeeWithdraw FeeOperation = FeeOperation(TransactionWithdraw)
+	FeeExchange FeeOperation = FeeOperation(TransactionExchange)
+)
+
+func (o FeeOperation) Valid() bool {
+	return o == FeeWithdraw || o == FeeExchange
+}
+
+// FeeRule prices an operation in FromCurrency. The fee is Percent of the
+// amount plus Fixed, raised to Min and capped at Max, and is charged in
+// FromCurrency on top of the amount.
+//
+// Several rules for the same operation and currencies form volume tiers: a
+// rule applies once the user's volume of the operation in FromCurrency for
+// the current calendar month reaches MinVolume.
+// @Description Fee rule; without to_currency an exchange rule matches any target currency
+type FeeRule struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	Operation    FeeOperation `json:"operation" db:"operation" example:"exchange"`
+	FromCurrency Currency     `json:"from_currency" db:"from_currency" example:"USD"`
+	// ToCurrency narrows an exchange rule to one target currency.
+	ToCurrency Currency  `json:"to_currency,omitempty" db:"to_currency" example:"EUR"`
+	Percent    Decimal   `json:"percent" db:"percent" swaggertype:"string" example:"0.5"`
+	Fixed      Money     `json:"fixed" db:"fixed"`
+	Min        Money     `json:"min" db:"min_fee"`
+	Max        *Money    `json:"max,omitempty" db:"max_fee"`
+	MinVolume  Money     `json:"min_volume" db:"min_volume"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks that the rule is well-formed.
+func (r *FeeRule) Validate() error {
+	if !r.Operation.Valid() || r.FromCurrency == "" {
+		return ErrInvalidFeeRule
+	}
+	if r.Operation == FeeWithdraw && r.ToCurrency != "" || r.ToCurrency == r.FromCurrency {
+		return ErrInvalidFeeRule
+	}
+	// The column keeps four decimal places of a percent.
+	if r.Percent.scale > 4 || r.Percent.Sign() < 0 || r.Percent.Cmp(NewDecimal(100, 0)) >= 0 {
+		return ErrInvalidFeeRule
+	}
+	if r.Fixed.Amount < 0 || r.Min.Amount < 0 || r.MinVolume.Amount < 0 {
+		return ErrInvalidFeeRule
+	}
+	if r.Max != nil && r.Max.Amount < r.Min.Amount {
+		return ErrInvalidFeeRule
+	}
+
+	return nil
+}
+
+// Compute returns the fee for amount in its currency.
+func (r *FeeRule) Compute(amount Money) (Money, error) {
+	fee := Money{Currency: amount.Currency}
+
+	if r.Percent.IsPositive() {
+		rate := Decimal{value: r.Percent.value, scale: r.Percent.scale + 2}
+		percent, err := amount.Convert(amount.Currency, rate, RoundHalfUp)
+		if err != nil {
+			return Money{}, err
+		}
+		fee = percent
+	}
+
+	fee, err := fee.Add(Money{Currency: amount.Currency, Amount: r.Fixed.Amount})
+	if err != nil {
+		return Money{}, err
+	}
+
+	fee.Amount = max(fee.Amount, r.Min.Amount)
+	if r.Max != nil {
+		fee.Amount = min(fee.Amount, r.Max.Amount)
+	}
+
+	return fee, nil
+}
+
+// MatchFeeRule picks the rule that prices an operation into to for a user
+// whose monthly volume is volume. rules must share the operation and
+// FromCurrency. Rules for exactly to take precedence over rules for any
+// currency; among them the highest tier reached applies. Returns nil if no
+// rule applies.
+func MatchFeeRule(rules []*FeeRule, to Currency, volume int64) *FeeRule {
+	var best *FeeRule
+	for _, rule := range rules {
+		if rule.ToCurrency != "" && rule.ToCurrency != to || rule.MinVolume.Amount > volume {
+			continue
+		}
+		if best == nil {
+			best = rule
+			continue
+		}
+
+		exact, bestExact := rule.ToCurrency != "", best.ToCurrency != ""
+		if exact != bestExact {
+			if exact {
+				best = rule
+			}
+			continue
+		}
+		if rule.MinVolume.Amount > best.MinVolume.Amount {
+			best = rule
+		}
+	}
+
+	return best
+}
+
+// FeeCharge is a fee taken from a wallet. It is collected on the fee account
+// and credited to the house wallet when fees are settled. A zero amount
+// means the operation is free.
+type FeeCharge struct {
+	Amount Money
+}
+
+var (
+	ErrInvalidFeeRule  = errors.New("invalid fee rule")
+	ErrFeeRuleNotFound = errors.New("fee rule not found")
+)
@@ -0,0 +1,82 @@
+package models_test
+
+import (
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func usd(amount int64) models.Money {
+	return models.Money{Currency: models.USD, Amount: amount}
+}
+
+func TestFeeRuleCompute(t *testing.T) {
+	capped := usd(500)
+	cases := []struct {
+		name   string
+		rule   models.FeeRule
+		amount int64
+		want   int64
+	}{
+		{"percent", models.FeeRule{Percent: models.NewDecimal(5, 1)}, 10000, 50},
+		{"percent rounds half up", models.FeeRule{Percent: models.NewDecimal(5, 1)}, 10100, 51},
+		{"percent plus fixed", models.FeeRule{Percent: models.NewDecimal(1, 0), Fixed: usd(30)}, 10000, 130},
+		{"raised to min", models.FeeRule{Percent: models.NewDecimal(1, 0), Min: usd(100)}, 1000, 100},
+		{"capped at max", models.FeeRule{Percent: models.NewDecimal(2, 0), Max: &capped}, 100000, 500},
+		{"free", models.FeeRule{}, 10000, 0},
+	}
+
+	for _, tc := range cases {
+		fee, err := tc.rule.Compute(usd(tc.amount))
+		if err != nil {
+			t.Errorf("%s: Compute() error: %v", tc.name, err)
+			continue
+		}
+		if fee.Currency != models.USD || fee.Amount != tc.want {
+			t.Errorf("%s: Compute(%d) = %d %s, want %d USD", tc.name, tc.amount, fee.Amount, fee.Currency, tc.want)
+		}
+	}
+}
+
+func TestFeeRuleValidate(t *testing.T) {
+	valid := models.FeeRule{Operation: models.FeeExchange, FromCurrency: models.USD, ToCurrency: models.EUR, Percent: models.NewDecimal(5, 1)}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid rule rejected: %v", err)
+	}
+
+	min := usd(200)
+	max := usd(100)
+	invalid := []models.FeeRule{
+		{Operation: "deposit", FromCurrency: models.USD},
+		{Operation: models.FeeWithdraw, FromCurrency: models.USD, ToCurrency: models.EUR},
+		{Operation: models.FeeExchange, FromCurrency: models.USD, ToCurrency: models.USD},
+		{Operation: models.FeeExchange, FromCurrency: models.USD, Percent: models.NewDecimal(100, 0)},
+		{Operation: models.FeeWithdraw, FromCurrency: models.USD, Fixed: usd(-1)},
+		{Operation: models.FeeWithdraw, FromCurrency: models.USD, Min: min, Max: &max},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("invalid rule accepted: %+v", rule)
+		}
+	}
+}
+
+func TestMatchFeeRule(t *testing.T) {
+	anyBase := &models.FeeRule{Percent: models.NewDecimal(1, 0)}
+	anyTier := &models.FeeRule{Percent: models.NewDecimal(5, 1), MinVolume: usd(100000)}
+	eurBase := &models.FeeRule{ToCurrency: models.EUR, Percent: models.NewDecimal(2, 0)}
+	rules := []*models.FeeRule{anyTier, eurBase, anyBase}
+
+	if got := models.MatchFeeRule(rules, models.RUB, 0); got != anyBase {
+		t.Errorf("base tier: got %+v", got)
+	}
+	if got := models.MatchFeeRule(rules, models.RUB, 100000); got != anyTier {
+		t.Errorf("reached tier: got %+v", got)
+	}
+	if got := models.MatchFeeRule(rules, models.EUR, 100000); got != eurBase {
+		t.Errorf("exact currency must win over a higher tier for any currency: got %+v", got)
+	}
+	if got := models.MatchFeeRule(nil, models.EUR, 0); got != nil {
+		t.Errorf("no rules: got %+v", got)
+	}
+}
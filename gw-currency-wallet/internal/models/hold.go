@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold reserves part of a wallet balance. Held money stays in the total
+// balance but is not available for other operations until the hold is
+// captured, voided or expires. A hold with OrderID reserves a limit order
+// and is released only by the order, never through the hold API.
+// @Description Reservation of wallet funds
+type Hold struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"-"`
+	WalletID  uuid.UUID  `db:"wallet_id" json:"wallet_id"`
+	OrderID   *uuid.UUID `db:"order_id" json:"order_id,omitempty"`
+	Amount    Money      `db:"amount" json:"amount"`
+	Captured  Money      `db:"captured" json:"captured"`
+	Status    HoldStatus `db:"status" json:"status"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Remaining returns the part of the hold that is still reserved.
+func (h *Hold) Remaining() Money {
+	return Money{Currency: h.Amount.Currency, Amount: h.Amount.Amount - h.Captured.Amount}
+}
+
+// BalanceView is the balance of one currency split into available and held parts.
+// @Description Wallet balance in one currency
+type BalanceView struct {
+	Available Decimal `json:"available" swaggertype:"string" example:"80.00"`
+	Held      Decimal `json:"held" swaggertype:"string" example:"20.00"`
+	Total     Decimal `json:"total" swaggertype:"string" example:"100.00"`
+}
+
+var (
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is not active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the remaining hold")
+	ErrInvalidHoldTTL     = errors.New("invalid hold ttl")
+	ErrHoldOwnedByOrder   = errors.New("hold reserves a limit order")
+)
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the request header that makes a mutating request idempotent.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyRecord stores the first result of a request made with an idempotency key.
+type IdempotencyRecord struct {
+	UserID       uuid.UUID  `db:"user_id"`
+	Key          string     `db:"key"`
+	RequestHash  string     `db:"request_hash"`
+	StatusCode   int        `db:"status_code"`
+	ResponseBody []byte     `db:"response_body"`
+	CreatedAt    time.Time  `db:"created_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+	// Abandoned is set for a record that was reserved longer than the lock
+	// timeout ago and never completed. The first request may have moved money
+	// before it died, so the key is not run again.
+	Abandoned bool `db:"-"`
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.CompletedAt != nil
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// AccountKind is the type of a ledger account.
+type AccountKind string
+
+const (
+	// AccountWallet holds money of a user wallet in one currency.
+	AccountWallet AccountKind = "wallet"
+	// AccountCashIn is the source of deposited money.
+	AccountCashIn AccountKind = "cash_in"
+	// AccountCashOut receives withdrawn and captured money.
+	AccountCashOut AccountKind = "cash_out"
+	// AccountFX is the counterparty of both legs of a currency exchange.
+	AccountFX AccountKind = "fx"
+	// AccountAdjustment is the counterparty of manual balance corrections.
+	AccountAdjustment AccountKind = "adjustment"
+	// AccountFees collects charged fees until they are settled to the house wallet.
+	AccountFees AccountKind = "fees"
+)
+
+// AccountRef identifies a ledger account. Wallet accounts are keyed by wallet
+// and currency, system accounts by kind and currency.
+type AccountRef struct {
+	Kind     AccountKind
+	WalletID uuid.UUID
+	Currency Currency
+}
+
+func WalletAccount(walletID uuid.UUID, currency Currency) AccountRef {
+	return AccountRef{Kind: AccountWallet, WalletID: walletID, Currency: currency}
+}
+
+func SystemAccount(kind AccountKind, currency Currency) AccountRef {
+	return AccountRef{Kind: kind, Currency: currency}
+}
+
+// JournalLine changes one account. A positive amount increases the account balance.
+type JournalLine struct {
+	Account AccountRef
+	Amount  int64
+}
+
+// JournalEntry is a set of lines that together sum to zero in every currency.
+type JournalEntry struct {
+	ID            uuid.UUID
+	Type          TransactionType
+	TransactionID uuid.UUID
+	Lines         []JournalLine
+}
+
+// Validate checks that the entry has at least two non-zero lines and balances per currency.
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	sums := make(map[Currency]int64)
+	for _, line := range e.Lines {
+		if line.Amount == 0 {
+			return ErrInvalidAmount
+		}
+		sums[line.Account.Currency] += line.Amount
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+
+	return nil
+}
+
+// BalanceDrift is a wallet balance whose projection differs from the ledger.
+type BalanceDrift struct {
+	WalletID  uuid.UUID
+	Currency  Currency
+	Projected int64
+	Ledger    int64
+}
+
+// UnbalancedEntry is a journal entry whose lines do not sum to zero.
+type UnbalancedEntry struct {
+	EntryID  uuid.UUID
+	Currency Currency
+	Sum      int64
+}
+
+// LedgerReport is the result of recomputing balances from the journal.
+type LedgerReport struct {
+	Unbalanced []UnbalancedEntry
+	Drift      []BalanceDrift
+}
+
+// OK reports whether the journal is consistent with the wallet balances.
+func (r *LedgerReport) OK() bool {
+	return len(r.Unbalanced) == 0 && len(r.Drift) == 0
+}
+
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
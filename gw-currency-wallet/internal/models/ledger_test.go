@@ -0,0 +1,67 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestJournalEntryValidate(t *testing.T) {
+	wallet := uuid.New()
+
+	tests := []struct {
+		name    string
+		lines   []models.JournalLine
+		wantErr error
+	}{
+		{
+			name: "deposit",
+			lines: []models.JournalLine{
+				{Account: models.WalletAccount(wallet, models.USD), Amount: 100},
+				{Account: models.SystemAccount(models.AccountCashIn, models.USD), Amount: -100},
+			},
+		},
+		{
+			name: "exchange balances per currency",
+			lines: []models.JournalLine{
+				{Account: models.WalletAccount(wallet, models.USD), Amount: -100},
+				{Account: models.SystemAccount(models.AccountFX, models.USD), Amount: 100},
+				{Account: models.SystemAccount(models.AccountFX, models.EUR), Amount: -90},
+				{Account: models.WalletAccount(wallet, models.EUR), Amount: 90},
+			},
+		},
+		{
+			name: "sum is zero only across currencies",
+			lines: []models.JournalLine{
+				{Account: models.WalletAccount(wallet, models.USD), Amount: -100},
+				{Account: models.WalletAccount(wallet, models.EUR), Amount: 100},
+			},
+			wantErr: models.ErrUnbalancedEntry,
+		},
+		{
+			name: "single line",
+			lines: []models.JournalLine{
+				{Account: models.WalletAccount(wallet, models.USD), Amount: 100},
+			},
+			wantErr: models.ErrUnbalancedEntry,
+		},
+		{
+			name: "zero line",
+			lines: []models.JournalLine{
+				{Account: models.WalletAccount(wallet, models.USD), Amount: 0},
+				{Account: models.SystemAccount(models.AccountCashIn, models.USD), Amount: 0},
+			},
+			wantErr: models.ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		entry := models.JournalEntry{Type: models.TransactionDeposit, Lines: tt.lines}
+		if err := entry.Validate(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: Validate() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
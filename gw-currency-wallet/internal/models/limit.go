@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LimitOperation is an operation type spending limits apply to. Values match
+// the transaction types the spent amounts are summed over; withdraw limits
+// also count hold captures and transfers to other users, which move money
+// out of the wallet as well.
+type LimitOperation string
+
+const (
+	LimitWithdraw LimitOperation = LimitOperation(TransactionWithdraw)
+	LimitExchange LimitOperation = LimitOperation(TransactionExchange)
+)
+
+func (o LimitOperation) Valid() bool {
+	return o == LimitWithdraw || o == LimitExchange
+}
+
+// LimitPeriod is a calendar period a limit is counted over, in server time.
+type LimitPeriod string
+
+const (
+	LimitDaily   LimitPeriod = "daily"
+	LimitMonthly LimitPeriod = "monthly"
+)
+
+func (p LimitPeriod) Valid() bool {
+	return p == LimitDaily || p == LimitMonthly
+}
+
+// SpendingLimit caps the amount of a currency a user may spend with an
+// operation per period. A limit without UserID is the system default; a
+// user's own limit overrides it. Exchanges count the sold currency.
+// @Description Spending limit; without user_id it is the system default
+type SpendingLimit struct {
+	UserID    *uuid.UUID     `json:"user_id,omitempty" db:"user_id"`
+	Operation LimitOperation `json:"operation" db:"operation" example:"withdraw"`
+	Period    LimitPeriod    `json:"period" db:"period" example:"daily"`
+	Amount    Money          `json:"limit" db:"amount"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// LimitUsage is a limit in effect for a user with the amount already spent
+// @Description Spending limit in effect and how much of it is left in the current period
+type LimitUsage struct {
+	Operation LimitOperation `json:"operation" example:"withdraw"`
+	Currency  Currency       `json:"currency" example:"USD"`
+	Period    LimitPeriod    `json:"period" example:"daily"`
+	Limit     Decimal        `json:"limit" swaggertype:"string" example:"10000.00"`
+	Used      Decimal        `json:"used" swaggertype:"string" example:"1500.00"`
+	Remaining Decimal        `json:"remaining" swaggertype:"string" example:"8500.00"`
+	Override  bool           `json:"override"`
+	ResetsAt  time.Time      `json:"resets_at"`
+}
+
+// LimitExceededError is returned when an operation would exceed a spending limit.
+type LimitExceededError struct {
+	Operation LimitOperation
+	Period    LimitPeriod
+	Remaining Money
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s %s limit exceeded, %s %s left", e.Period, e.Operation, e.Remaining, e.Remaining.Currency)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+var (
+	ErrLimitExceeded = errors.New("spending limit exceeded")
+	ErrInvalidLimit  = errors.New("invalid limit operation or period")
+	ErrLimitNotFound = errors.New("spending limit not found")
+)
@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestLimitExceededError(t *testing.T) {
+	err := error(&models.LimitExceededError{
+		Operation: models.LimitWithdraw,
+		Period:    models.LimitDaily,
+		Remaining: models.Money{Currency: models.USD, Amount: 1050},
+	})
+
+	if !errors.Is(err, models.ErrLimitExceeded) {
+		t.Error("LimitExceededError does not match ErrLimitExceeded")
+	}
+	if got, want := err.Error(), "daily withdraw limit exceeded, 10.50 USD left"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestLimitOperationValid(t *testing.T) {
+	if !models.LimitWithdraw.Valid() || !models.LimitExchange.Valid() {
+		t.Error("known operation reported as invalid")
+	}
+	if models.LimitOperation("deposit").Valid() {
+		t.Error("deposits must not be limited")
+	}
+	if models.LimitPeriod("weekly").Valid() {
+		t.Error("unknown period reported as valid")
+	}
+}
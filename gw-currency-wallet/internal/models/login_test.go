@@ -0,0 +1,44 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestLockoutRuleDuration(t *testing.T) {
+	rule := models.LockoutRule{BaseLockout: time.Minute, MaxLockout: 10 * time.Minute}
+
+	tests := []struct {
+		level int
+		want  time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{60, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := rule.Duration(tt.level); got != tt.want {
+			t.Errorf("Duration(%d) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLoginLockedError(t *testing.T) {
+	var err error = &models.LoginLockedError{RetryAfter: 90 * time.Second}
+
+	if !errors.Is(err, models.ErrLoginLocked) {
+		t.Error("LoginLockedError does not match ErrLoginLocked")
+	}
+
+	var locked *models.LoginLockedError
+	if !errors.As(err, &locked) || locked.RetryAfter != 90*time.Second {
+		t.Errorf("errors.As lost RetryAfter: %+v", locked)
+	}
+}
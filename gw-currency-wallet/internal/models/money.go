@@ -0,0 +1,349 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode задаёт округление результата, в котором знаков больше,
+// чем допускает валюта.
+type RoundingMode int
+
+const (
+	// RoundDown отбрасывает лишние знаки, округляя к нулю.
+	RoundDown RoundingMode = iota
+	// RoundUp округляет от нуля.
+	RoundUp
+	// RoundHalfUp округляет к ближайшему, половину — от нуля.
+	RoundHalfUp
+	// RoundHalfEven округляет к ближайшему, половину — к чётному.
+	RoundHalfEven
+)
+
+// Decimal — точное десятичное число value * 10^-scale.
+// @Description Decimal number encoded as a string, e.g. "100.25"
+type Decimal struct {
+	value int64
+	scale int32
+}
+
+// NewDecimal возвращает value * 10^-scale.
+func NewDecimal(value int64, scale int32) Decimal {
+	return Decimal{value: value, scale: scale}
+}
+
+// ParseDecimal разбирает десятичную строку вида "-12.340".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Decimal{}, ErrInvalidAmount
+	}
+
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || hasDot && fracPart == "" {
+		return Decimal{}, ErrInvalidAmount
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return Decimal{}, ErrInvalidAmount
+		}
+	}
+
+	value, err := strconv.ParseInt(sign+intPart+fracPart, 10, 64)
+	if err != nil {
+		return Decimal{}, ErrInvalidAmount
+	}
+
+	return Decimal{value: value, scale: int32(len(fracPart))}, nil
+}
+
+// DecimalFromFloat переводит float в кратчайшее представляющее его десятичное
+// число. Так принимаются курсы от обменника.
+func DecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, ErrInvalidAmount
+	}
+
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func (d Decimal) Sign() int {
+	switch {
+	case d.value > 0:
+		return 1
+	case d.value < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.value > 0
+}
+
+// Cmp сравнивает d с other и возвращает -1, 0 или +1.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := d.big(), other.big()
+	switch {
+	case d.scale < other.scale:
+		a.Mul(a, pow10(other.scale-d.scale))
+	case d.scale > other.scale:
+		b.Mul(b, pow10(d.scale-other.scale))
+	}
+
+	return a.Cmp(b)
+}
+
+// Float64 возвращает ближайшее значение float. Для расчётов с деньгами не годится.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.value, 10)
+	sign := ""
+	if d.value < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-d.scale))
+	}
+
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON принимает и строки, и числа JSON. Числа разбираются
+// из текста, а не через float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) big() *big.Int {
+	return big.NewInt(d.value)
+}
+
+// Money — сумма в валюте, хранится целым числом минимальных единиц.
+// @Description Monetary amount with currency, amount is a decimal string
+type Money struct {
+	Currency Currency
+	Amount   int64
+}
+
+// NewMoney переводит сумму в минимальные единицы валюты. Неизвестная или
+// отключённая валюта и лишние знаки после запятой отклоняются.
+func NewMoney(amount Decimal, currency Currency) (Money, error) {
+	return Currencies.NewMoney(amount, currency)
+}
+
+// NewMoney — то же, что NewMoney, но с валютами этого реестра.
+func (r *CurrencyRegistry) NewMoney(amount Decimal, currency Currency) (Money, error) {
+	if _, ok := r.Lookup(currency); !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	exp := r.minorUnits(currency)
+	value := amount.big()
+
+	if amount.scale > exp {
+		divisor := pow10(amount.scale - exp)
+		quo, rem := new(big.Int).QuoRem(value, divisor, new(big.Int))
+		if rem.Sign() != 0 {
+			return Money{}, ErrTooPrecise
+		}
+		value = quo
+	} else {
+		value.Mul(value, pow10(exp-amount.scale))
+	}
+
+	if !value.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Currency: currency, Amount: value.Int64()}, nil
+}
+
+// ParseMoney разбирает сумму в валюте из десятичной строки.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	return Currencies.ParseMoney(amount, currency)
+}
+
+// ParseMoney — то же, что ParseMoney, но с валютами этого реестра.
+func (r *CurrencyRegistry) ParseMoney(amount string, currency Currency) (Money, error) {
+	d, err := ParseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return r.NewMoney(d, currency)
+}
+
+// Decimal возвращает сумму в основных единицах.
+func (m Money) Decimal() Decimal {
+	return Currencies.Decimal(m)
+}
+
+// Decimal — то же, что Money.Decimal, но с точностью из этого реестра.
+func (r *CurrencyRegistry) Decimal(m Money) Decimal {
+	return Decimal{value: m.Amount, scale: r.minorUnits(m.Currency)}
+}
+
+func (m Money) String() string {
+	return m.Decimal().String()
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Currency: m.Currency, Amount: sum}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Currency: other.Currency, Amount: -other.Amount})
+}
+
+// Convert переводит сумму в валюту to по курсу rate и округляет результат
+// до точности этой валюты способом mode.
+func (m Money) Convert(to Currency, rate Decimal, mode RoundingMode) (Money, error) {
+	return Currencies.Convert(m, to, rate, mode)
+}
+
+// Convert — то же, что Money.Convert, но с точностью из этого реестра.
+func (r *CurrencyRegistry) Convert(m Money, to Currency, rate Decimal, mode RoundingMode) (Money, error) {
+	if !rate.IsPositive() {
+		return Money{}, ErrInvalidRate
+	}
+
+	num := new(big.Int).Mul(big.NewInt(m.Amount), rate.big())
+	den := big.NewInt(1)
+
+	shift := r.minorUnits(to) - r.minorUnits(m.Currency) - rate.scale
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	result := roundQuo(num, den, mode)
+	if !result.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Currency: to, Amount: result.Int64()}, nil
+}
+
+type moneyJSON struct {
+	Currency Currency `json:"currency"`
+	Amount   string   `json:"amount"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Currency: m.Currency, Amount: m.String()})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Currency Currency `json:"currency"`
+		Amount   Decimal  `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	parsed, err := NewMoney(raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// roundQuo делит num на положительный den и округляет частное способом mode.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	away := big.NewInt(int64(num.Sign()))
+
+	switch mode {
+	case RoundUp:
+		return quo.Add(quo, away)
+	case RoundHalfUp, RoundHalfEven:
+		twice := new(big.Int).Abs(rem)
+		twice.Mul(twice, big.NewInt(2))
+		switch cmp := twice.Cmp(den); {
+		case cmp > 0:
+			return quo.Add(quo, away)
+		case cmp == 0 && (mode == RoundHalfUp || new(big.Int).Abs(quo).Bit(0) == 1):
+			return quo.Add(quo, away)
+		}
+	}
+
+	return quo
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+var (
+	ErrTooPrecise       = errors.New("amount has more decimal places than the currency allows")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidRate      = errors.New("invalid exchange rate")
+)
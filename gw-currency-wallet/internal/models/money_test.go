@@ -0,0 +1,119 @@
+package models_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr error
+	}{
+		{in: "0.29", want: 29},
+		{in: "100", want: 10000},
+		{in: "1.5", want: 150},
+		{in: "12.340", want: 1234},
+		{in: "0.001", wantErr: models.ErrTooPrecise},
+		{in: "1e3", wantErr: models.ErrInvalidAmount},
+		{in: "1.", wantErr: models.ErrInvalidAmount},
+		{in: "", wantErr: models.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		got, err := models.ParseMoney(tt.in, models.USD)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseMoney(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.Amount != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var req models.WalletOperationReq
+	if err := json.Unmarshal([]byte(`{"currency":"USD","amount":0.29}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	m, err := models.NewMoney(req.Amount, models.Currency(req.Currency))
+	if err != nil {
+		t.Fatalf("NewMoney: %v", err)
+	}
+	if m.Amount != 29 {
+		t.Fatalf("amount = %d, want 29", m.Amount)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"currency":"USD","amount":"0.29"}` {
+		t.Fatalf("marshal = %s", data)
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	rate := models.NewDecimal(755, 1) // 75.5
+
+	tests := []struct {
+		amount int64
+		mode   models.RoundingMode
+		want   int64
+	}{
+		{amount: 1, mode: models.RoundDown, want: 75},
+		{amount: 1, mode: models.RoundUp, want: 76},
+		{amount: 1, mode: models.RoundHalfUp, want: 76},
+		{amount: 1, mode: models.RoundHalfEven, want: 76},
+		{amount: 3, mode: models.RoundHalfEven, want: 226},
+		{amount: 100, mode: models.RoundDown, want: 7550},
+	}
+
+	for _, tt := range tests {
+		m := models.Money{Currency: models.USD, Amount: tt.amount}
+		got, err := m.Convert(models.RUB, rate, tt.mode)
+		if err != nil {
+			t.Fatalf("Convert: %v", err)
+		}
+		if got.Amount != tt.want || got.Currency != models.RUB {
+			t.Errorf("Convert(%d, mode %d) = %d %s, want %d RUB", tt.amount, tt.mode, got.Amount, got.Currency, tt.want)
+		}
+	}
+}
+
+func TestMoneyCurrencyPrecision(t *testing.T) {
+	registry := models.NewCurrencyRegistry(
+		models.CurrencyInfo{Code: models.USD, MinorUnits: 2, Enabled: true},
+		models.CurrencyInfo{Code: "JPY", MinorUnits: 0, Enabled: true},
+		models.CurrencyInfo{Code: "KWD", MinorUnits: 3, Enabled: true},
+		models.CurrencyInfo{Code: "BTC", MinorUnits: 8, Enabled: false},
+	)
+
+	if m, err := registry.ParseMoney("150", "JPY"); err != nil || m.Amount != 150 || registry.Decimal(m).String() != "150" {
+		t.Errorf("JPY: got %v %v", m, err)
+	}
+	if _, err := registry.ParseMoney("1.5", "JPY"); !errors.Is(err, models.ErrTooPrecise) {
+		t.Errorf("JPY fractional: error = %v, want ErrTooPrecise", err)
+	}
+	if m, err := registry.ParseMoney("1.234", "KWD"); err != nil || m.Amount != 1234 || registry.Decimal(m).String() != "1.234" {
+		t.Errorf("KWD: got %v %v", m, err)
+	}
+	if _, err := registry.ParseMoney("1", "BTC"); !errors.Is(err, models.ErrUnsupportedCurrency) {
+		t.Errorf("disabled BTC: error = %v, want ErrUnsupportedCurrency", err)
+	}
+	if _, err := models.ParseMoney("150", "JPY"); !errors.Is(err, models.ErrUnsupportedCurrency) {
+		t.Errorf("JPY outside the registry: error = %v, want ErrUnsupportedCurrency", err)
+	}
+
+	usd := models.Money{Currency: models.USD, Amount: 100}
+	got, err := registry.Convert(usd, "JPY", models.NewDecimal(15025, 2), models.RoundDown)
+	if err != nil || got.Amount != 150 {
+		t.Errorf("USD->JPY: got %v %v, want 150", got, err)
+	}
+}
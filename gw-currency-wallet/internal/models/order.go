@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderStatus string
+
+const (
+	OrderOpen      OrderStatus = "open"
+	OrderFilled    OrderStatus = "filled"
+	OrderCancelled OrderStatus = "cancelled"
+	OrderExpired   OrderStatus = "expired"
+	// OrderFailed means the rate was reached but the exchange was rejected,
+	// for example by a spending limit or a frozen wallet.
+	OrderFailed OrderStatus = "failed"
+)
+
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderOpen, OrderFilled, OrderCancelled, OrderExpired, OrderFailed:
+		return true
+	}
+	return false
+}
+
+var orderEvents = map[OrderStatus]EventType{
+	OrderOpen:      OrderPlacedEvent,
+	OrderFilled:    OrderFilledEvent,
+	OrderCancelled: OrderCancelledEvent,
+	OrderExpired:   OrderExpiredEvent,
+	OrderFailed:    OrderFailedEvent,
+}
+
+// LimitOrder converts Amount into ToCurrency once the exchange rate
+// Amount.Currency->ToCurrency reaches TargetRate. Amount and Fee are reserved
+// by the hold HoldID until the order is filled, cancelled or expires.
+// @Description Conditional currency conversion executed when the rate reaches the target
+type LimitOrder struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	UserID     uuid.UUID `db:"user_id" json:"-"`
+	WalletID   uuid.UUID `db:"wallet_id" json:"wallet_id"`
+	HoldID     uuid.UUID `db:"hold_id" json:"hold_id"`
+	Amount     Money     `db:"amount" json:"amount"`
+	ToCurrency Currency  `db:"to_currency" json:"to_currency" example:"EUR"`
+	TargetRate Decimal   `db:"target_rate" json:"target_rate" swaggertype:"string" example:"0.95"`
+	// Fee is fixed when the order is placed, like the fee of a quote.
+	Fee    Money       `db:"fee" json:"fee"`
+	Status OrderStatus `db:"status" json:"status"`
+	// FilledRate and Converted are set once the order is filled.
+	FilledRate *Decimal  `db:"filled_rate" json:"filled_rate,omitempty" swaggertype:"string" example:"0.9512"`
+	Converted  *Money    `db:"to_amount" json:"converted,omitempty"`
+	Error      string    `db:"error" json:"error,omitempty"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the amount, the currencies and the target rate. An order
+// must buy at least one minor unit at its target rate.
+func (o *LimitOrder) Validate() error {
+	if !o.Amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	if err := o.ToCurrency.Validate(); err != nil {
+		return err
+	}
+	if o.ToCurrency == o.Amount.Currency {
+		return ErrSameCurrency
+	}
+	if !o.TargetRate.IsPositive() {
+		return ErrInvalidOrder
+	}
+
+	converted, err := o.Amount.Convert(o.ToCurrency, o.TargetRate, RoundDown)
+	if err != nil {
+		return err
+	}
+	if !converted.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	return nil
+}
+
+// Reserve returns the amount held for the order: the amount and the fee.
+func (o *LimitOrder) Reserve() (Money, error) {
+	return o.Amount.Add(o.Fee)
+}
+
+// NewOrderEvent builds the event published when order moves to its current status.
+func NewOrderEvent(order *LimitOrder) *EventMessage {
+	details := fmt.Sprintf("order_id=%s target_rate=%s", order.ID, order.TargetRate)
+	if order.FilledRate != nil && order.Converted != nil {
+		details += fmt.Sprintf(" rate=%s converted=%s", order.FilledRate, order.Converted.String())
+	}
+	if order.Error != "" {
+		details += fmt.Sprintf(" error=%q", order.Error)
+	}
+
+	return &EventMessage{
+		EventID:   uuid.New(),
+		Event:     orderEvents[order.Status],
+		UserID:    order.UserID,
+		WalletID:  order.WalletID,
+		Amount:    order.Amount,
+		Currency:  fmt.Sprintf("%s->%s", order.Amount.Currency, order.ToCurrency),
+		Timestamp: time.Now(),
+		Details:   details,
+	}
+}
+
+var (
+	ErrInvalidOrder    = errors.New("invalid limit order")
+	ErrInvalidOrderTTL = errors.New("invalid order ttl")
+	ErrOrderNotFound   = errors.New("limit order not found")
+	ErrOrderNotOpen    = errors.New("limit order is not open")
+)
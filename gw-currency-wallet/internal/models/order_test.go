@@ -0,0 +1,67 @@
+package models_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestLimitOrderValidate(t *testing.T) {
+	valid := models.LimitOrder{Amount: usd(100000), ToCurrency: models.EUR, TargetRate: models.NewDecimal(95, 2)}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid order rejected: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		order models.LimitOrder
+		want  error
+	}{
+		{"zero amount", models.LimitOrder{Amount: usd(0), ToCurrency: models.EUR, TargetRate: models.NewDecimal(1, 0)}, models.ErrInvalidAmount},
+		{"same currency", models.LimitOrder{Amount: usd(100), ToCurrency: models.USD, TargetRate: models.NewDecimal(1, 0)}, models.ErrSameCurrency},
+		{"unknown currency", models.LimitOrder{Amount: usd(100), ToCurrency: "XXX", TargetRate: models.NewDecimal(1, 0)}, models.ErrUnsupportedCurrency},
+		{"zero rate", models.LimitOrder{Amount: usd(100), ToCurrency: models.EUR}, models.ErrInvalidOrder},
+		{"buys nothing", models.LimitOrder{Amount: usd(1), ToCurrency: models.EUR, TargetRate: models.NewDecimal(1, 1)}, models.ErrInvalidAmount},
+	}
+	for _, tc := range cases {
+		if err := tc.order.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Validate() = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestNewOrderEvent(t *testing.T) {
+	rate := models.NewDecimal(96, 2)
+	converted := models.Money{Currency: models.EUR, Amount: 96000}
+	order := &models.LimitOrder{
+		ID:         uuid.New(),
+		UserID:     uuid.New(),
+		WalletID:   uuid.New(),
+		Amount:     usd(100000),
+		ToCurrency: models.EUR,
+		TargetRate: models.NewDecimal(95, 2),
+		Status:     models.OrderFilled,
+		FilledRate: &rate,
+		Converted:  &converted,
+	}
+
+	evt := models.NewOrderEvent(order)
+	if evt.Event != models.OrderFilledEvent || evt.UserID != order.UserID || evt.WalletID != order.WalletID {
+		t.Errorf("unexpected event: %+v", evt)
+	}
+	if evt.Currency != "USD->EUR" {
+		t.Errorf("Currency = %q, want USD->EUR", evt.Currency)
+	}
+	if !strings.Contains(evt.Details, "order_id="+order.ID.String()) || !strings.Contains(evt.Details, "rate=0.96 converted=960.00") {
+		t.Errorf("Details = %q", evt.Details)
+	}
+
+	order.Status = models.OrderCancelled
+	if evt := models.NewOrderEvent(order); evt.Event != models.OrderCancelledEvent {
+		t.Errorf("cancelled order event = %q", evt.Event)
+	}
+}
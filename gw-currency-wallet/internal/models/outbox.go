@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is a Kafka message stored in the same transaction as the
+// change it describes and published later by the outbox relay.
+type OutboxMessage struct {
+	ID            uuid.UUID       `db:"id"`
+	Key           string          `db:"message_key"`
+	Payload       json.RawMessage `db:"payload"`
+	Attempts      int             `db:"attempts"`
+	LastError     *string         `db:"last_error"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	CreatedAt     time.Time       `db:"created_at"`
+	SentAt        *time.Time      `db:"sent_at"`
+}
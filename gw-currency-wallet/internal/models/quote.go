@@ -0,0 +1,31 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeQuote locks an exchange rate for a user until ExpiresAt.
+// A quote can be executed only once.
+// @Description Exchange quote with a locked rate
+type ExchangeQuote struct {
+	ID        uuid.UUID `db:"id" json:"quote_id"`
+	UserID    uuid.UUID `db:"user_id" json:"-"`
+	WalletID  uuid.UUID `db:"wallet_id" json:"-"`
+	Amount    Money     `db:"amount" json:"amount"`
+	Converted Money     `db:"to_amount" json:"converted"`
+	Rate      Decimal   `db:"rate" json:"rate" swaggertype:"string" example:"0.0105"`
+	// Fee is charged in the sold currency on top of Amount.
+	Fee       Money      `db:"fee" json:"fee"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrQuoteNotFound = errors.New("exchange quote not found")
+	ErrQuoteExpired  = errors.New("exchange quote has expired")
+	ErrQuoteUsed     = errors.New("exchange quote has already been used")
+)
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleKind string
+
+const (
+	// ScheduleTransfer moves money from one wallet of the user to another.
+	ScheduleTransfer ScheduleKind = "transfer"
+	// ScheduleExchange converts money inside one wallet.
+	ScheduleExchange ScheduleKind = "exchange"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleActive ScheduleStatus = "active"
+	SchedulePaused ScheduleStatus = "paused"
+)
+
+func (s ScheduleStatus) Valid() bool {
+	return s == ScheduleActive || s == SchedulePaused
+}
+
+// ScheduledTransfer is a standing order the scheduler runs at the times of a
+// cron expression, evaluated in UTC. Failures counts failed runs in a row;
+// the schedule is paused once it reaches the configured maximum.
+// @Description Standing order: a recurring transfer between own wallets or exchange inside a wallet
+type ScheduledTransfer struct {
+	ID       uuid.UUID    `db:"id" json:"id"`
+	UserID   uuid.UUID    `db:"user_id" json:"-"`
+	Kind     ScheduleKind `db:"kind" json:"kind" example:"transfer"`
+	WalletID uuid.UUID    `db:"wallet_id" json:"wallet_id"`
+	// ToWalletID is set for transfers.
+	ToWalletID *uuid.UUID `db:"to_wallet_id" json:"to_wallet_id,omitempty"`
+	Amount     Money      `db:"amount" json:"amount"`
+	// ToCurrency is set for exchanges.
+	ToCurrency Currency       `db:"to_currency" json:"to_currency,omitempty" example:"EUR"`
+	Cron       string         `db:"cron" json:"cron" example:"0 9 * * FRI"`
+	Status     ScheduleStatus `db:"status" json:"status"`
+	Failures   int            `db:"failures" json:"failures"`
+	NextRunAt  time.Time      `db:"next_run_at" json:"next_run_at"`
+	LastRunAt  *time.Time     `db:"last_run_at" json:"last_run_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Validate checks that the operation of the schedule is well-formed.
+// Ownership of the wallets and the cron expression are checked elsewhere.
+func (s *ScheduledTransfer) Validate() error {
+	if !s.Amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	switch s.Kind {
+	case ScheduleTransfer:
+		if s.ToWalletID == nil || s.ToCurrency != "" {
+			return ErrInvalidSchedule
+		}
+		if *s.ToWalletID == s.WalletID {
+			return ErrSelfTransfer
+		}
+	case ScheduleExchange:
+		if s.ToWalletID != nil || s.ToCurrency == "" {
+			return ErrInvalidSchedule
+		}
+		if s.ToCurrency == s.Amount.Currency {
+			return ErrSameCurrency
+		}
+	default:
+		return ErrInvalidSchedule
+	}
+
+	return nil
+}
+
+type ScheduleRunStatus string
+
+const (
+	ScheduleRunSucceeded ScheduleRunStatus = "succeeded"
+	ScheduleRunFailed    ScheduleRunStatus = "failed"
+)
+
+// ScheduleRun is one execution of a schedule. Its ID is derived from the
+// schedule and the planned time, so the same run cannot be recorded twice.
+// @Description Result of one run of a standing order
+type ScheduleRun struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	ScheduleID   uuid.UUID         `db:"schedule_id" json:"schedule_id"`
+	ScheduledFor time.Time         `db:"scheduled_for" json:"scheduled_for"`
+	Status       ScheduleRunStatus `db:"status" json:"status"`
+	Error        string            `db:"error" json:"error,omitempty"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+}
+
+// ExecutionID returns the ID of the run of scheduleID planned for scheduledFor.
+func ExecutionID(scheduleID uuid.UUID, scheduledFor time.Time) uuid.UUID {
+	return uuid.NewSHA1(scheduleID, []byte(scheduledFor.UTC().Format(time.RFC3339)))
+}
+
+var (
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrTooManySchedules = errors.New("too many schedules")
+)
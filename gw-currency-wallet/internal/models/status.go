@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountStatus is the lifecycle state of a user account or a wallet.
+// Money moves only if both the wallet and its owner's account allow it.
+type AccountStatus string
+
+const (
+	StatusActive AccountStatus = "active"
+	// StatusDebitBlocked accepts incoming money but lets nothing out.
+	StatusDebitBlocked AccountStatus = "debit_blocked"
+	// StatusFrozen rejects every operation that moves money.
+	StatusFrozen AccountStatus = "frozen"
+	// StatusClosed is final: the account or wallet never moves money again.
+	StatusClosed AccountStatus = "closed"
+)
+
+// statusRank orders statuses from the least to the most restrictive.
+var statusRank = map[AccountStatus]int{
+	StatusActive:       1,
+	StatusDebitBlocked: 2,
+	StatusFrozen:       3,
+	StatusClosed:       4,
+}
+
+func (s AccountStatus) Valid() bool {
+	_, ok := statusRank[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the status may be changed to next.
+// Any status except closed may change to any other; closed is final.
+func (s AccountStatus) CanTransitionTo(next AccountStatus) bool {
+	return s.Valid() && next.Valid() && s != next && s != StatusClosed
+}
+
+// Stricter returns the more restrictive of s and other. Unknown statuses
+// are treated as frozen.
+func (s AccountStatus) Stricter(other AccountStatus) AccountStatus {
+	s, other = s.orFrozen(), other.orFrozen()
+	if statusRank[other] > statusRank[s] {
+		return other
+	}
+	return s
+}
+
+// CheckDebit returns an error unless money may leave an account in this status.
+func (s AccountStatus) CheckDebit() error {
+	if s == StatusDebitBlocked {
+		return ErrWalletDebitBlocked
+	}
+	return s.CheckCredit()
+}
+
+// CheckCredit returns an error unless money may enter an account in this status.
+func (s AccountStatus) CheckCredit() error {
+	switch s.orFrozen() {
+	case StatusClosed:
+		return ErrWalletClosed
+	case StatusFrozen:
+		return ErrWalletFrozen
+	}
+	return nil
+}
+
+func (s AccountStatus) orFrozen() AccountStatus {
+	if !s.Valid() {
+		return StatusFrozen
+	}
+	return s
+}
+
+// StatusTransition records a status change of an account or, when WalletID
+// is set, of one of its wallets.
+// @Description Status change of a user account or wallet
+type StatusTransition struct {
+	ID        uuid.UUID     `db:"id" json:"id"`
+	UserID    uuid.UUID     `db:"user_id" json:"user_id"`
+	WalletID  *uuid.UUID    `db:"wallet_id" json:"wallet_id,omitempty"`
+	From      AccountStatus `db:"from_status" json:"from" example:"active"`
+	To        AccountStatus `db:"to_status" json:"to" example:"frozen"`
+	ActorID   uuid.UUID     `db:"actor_id" json:"actor_id"`
+	Reason    string        `db:"reason" json:"reason"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrInvalidStatus           = errors.New("invalid status")
+	ErrInvalidStatusTransition = errors.New("status transition is not allowed")
+	ErrWalletNotEmpty          = errors.New("wallet must have no funds to be closed")
+)
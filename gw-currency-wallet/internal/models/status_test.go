@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestAccountStatusChecks(t *testing.T) {
+	cases := []struct {
+		status        models.AccountStatus
+		debit, credit error
+	}{
+		{models.StatusActive, nil, nil},
+		{models.StatusDebitBlocked, models.ErrWalletDebitBlocked, nil},
+		{models.StatusFrozen, models.ErrWalletFrozen, models.ErrWalletFrozen},
+		{models.StatusClosed, models.ErrWalletClosed, models.ErrWalletClosed},
+		{models.AccountStatus("unknown"), models.ErrWalletFrozen, models.ErrWalletFrozen},
+	}
+
+	for _, tc := range cases {
+		if err := tc.status.CheckDebit(); !errors.Is(err, tc.debit) {
+			t.Errorf("%s: CheckDebit() = %v, want %v", tc.status, err, tc.debit)
+		}
+		if err := tc.status.CheckCredit(); !errors.Is(err, tc.credit) {
+			t.Errorf("%s: CheckCredit() = %v, want %v", tc.status, err, tc.credit)
+		}
+	}
+}
+
+func TestAccountStatusTransitions(t *testing.T) {
+	if !models.StatusActive.CanTransitionTo(models.StatusFrozen) || !models.StatusFrozen.CanTransitionTo(models.StatusActive) {
+		t.Error("freezing and unfreezing must be allowed")
+	}
+	if !models.StatusDebitBlocked.CanTransitionTo(models.StatusClosed) {
+		t.Error("debit-blocked account must be closable")
+	}
+	if models.StatusClosed.CanTransitionTo(models.StatusActive) {
+		t.Error("closed is a final status")
+	}
+	if models.StatusActive.CanTransitionTo(models.StatusActive) {
+		t.Error("transition to the same status reported as allowed")
+	}
+	if models.StatusActive.CanTransitionTo(models.AccountStatus("deleted")) {
+		t.Error("transition to an unknown status reported as allowed")
+	}
+}
+
+func TestAccountStatusStricter(t *testing.T) {
+	if got := models.StatusActive.Stricter(models.StatusDebitBlocked); got != models.StatusDebitBlocked {
+		t.Errorf("active.Stricter(debit_blocked) = %s", got)
+	}
+	if got := models.StatusClosed.Stricter(models.StatusFrozen); got != models.StatusClosed {
+		t.Errorf("closed.Stricter(frozen) = %s", got)
+	}
+	if got := models.StatusActive.Stricter(""); got != models.StatusFrozen {
+		t.Errorf("active.Stricter(\"\") = %s, want frozen", got)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessClaims are the claims of a parsed access token.
+type AccessClaims struct {
+	UserID    string
+	Role      Role
+	JTI       uuid.UUID
+	ExpiresAt time.Time
+}
+
+// RefreshToken is a stored refresh token. Only the SHA-256 hash of the token
+// is kept. Every rotation issues a new token in the same family; presenting
+// an already rotated token revokes the whole family.
+type RefreshToken struct {
+	ID              uuid.UUID  `db:"id"`
+	UserID          uuid.UUID  `db:"user_id"`
+	FamilyID        uuid.UUID  `db:"family_id"`
+	TokenHash       string     `db:"token_hash"`
+	AccessJTI       uuid.UUID  `db:"access_jti"`
+	AccessExpiresAt time.Time  `db:"access_expires_at"`
+	ExpiresAt       time.Time  `db:"expires_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	RotatedAt       *time.Time `db:"rotated_at"`
+	RevokedAt       *time.Time `db:"revoked_at"`
+}
+
+// TokenPair is returned on login and refresh
+// @Description Access token with the refresh token used to renew it
+type TokenPair struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// EmailTokenPurpose is the action an emailed token confirms.
+type EmailTokenPurpose string
+
+const (
+	EmailVerification EmailTokenPurpose = "email_verification"
+	PasswordReset     EmailTokenPurpose = "password_reset"
+)
+
+// EmailToken is a single-use token sent to the user's email. Like refresh
+// tokens, only the SHA-256 hash of the token is kept. Issuing a new token
+// invalidates the unused ones with the same purpose.
+type EmailToken struct {
+	ID        uuid.UUID         `db:"id"`
+	UserID    uuid.UUID         `db:"user_id"`
+	Purpose   EmailTokenPurpose `db:"purpose"`
+	TokenHash string            `db:"token_hash"`
+	ExpiresAt time.Time         `db:"expires_at"`
+	CreatedAt time.Time         `db:"created_at"`
+	UsedAt    *time.Time        `db:"used_at"`
+}
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrInvalidEmailToken   = errors.New("invalid, expired or already used token")
+)
@@ -1,16 +1,112 @@
 package models
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type TransactionType string
+
+const (
+	TransactionDeposit  TransactionType = "deposit"
+	TransactionWithdraw TransactionType = "withdraw"
+	TransactionExchange TransactionType = "exchange"
+
+	TransactionTransferIn  TransactionType = "transfer_in"
+	TransactionTransferOut TransactionType = "transfer_out"
+
+	TransactionHoldCapture TransactionType = "hold_capture"
+
+	TransactionAdjustmentIn  TransactionType = "adjustment_in"
+	TransactionAdjustmentOut TransactionType = "adjustment_out"
+
+	// TransactionFeeSettlement only types journal entries that move collected
+	// fees to the house wallet; it never appears in the operation history.
+	TransactionFeeSettlement TransactionType = "fee_settlement"
+)
+
+func (t TransactionType) Valid() bool {
+	switch t {
+	case TransactionDeposit, TransactionWithdraw, TransactionExchange,
+		TransactionTransferIn, TransactionTransferOut, TransactionHoldCapture,
+		TransactionAdjustmentIn, TransactionAdjustmentOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transaction model
+// @Description Wallet operation from the transaction history
 type Transaction struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	UserID       int64     `db:"user_id" json:"user_id"`
-	FromCurrency string    `db:"from_currency" json:"from_currency"`
-	ToCurrency   string    `db:"to_currency" json:"to_currency"`
-	Amount       float64   `db:"amount" json:"amount"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID        uuid.UUID       `db:"id" json:"id"`
+	UserID    uuid.UUID       `db:"user_id" json:"user_id"`
+	WalletID  uuid.UUID       `db:"wallet_id" json:"wallet_id"`
+	Type      TransactionType `db:"type" json:"type"`
+	Amount    Money           `db:"amount" json:"amount"`
+	Converted *Money          `db:"to_amount" json:"converted,omitempty"`
+	Rate      *string         `db:"rate" json:"rate,omitempty"`
+	// Fee is charged in the currency of Amount on top of it.
+	Fee *Money `db:"fee" json:"fee,omitempty"`
+	// CounterpartyUserID is the other side of a transfer.
+	CounterpartyUserID *uuid.UUID `db:"counterparty_user_id" json:"counterparty_user_id,omitempty"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TransactionFilter describes a page request for the transaction history.
+type TransactionFilter struct {
+	Type     TransactionType
+	Currency Currency
+	From     *time.Time
+	To       *time.Time
+	Cursor   *TransactionCursor
+	Limit    int
+}
+
+// TransactionCursor points at the last transaction of the previous page.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// TransactionPage is a page of the transaction history
+// @Description Page of transactions, pass next_cursor to get the next page
+type TransactionPage struct {
+	Items      []*Transaction `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	txID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &TransactionCursor{CreatedAt: createdAt, ID: txID}, nil
 }
+
+var ErrInvalidCursor = errors.New("invalid cursor")
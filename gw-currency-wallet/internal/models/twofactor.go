@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorCodeHeader carries the TOTP code that confirms a large withdrawal or transfer.
+const TwoFactorCodeHeader = "X-2FA-Code"
+
+// StepUpThresholds are the amounts in major units from which a withdrawal,
+// transfer or hold capture needs a TOTP code. An amount is compared with the
+// threshold of its own currency; Default applies to currencies without one.
+type StepUpThresholds struct {
+	Default    Decimal
+	Currencies map[Currency]Decimal
+}
+
+// ParseStepUpThresholds parses the default threshold and a comma-separated
+// list of per-currency thresholds such as "RUB:100000,BTC:0.02".
+func ParseStepUpThresholds(def, perCurrency string) (StepUpThresholds, error) {
+	thresholds := StepUpThresholds{Currencies: make(map[Currency]Decimal)}
+
+	var err error
+	if thresholds.Default, err = ParseDecimal(def); err != nil || thresholds.Default.Sign() < 0 {
+		return StepUpThresholds{}, fmt.Errorf("%w: %q", ErrInvalidStepUpThreshold, def)
+	}
+
+	for _, item := range strings.Split(perCurrency, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		code, amount, ok := strings.Cut(item, ":")
+		if !ok {
+			return StepUpThresholds{}, fmt.Errorf("%w: %q", ErrInvalidStepUpThreshold, item)
+		}
+		threshold, err := ParseDecimal(strings.TrimSpace(amount))
+		if err != nil || threshold.Sign() < 0 {
+			return StepUpThresholds{}, fmt.Errorf("%w: %q", ErrInvalidStepUpThreshold, item)
+		}
+
+		thresholds.Currencies[Currency(strings.ToUpper(strings.TrimSpace(code)))] = threshold
+	}
+
+	return thresholds, nil
+}
+
+// Requires reports whether amount reaches the threshold of its currency.
+func (t StepUpThresholds) Requires(amount Money) bool {
+	threshold, ok := t.Currencies[amount.Currency]
+	if !ok {
+		threshold = t.Default
+	}
+
+	return amount.Decimal().Cmp(threshold) >= 0
+}
+
+// TOTP is the authenticator app enrolled by a user. The secret is stored
+// encrypted; it is enabled once the user confirms a first code.
+type TOTP struct {
+	UserID          uuid.UUID  `db:"user_id"`
+	EncryptedSecret []byte     `db:"secret_encrypted"`
+	EnabledAt       *time.Time `db:"enabled_at"`
+	LastCounter     int64      `db:"last_counter"`
+	FailedAttempts  int        `db:"failed_attempts"`
+	LockedUntil     *time.Time `db:"locked_until"`
+	CreatedAt       time.Time  `db:"created_at"`
+
+	// Locked is computed on read: LockedUntil has not passed yet.
+	Locked bool `db:"-"`
+}
+
+func (t *TOTP) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TOTPEnrollment is returned when a user starts enrolling an authenticator app
+// @Description Secret to add to an authenticator app, as text or as a QR code of provisioning_uri
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are shown once when two-factor authentication is enabled
+// @Description Single-use codes to log in without the authenticator app
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallenge is a stored login challenge: the password was right
+// and the second factor is still expected. Only the token hash is kept.
+type TwoFactorChallenge struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	TokenHash string    `db:"token_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// LoginChallenge is returned by login instead of tokens when two-factor authentication is enabled
+// @Description Second login step is required: send challenge_token with a TOTP or recovery code to /login/2fa
+type LoginChallenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+var (
+	ErrTwoFactorRequired       = errors.New("two-factor code required")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked         = errors.New("too many invalid two-factor codes, try again later")
+	ErrInvalidChallenge        = errors.New("invalid or expired login challenge")
+	ErrInvalidStepUpThreshold  = errors.New("invalid step-up threshold")
+)
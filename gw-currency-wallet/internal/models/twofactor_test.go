@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+)
+
+func TestStepUpThresholds(t *testing.T) {
+	thresholds, err := models.ParseStepUpThresholds("1000", " rub:100000, EUR:900.50 ")
+	if err != nil {
+		t.Fatalf("ParseStepUpThresholds: %v", err)
+	}
+
+	tests := []struct {
+		amount models.Money
+		want   bool
+	}{
+		{models.Money{Currency: models.USD, Amount: 99999}, false},
+		{models.Money{Currency: models.USD, Amount: 100000}, true},
+		{models.Money{Currency: models.RUB, Amount: 150000}, false},
+		{models.Money{Currency: models.RUB, Amount: 10000000}, true},
+		{models.Money{Currency: models.EUR, Amount: 90049}, false},
+		{models.Money{Currency: models.EUR, Amount: 90050}, true},
+	}
+	for _, tt := range tests {
+		if got := thresholds.Requires(tt.amount); got != tt.want {
+			t.Errorf("Requires(%s %s) = %v, want %v", tt.amount, tt.amount.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestParseStepUpThresholdsInvalid(t *testing.T) {
+	for _, tt := range []struct{ def, perCurrency string }{
+		{"abc", ""},
+		{"-1", ""},
+		{"1000", "RUB"},
+		{"1000", "RUB:abc"},
+		{"1000", "RUB:-5"},
+	} {
+		if _, err := models.ParseStepUpThresholds(tt.def, tt.perCurrency); !errors.Is(err, models.ErrInvalidStepUpThreshold) {
+			t.Errorf("ParseStepUpThresholds(%q, %q) error = %v, want ErrInvalidStepUpThreshold", tt.def, tt.perCurrency, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,9 +10,49 @@ import (
 // User model
 // @Description User account information
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	Username     string    `db:"username" json:"username"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password" json:"-"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID              uuid.UUID     `db:"id" json:"id"`
+	Username        string        `db:"username" json:"username"`
+	Email           string        `db:"email" json:"email"`
+	PasswordHash    string        `db:"password" json:"-"`
+	Role            Role          `db:"role" json:"role"`
+	Status          AccountStatus `db:"status" json:"status" example:"active"`
+	EmailVerifiedAt *time.Time    `db:"email_verified_at" json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time     `db:"created_at" json:"created_at"`
 }
+
+// EmailVerified reports whether the user has confirmed their email.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Role defines what a user may do. Roles are ordered: each one has all
+// rights of the roles below it.
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleUser:    1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// AtLeast reports whether r has all rights of min. Unknown roles have none.
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[min]
+}
+
+var (
+	ErrInvalidRole   = errors.New("invalid role")
+	ErrUsernameTaken = errors.New("username is taken")
+	ErrEmailTaken    = errors.New("email is taken")
+)
@@ -9,99 +9,157 @@ import (
 
 type Currency string
 
-// Currency represents supported currencies
-// @Enum USD, RUB, EUR
+// Currency represents a currency code from the currencies registry
+// @Description ISO currency code, e.g. USD, RUB, EUR
 const (
 	RUB Currency = "RUB"
 	USD Currency = "USD"
 	EUR Currency = "EUR"
 )
 
-const CurrencyFactor int64 = 100
+// DefaultWalletName is the name of the wallet opened at registration.
+const DefaultWalletName = "main"
+
+// MaxWalletNameLength is the longest wallet name in characters.
+const MaxWalletNameLength = 64
+
+// NewDefaultWallet returns the empty default wallet opened for a new user.
+func NewDefaultWallet(userID uuid.UUID) *Wallet {
+	return &Wallet{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      DefaultWalletName,
+		Default:   true,
+		Balances:  make(map[Currency]int64),
+		UpdatedAt: time.Now(),
+	}
+}
 
 // Wallet model
-// @Description User wallet with balances in different currencies
+// @Description User wallet with balances in different currencies. Every user has one default wallet and may open more
 type Wallet struct {
 	ID     uuid.UUID `db:"id" json:"id"`
 	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name" example:"savings"`
+	// Default is the wallet used by routes that do not name a wallet.
+	Default bool `db:"is_default" json:"default"`
 
-	USD int64 `db:"usd" json:"usd"`
-	RUB int64 `db:"rub" json:"rub"`
-	EUR int64 `db:"eur" json:"eur"`
+	Balances map[Currency]int64 `json:"balances"`
+	// Held is the part of Balances reserved by active holds.
+	Held   map[Currency]int64 `json:"held"`
+	Status AccountStatus      `db:"status" json:"status" example:"active"`
 
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (w *Wallet) GetBalanceByCurrency(currency Currency) (float64, error) {
-	switch currency {
-	case RUB:
-		return float64(w.RUB) / float64(CurrencyFactor), nil
-	case USD:
-		return float64(w.USD) / float64(CurrencyFactor), nil
-	case EUR:
-		return float64(w.EUR) / float64(CurrencyFactor), nil
-	default:
-		return 0, ErrUnsupportedCurrency
+// WalletBalance is a single row of the wallet_balances table.
+type WalletBalance struct {
+	WalletID    uuid.UUID `db:"wallet_id" json:"wallet_id"`
+	Currency    Currency  `db:"currency" json:"currency"`
+	AmountMinor int64     `db:"amount_minor" json:"amount_minor"`
+	HeldMinor   int64     `db:"held_minor" json:"held_minor"`
+}
+
+// Balance returns the wallet balance in the given currency.
+func (w *Wallet) Balance(currency Currency) (Money, error) {
+	amount, ok := w.Balances[currency]
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
 	}
+
+	return Money{Currency: currency, Amount: amount}, nil
 }
 
-func (w *Wallet) GetAllBalances() map[Currency]float64 {
-	return map[Currency]float64{
-		RUB: float64(w.RUB) / float64(CurrencyFactor),
-		USD: float64(w.USD) / float64(CurrencyFactor),
-		EUR: float64(w.EUR) / float64(CurrencyFactor),
+// Available returns the part of the balance that is not reserved by holds.
+func (w *Wallet) Available(currency Currency) (Money, error) {
+	balance, err := w.Balance(currency)
+	if err != nil {
+		return Money{}, err
 	}
+
+	balance.Amount -= w.Held[currency]
+	return balance, nil
 }
 
-func (w *Wallet) getTarget(currency Currency) (*int64, error) {
-	switch currency {
-	case RUB:
-		return &w.RUB, nil
-	case USD:
-		return &w.USD, nil
-	case EUR:
-		return &w.EUR, nil
-	default:
-		return nil, ErrUnsupportedCurrency
+// BalanceDetails returns available, held and total balances keyed by currency.
+func (w *Wallet) BalanceDetails() map[Currency]BalanceView {
+	details := make(map[Currency]BalanceView, len(w.Balances))
+	for currency, amount := range w.Balances {
+		held := w.Held[currency]
+		details[currency] = BalanceView{
+			Available: Money{Currency: currency, Amount: amount - held}.Decimal(),
+			Held:      Money{Currency: currency, Amount: held}.Decimal(),
+			Total:     Money{Currency: currency, Amount: amount}.Decimal(),
+		}
 	}
+
+	return details
 }
 
-func (w *Wallet) Deposit(amount float64, currency Currency) (float64, error) {
-	if amount <= 0 {
-		return 0, ErrInvalidAmount
+// GetAllBalances returns balances in major units keyed by currency.
+func (w *Wallet) GetAllBalances() map[Currency]Decimal {
+	balances := make(map[Currency]Decimal, len(w.Balances))
+	for currency, amount := range w.Balances {
+		balances[currency] = Money{Currency: currency, Amount: amount}.Decimal()
+	}
+
+	return balances
+}
+
+func (w *Wallet) Deposit(amount Money) (Money, error) {
+	if !amount.IsPositive() {
+		return Money{}, ErrInvalidAmount
+	}
+	if amount.Currency == "" {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	if w.Balances == nil {
+		w.Balances = make(map[Currency]int64)
 	}
 
-	cents := int64(amount * float64(CurrencyFactor))
-	target, err := w.getTarget(currency)
+	balance, err := Money{Currency: amount.Currency, Amount: w.Balances[amount.Currency]}.Add(amount)
 	if err != nil {
-		return 0, err
+		return Money{}, err
 	}
 
-	*target += cents
-	return float64(*target) / float64(CurrencyFactor), nil
+	w.Balances[amount.Currency] = balance.Amount
+	return balance, nil
 }
 
-func (w *Wallet) Withdraw(amount float64, currency Currency) (float64, error) {
-	if amount <= 0 {
-		return 0, ErrInvalidAmount
+func (w *Wallet) Withdraw(amount Money) (Money, error) {
+	if !amount.IsPositive() {
+		return Money{}, ErrInvalidAmount
 	}
 
-	cents := int64(amount * float64(CurrencyFactor))
-	target, err := w.getTarget(currency)
+	available, err := w.Available(amount.Currency)
 	if err != nil {
-		return 0, err
+		return Money{}, err
 	}
 
-	if *target < cents {
-		return 0, ErrInsufficientFunds
+	if available.Amount < amount.Amount {
+		return Money{}, ErrInsufficientFunds
 	}
 
-	*target -= cents
-	return float64(*target) / float64(CurrencyFactor), nil
+	balance, _ := w.Balance(amount.Currency)
+	balance.Amount -= amount.Amount
+	w.Balances[amount.Currency] = balance.Amount
+	return balance, nil
 }
 
 var (
 	ErrInsufficientFunds   = errors.New("insufficient funds")
 	ErrUnsupportedCurrency = errors.New("unsupported currency")
 	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrSameCurrency        = errors.New("source and target currencies must differ")
+	ErrSelfTransfer        = errors.New("cannot transfer to the same wallet")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+	ErrWalletDebitBlocked  = errors.New("wallet is blocked for outgoing payments")
+	ErrWalletClosed        = errors.New("wallet is closed")
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrInvalidWalletName   = errors.New("wallet name must be 1 to 64 characters")
+	ErrWalletNameTaken     = errors.New("wallet with this name already exists")
+	ErrTooManyWallets      = errors.New("too many open wallets")
+	ErrDefaultWallet       = errors.New("default wallet cannot be closed")
 )
@@ -0,0 +1,116 @@
+package orders
+
+import (
+	"context"
+	grpcClient "gw-currency-wallet/internal/grpc"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"time"
+)
+
+// Executor closes limit orders: fills the ones whose rate was reached and
+// expires the ones whose time ran out.
+type Executor interface {
+	FillOrders(ctx context.Context, from, to models.Currency, rate models.Decimal, limit int) (int, error)
+	ExpireOrders(ctx context.Context, limit int) (int, error)
+}
+
+// Watcher periodically evaluates open limit orders against the rates of the
+// exchanger. Several instances may run at once: each order is claimed with a
+// row lock before it is filled.
+type Watcher struct {
+	executor  Executor
+	rates     grpcClient.ExchangeClient
+	interval  time.Duration
+	timeout   time.Duration
+	batchSize int
+}
+
+func NewWatcher(executor Executor, rates grpcClient.ExchangeClient, interval, timeout time.Duration, batchSize int) *Watcher {
+	return &Watcher{
+		executor:  executor,
+		rates:     rates,
+		interval:  interval,
+		timeout:   timeout,
+		batchSize: batchSize,
+	}
+}
+
+// Run evaluates orders until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	logger.L.Info("Order watcher started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		for w.expire(ctx) == w.batchSize {
+			// Полная пачка: вероятно, истекли ещё заявки, продолжаем сразу.
+		}
+		w.fill(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Order watcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expire closes one batch of expired orders and returns their number.
+func (w *Watcher) expire(ctx context.Context) int {
+	expired, err := w.executor.ExpireOrders(ctx, w.batchSize)
+	if err != nil && ctx.Err() == nil {
+		logger.L.Errorw("Failed to expire orders", "expired", expired, "error", err.Error())
+	}
+	if expired > 0 {
+		logger.L.Infow("Expired orders closed", "count", expired)
+	}
+
+	return expired
+}
+
+// fill fetches the current rates and fills the orders they trigger.
+func (w *Watcher) fill(ctx context.Context) {
+	rateCtx, cancel := context.WithTimeout(ctx, w.timeout)
+	rates, err := w.rates.GetAllRates(rateCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.L.Warnw("Failed to get rates for orders", "error", err.Error())
+		}
+		return
+	}
+
+	for _, r := range rates {
+		rate, err := models.DecimalFromFloat(r.Rate)
+		if err != nil || !rate.IsPositive() {
+			continue
+		}
+		from, to := models.Currency(r.FromCurrency), models.Currency(r.ToCurrency)
+
+		for w.fillPair(ctx, from, to, rate) == w.batchSize {
+			// Полная пачка: вероятно, курс достиг ещё заявок, продолжаем сразу.
+		}
+	}
+}
+
+// fillPair fills one batch of orders from->to and returns the number of
+// orders it closed, or 0 if some of them failed: they would be selected again.
+// Orders held by another instance are not counted, so a batch of them does
+// not keep the watcher selecting the same orders.
+func (w *Watcher) fillPair(ctx context.Context, from, to models.Currency, rate models.Decimal) int {
+	closed, err := w.executor.FillOrders(ctx, from, to, rate, w.batchSize)
+	if closed > 0 {
+		logger.L.Infow("Orders filled", "from", from, "to", to, "rate", rate.String(), "count", closed)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.L.Errorw("Failed to fill orders", "from", from, "to", to, "closed", closed, "error", err.Error())
+		}
+		return 0
+	}
+
+	return closed
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"gw-currency-wallet/internal/kafka"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/storages"
+	"time"
+)
+
+// Relay publishes pending outbox rows to Kafka. Rows are marked as sent only
+// after a successful publish, which gives at-least-once delivery; consumers
+// deduplicate by event_id. Rows with the same key go to the same partition
+// and are published in the order they were created.
+type Relay struct {
+	store    storages.OutboxStorage
+	producer kafka.ProducerInterface
+
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func NewRelay(store storages.OutboxStorage, producer kafka.ProducerInterface, interval time.Duration, batchSize int, maxBackoff time.Duration) *Relay {
+	return &Relay{
+		store:      store,
+		producer:   producer,
+		interval:   interval,
+		batchSize:  batchSize,
+		lease:      time.Minute,
+		minBackoff: time.Second,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	logger.L.Info("Outbox relay started")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		for r.flush(ctx) == r.batchSize {
+			// Полная пачка: вероятно, есть ещё сообщения, забираем сразу.
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// flush publishes one batch and returns the number of claimed messages.
+func (r *Relay) flush(ctx context.Context) int {
+	messages, err := r.store.ClaimOutbox(ctx, r.batchSize, r.lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.L.Errorw("Failed to claim outbox messages", "error", err.Error())
+		}
+		return 0
+	}
+
+	// A failed message blocks the later messages with its key until its next
+	// attempt, so that they do not overtake it.
+	blocked := make(map[string]time.Time)
+
+	for _, msg := range messages {
+		if next, ok := blocked[msg.Key]; ok {
+			r.postpone(ctx, msg, next)
+			continue
+		}
+
+		if err := r.producer.Publish(ctx, msg.Key, json.RawMessage(msg.Payload)); err != nil {
+			blocked[msg.Key] = r.fail(ctx, msg, err)
+			continue
+		}
+
+		if err := r.store.MarkOutboxSent(ctx, msg.ID); err != nil {
+			logger.L.Errorw("Failed to mark outbox message as sent", "id", msg.ID, "error", err.Error())
+		}
+	}
+
+	return len(messages)
+}
+
+// fail reschedules msg after a failed publish and returns its next attempt time.
+func (r *Relay) fail(ctx context.Context, msg *models.OutboxMessage, publishErr error) time.Time {
+	next := time.Now().Add(r.backoff(msg.Attempts))
+
+	logger.L.Warnw("Failed to publish outbox message",
+		"id", msg.ID, "attempts", msg.Attempts+1, "next_attempt_at", next, "error", publishErr.Error())
+
+	if err := r.store.MarkOutboxFailed(ctx, msg.ID, publishErr.Error(), next); err != nil {
+		logger.L.Errorw("Failed to reschedule outbox message", "id", msg.ID, "error", err.Error())
+	}
+
+	return next
+}
+
+// postpone moves msg to next without counting an attempt.
+func (r *Relay) postpone(ctx context.Context, msg *models.OutboxMessage, next time.Time) {
+	if err := r.store.PostponeOutbox(ctx, msg.ID, next); err != nil {
+		logger.L.Errorw("Failed to postpone outbox message", "id", msg.ID, "error", err.Error())
+	}
+}
+
+// backoff doubles the delay with every failed attempt up to maxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.minBackoff
+	for i := 0; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, r.maxBackoff)
+}
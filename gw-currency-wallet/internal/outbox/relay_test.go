@@ -0,0 +1,235 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/outbox"
+	"gw-currency-wallet/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// memoryOutbox хранит сообщения в памяти и выдаёт их так же, как OutboxRepo:
+// неотправленные, готовые к попытке, в порядке создания, с арендой на lease.
+type memoryOutbox struct {
+	mu       sync.Mutex
+	messages []*memoryMessage
+}
+
+type memoryMessage struct {
+	models.OutboxMessage
+	sent bool
+}
+
+func (s *memoryOutbox) add(key, payload string) uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := &memoryMessage{OutboxMessage: models.OutboxMessage{
+		ID:            uuid.New(),
+		Key:           key,
+		Payload:       []byte(`"` + payload + `"`),
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}}
+	s.messages = append(s.messages, msg)
+	return msg.ID
+}
+
+func (s *memoryOutbox) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var claimed []*models.OutboxMessage
+	for _, msg := range s.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if msg.sent || msg.NextAttemptAt.After(now) {
+			continue
+		}
+
+		msg.NextAttemptAt = now.Add(lease)
+		claim := msg.OutboxMessage
+		claimed = append(claimed, &claim)
+	}
+
+	return claimed, nil
+}
+
+func (s *memoryOutbox) MarkOutboxSent(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.find(id).sent = true
+	return nil
+}
+
+func (s *memoryOutbox) MarkOutboxFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.find(id)
+	msg.Attempts++
+	msg.LastError = &reason
+	msg.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (s *memoryOutbox) PostponeOutbox(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.find(id).NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (s *memoryOutbox) find(id uuid.UUID) *memoryMessage {
+	for _, msg := range s.messages {
+		if msg.ID == id {
+			return msg
+		}
+	}
+
+	panic("unknown outbox message " + id.String())
+}
+
+func (s *memoryOutbox) get(id uuid.UUID) models.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.find(id).OutboxMessage
+}
+
+func (s *memoryOutbox) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := 0
+	for _, msg := range s.messages {
+		if !msg.sent {
+			pending++
+		}
+	}
+	return pending
+}
+
+// memoryProducer запоминает опубликованные сообщения и отклоняет первые
+// failures[payload] попыток их отправки.
+type memoryProducer struct {
+	mu        sync.Mutex
+	failures  map[string]int
+	published []string
+}
+
+func (p *memoryProducer) Publish(ctx context.Context, key string, value interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var payload string
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	if p.failures[payload] > 0 {
+		p.failures[payload]--
+		return errors.New("kafka unavailable")
+	}
+
+	p.published = append(p.published, payload)
+	return nil
+}
+
+func (p *memoryProducer) log() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.published)
+}
+
+// runRelay запускает релей и ждёт, пока все сообщения будут отправлены.
+func runRelay(t *testing.T, store *memoryOutbox, producer *memoryProducer, batchSize int) {
+	t.Helper()
+	logger.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		outbox.NewRelay(store, producer, 10*time.Millisecond, batchSize, time.Second).Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for store.pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d messages not sent, published %v", store.pending(), producer.log())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRelay_PublishesAndMarksSent(t *testing.T) {
+	store := &memoryOutbox{}
+	producer := &memoryProducer{}
+	for _, payload := range []string{"a1", "b1", "a2", "c1", "a3"} {
+		store.add(payload[:1], payload)
+	}
+
+	// Пачка меньше числа сообщений: релей забирает следующую сразу.
+	runRelay(t, store, producer, 2)
+
+	if got := producer.log(); !slices.Equal(got, []string{"a1", "b1", "a2", "c1", "a3"}) {
+		t.Errorf("published %v, expected every message once in creation order", got)
+	}
+}
+
+func TestRelay_RetriesAfterKafkaError(t *testing.T) {
+	store := &memoryOutbox{}
+	producer := &memoryProducer{failures: map[string]int{"a1": 1}}
+	id := store.add("a", "a1")
+
+	runRelay(t, store, producer, 10)
+
+	if got := producer.log(); !slices.Equal(got, []string{"a1"}) {
+		t.Errorf("published %v, expected a1 once", got)
+	}
+	msg := store.get(id)
+	if msg.Attempts != 1 || msg.LastError == nil || *msg.LastError != "kafka unavailable" {
+		t.Errorf("failed attempt not recorded: attempts %d, last error %v", msg.Attempts, msg.LastError)
+	}
+}
+
+func TestRelay_KeepsOrderWithinKey(t *testing.T) {
+	store := &memoryOutbox{}
+	producer := &memoryProducer{failures: map[string]int{"a1": 1}}
+	store.add("a", "a1")
+	store.add("b", "b1")
+	a2 := store.add("a", "a2")
+	store.add("b", "b2")
+
+	runRelay(t, store, producer, 10)
+
+	// Ошибка a1 задерживает только сообщения ключа a, и a2 его не обгоняет.
+	if got := producer.log(); !slices.Equal(got, []string{"b1", "b2", "a1", "a2"}) {
+		t.Errorf("published %v, expected b1, b2, a1, a2", got)
+	}
+	if attempts := store.get(a2).Attempts; attempts != 0 {
+		t.Errorf("postponed message counted %d failed attempts, expected 0", attempts)
+	}
+}
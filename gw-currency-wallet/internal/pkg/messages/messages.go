@@ -7,6 +7,8 @@ const (
 	MsgInvalidCredentials = "Invalid email or password"
 	MsgWalletNotFound     = "Wallet not found"
 	MsgInvalidToken       = "Invalid token"
+	MsgTokenRevoked       = "Token has been revoked"
+	MsgForbidden          = "Insufficient permissions"
 	MsgEmailExists        = "User with this email already exists"
 	MsgUserExists         = "User with this name already exists"
 	MsgWithdrawFailed     = "Failed to withdraw funds"
@@ -14,4 +16,80 @@ const (
 	MsgInvalidUserID      = "Invalid user id"
 	MsgExchangeFailed     = "Failed to exchange currency"
 	MsgGetRatesFailed     = "Unable to get currency exchange rate"
+	MsgInvalidAmount      = "Invalid amount"
+	MsgTransferFailed     = "Failed to transfer funds"
+	MsgRecipientNotFound  = "Recipient not found"
+	MsgQuoteFailed        = "Failed to create exchange quote"
+	MsgQuoteNotFound      = "Exchange quote not found"
+	MsgQuoteExpired       = "Exchange quote has expired"
+	MsgQuoteUsed          = "Exchange quote has already been used"
+	MsgHoldFailed         = "Failed to process hold"
+	MsgHoldNotFound       = "Hold not found"
+	MsgHoldNotActive      = "Hold is no longer active"
+	MsgHoldOwnedByOrder   = "Hold reserves a limit order; cancel the order instead"
+	MsgLogoutFailed       = "Failed to log out"
+	MsgUserNotFound       = "User not found"
+	MsgWalletFrozen       = "Wallet is frozen"
+	MsgReasonRequired     = "Reason is required"
+	MsgInvalidRole        = "Invalid role"
+	MsgAdminActionFailed  = "Failed to perform administrative action"
+	MsgLoginLocked        = "Too many failed login attempts, try again later"
+	MsgEmailNotVerified   = "Email is not verified"
+	MsgEmailVerified      = "Email is already verified"
+	MsgInvalidEmailToken  = "Invalid, expired or already used token"
+	MsgEmailSendFailed    = "Failed to send email"
+
+	MsgTwoFactorRequired       = "Two-factor code is required"
+	MsgInvalidTwoFactorCode    = "Invalid two-factor code"
+	MsgTwoFactorLocked         = "Too many invalid two-factor codes, try again later"
+	MsgTwoFactorNotEnabled     = "Two-factor authentication is not enabled"
+	MsgTwoFactorAlreadyEnabled = "Two-factor authentication is already enabled"
+	MsgTwoFactorSetupRequired  = "Enable two-factor authentication to perform this operation"
+	MsgInvalidLoginChallenge   = "Invalid or expired login challenge"
+
+	MsgInvalidAPIKey       = "Invalid, expired or revoked API key"
+	MsgAPIKeyIPNotAllowed  = "API key is not allowed from this IP address"
+	MsgAPIKeyScopeMissing  = "API key does not have the required scope"
+	MsgAPIKeyNotFound      = "API key not found"
+	MsgInvalidAPIKeyScope  = "Unknown API key scope"
+	MsgInvalidAllowedIP    = "Allowed IP must be an address or a CIDR range"
+	MsgInvalidAPIKeyExpiry = "API key expiry must be in the future"
+
+	MsgWalletDebitBlocked      = "Wallet is blocked for outgoing payments"
+	MsgWalletClosed            = "Wallet is closed"
+	MsgInvalidStatus           = "Invalid status, expected active, debit_blocked, frozen or closed"
+	MsgInvalidStatusTransition = "Status transition is not allowed"
+	MsgWalletNotEmpty          = "Wallet must have no funds to be closed"
+
+	MsgInvalidWalletID   = "Invalid wallet id"
+	MsgInvalidWalletName = "Wallet name must be 1 to 64 characters"
+	MsgWalletNameTaken   = "You already have a wallet with this name"
+	MsgTooManyWallets    = "Maximum number of open wallets reached"
+	MsgDefaultWallet     = "Main wallet cannot be closed"
+
+	MsgLimitExceeded = "Spending limit exceeded"
+	MsgInvalidLimit  = "Invalid limit operation, period or amount"
+	MsgLimitNotFound = "Spending limit not found"
+
+	MsgInvalidFeeRule  = "Invalid fee rule operation, currencies or amounts"
+	MsgFeeRuleNotFound = "Fee rule not found"
+	MsgInvalidFeeID    = "Invalid fee rule id"
+
+	MsgInvalidSchedule   = "Invalid standing order"
+	MsgScheduleNotFound  = "Standing order not found"
+	MsgTooManySchedules  = "Maximum number of standing orders reached"
+	MsgInvalidScheduleID = "Invalid standing order id"
+
+	MsgOrderFailed    = "Failed to place limit order"
+	MsgOrderNotFound  = "Limit order not found"
+	MsgOrderNotOpen   = "Limit order is no longer open"
+	MsgInvalidOrderID = "Invalid limit order id"
+
+	MsgInvalidRefreshToken = "Invalid or expired refresh token"
+	MsgRefreshTokenReused  = "Refresh token was already used, all sessions of this login were revoked"
+
+	MsgInvalidIdempotencyKey = "Invalid idempotency key"
+	MsgIdempotencyKeyReused  = "Idempotency key was already used with a different request"
+	MsgIdempotencyInProgress = "Request with this idempotency key is still in progress"
+	MsgIdempotencyAbandoned  = "Request with this idempotency key did not finish, check its outcome and retry with a new key"
 )
@@ -0,0 +1,77 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// KeySize is the key length for AES-256.
+const KeySize = 32
+
+// Box encrypts small secrets for storage with AES-256-GCM. Sealed data is
+// the random nonce followed by the ciphertext.
+type Box struct {
+	aead cipher.AEAD
+}
+
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// NewFromBase64 creates a Box from a standard base64 encoded key.
+func NewFromBase64(key string) (*Box, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	return New(raw)
+}
+
+// Seal encrypts plaintext. The same associated data must be passed to Open,
+// which binds the ciphertext to its owner: a row copied to another owner
+// does not decrypt.
+func (b *Box) Seal(plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return b.aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func (b *Box) Open(sealed, associatedData []byte) ([]byte, error) {
+	size := b.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := b.aead.Open(nil, sealed[:size], sealed[size:], associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+var (
+	ErrInvalidKey = errors.New("encryption key must be 32 bytes, base64 encoded")
+	ErrDecrypt    = errors.New("failed to decrypt secret")
+)
@@ -0,0 +1,48 @@
+package secretbox_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"gw-currency-wallet/internal/pkg/secretbox"
+)
+
+func TestSealOpen(t *testing.T) {
+	box, err := secretbox.New(bytes.Repeat([]byte{7}, secretbox.KeySize))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	secret := []byte("12345678901234567890")
+	sealed, err := box.Seal(secret, []byte("user-1"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Fatal("sealed data contains the plaintext")
+	}
+
+	opened, err := box.Open(sealed, []byte("user-1"))
+	if err != nil || !bytes.Equal(opened, secret) {
+		t.Fatalf("Open = %q, %v", opened, err)
+	}
+
+	if _, err := box.Open(sealed, []byte("user-2")); !errors.Is(err, secretbox.ErrDecrypt) {
+		t.Errorf("open with other associated data: got %v, want ErrDecrypt", err)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := box.Open(sealed, []byte("user-1")); !errors.Is(err, secretbox.ErrDecrypt) {
+		t.Errorf("open tampered data: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestNewFromBase64(t *testing.T) {
+	if _, err := secretbox.NewFromBase64("c2hvcnQ="); !errors.Is(err, secretbox.ErrInvalidKey) {
+		t.Errorf("short key: got %v, want ErrInvalidKey", err)
+	}
+	if _, err := secretbox.NewFromBase64("not base64!"); !errors.Is(err, secretbox.ErrInvalidKey) {
+		t.Errorf("malformed key: got %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,68 @@
+package schedules
+
+import (
+	"context"
+	"gw-currency-wallet/internal/pkg/logger"
+	"time"
+)
+
+// Executor runs standing orders whose time has come.
+type Executor interface {
+	RunDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Runner periodically executes due standing orders. Several instances may
+// run at once: each order is claimed with a row lock before it is executed.
+type Runner struct {
+	executor  Executor
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRunner(executor Executor, interval time.Duration, batchSize int) *Runner {
+	return &Runner{
+		executor:  executor,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run executes due orders until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	logger.L.Info("Schedule runner started")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		for r.runDue(ctx) == r.batchSize {
+			// Полная пачка: вероятно, наступило время ещё поручений, продолжаем сразу.
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.L.Info("Schedule runner stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue executes one batch and returns the number of executed orders, or 0
+// if some of them failed: they would be selected again, so the runner waits
+// for the next tick instead of spinning on them. Orders held by another
+// instance are not counted for the same reason.
+func (r *Runner) runDue(ctx context.Context) int {
+	executed, err := r.executor.RunDue(ctx, time.Now(), r.batchSize)
+	if executed > 0 {
+		logger.L.Infow("Schedules run", "count", executed)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.L.Errorw("Failed to run schedules", "executed", executed, "error", err.Error())
+		}
+		return 0
+	}
+
+	return executed
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/mailer"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"gw-currency-wallet/internal/utils"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AccountService подтверждает почту пользователей и восстанавливает доступ
+// по одноразовым токенам, отправленным на почту.
+type AccountService struct {
+	userRepo  storages.UserStorage
+	tokenRepo storages.EmailTokenStorage
+	mailer    mailer.Mailer
+	baseURL   string
+	verifyTTL time.Duration
+	resetTTL  time.Duration
+}
+
+func NewAccountService(userRepo storages.UserStorage, tokenRepo storages.EmailTokenStorage, mailer mailer.Mailer, baseURL string, verifyTTL, resetTTL time.Duration) *AccountService {
+	return &AccountService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		baseURL:   baseURL,
+		verifyTTL: verifyTTL,
+		resetTTL:  resetTTL,
+	}
+}
+
+// SendVerificationEmail отправляет пользователю письмо со ссылкой
+// подтверждения почты. Ранее отправленные ссылки перестают действовать.
+func (s *AccountService) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.EmailVerification, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hello, %s!\n\nTo confirm your email, open the link below:\n%s\n\nThe link is valid for %s.\n",
+			user.Username, s.link("/verify-email", token), s.verifyTTL),
+	})
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return models.ErrInvalidEmailToken
+	}
+
+	_, err := s.tokenRepo.VerifyEmail(ctx, hashToken(token))
+	return err
+}
+
+// RequestPasswordReset отправляет письмо со ссылкой сброса пароля. Если
+// такой почты нет, ничего не происходит: по ответу нельзя узнать, зарегистрирован ли адрес.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.PasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello, %s!\n\nTo set a new password, open the link below:\n%s\n\n"+
+			"The link is valid for %s. If you did not request a reset, ignore this email.\n",
+			user.Username, s.link("/reset-password", token), s.resetTTL),
+	})
+}
+
+// ResetPassword устанавливает новый пароль по токену из письма
+// и завершает все сессии пользователя.
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	if token == "" {
+		return models.ErrInvalidEmailToken
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.tokenRepo.ResetPassword(ctx, hashToken(token), hash)
+	return err
+}
+
+func (s *AccountService) issueToken(ctx context.Context, userID uuid.UUID, purpose models.EmailTokenPurpose, ttl time.Duration) (string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.tokenRepo.CreateEmailToken(ctx, &models.EmailToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(secret),
+	}, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+var ErrEmailAlreadyVerified = fmt.Errorf("email is already verified")
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AdminService struct {
+	adminRepo  storages.AdminStorage
+	userRepo   storages.UserStorage
+	walletRepo storages.WalletStorage
+	limitRepo  storages.LimitStorage
+	feeRepo    storages.FeeStorage
+}
+
+func NewAdminService(adminRepo storages.AdminStorage, userRepo storages.UserStorage, walletRepo storages.WalletStorage, limitRepo storages.LimitStorage, feeRepo storages.FeeStorage) *AdminService {
+	return &AdminService{adminRepo: adminRepo, userRepo: userRepo, walletRepo: walletRepo, limitRepo: limitRepo, feeRepo: feeRepo}
+}
+
+// FindUser ищет пользователя по идентификатору или, если он не задан, по имени.
+func (s *AdminService) FindUser(ctx context.Context, userID uuid.UUID, username string) (*models.User, error) {
+	var (
+		user *models.User
+		err  error
+	)
+	if userID != uuid.Nil {
+		user, err = s.userRepo.GetUserByID(ctx, userID)
+	} else {
+		user, err = s.userRepo.GetUserByUsername(ctx, username)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetUserWallet возвращает основной кошелёк пользователя.
+func (s *AdminService) GetUserWallet(ctx context.Context, userID uuid.UUID) (*models.Wallet, error) {
+	wallet, err := s.walletRepo.GetWalletByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+
+	return wallet, err
+}
+
+// ListUserWallets возвращает все кошельки пользователя, включая закрытые.
+func (s *AdminService) ListUserWallets(ctx context.Context, userID uuid.UUID) ([]*models.Wallet, error) {
+	if _, err := s.FindUser(ctx, userID, ""); err != nil {
+		return nil, err
+	}
+
+	return s.walletRepo.ListWallets(ctx, userID)
+}
+
+// GetWallet возвращает любой кошелёк по его идентификатору.
+func (s *AdminService) GetWallet(ctx context.Context, walletID uuid.UUID) (*models.Wallet, error) {
+	return s.adminRepo.GetWallet(ctx, walletID)
+}
+
+// SetWalletFrozen замораживает кошелёк walletID или возвращает его
+// в активный статус от имени actorID.
+func (s *AdminService) SetWalletFrozen(ctx context.Context, actorID, walletID uuid.UUID, frozen bool, reason string) (*models.Wallet, error) {
+	if frozen {
+		return s.setWalletStatus(ctx, actorID, walletID, models.StatusFrozen, models.AuditWalletFreeze, reason)
+	}
+
+	return s.setWalletStatus(ctx, actorID, walletID, models.StatusActive, models.AuditWalletUnfreeze, reason)
+}
+
+// SetWalletStatus переводит кошелёк walletID в статус status от имени actorID.
+func (s *AdminService) SetWalletStatus(ctx context.Context, actorID, walletID uuid.UUID, status models.AccountStatus, reason string) (*models.Wallet, error) {
+	return s.setWalletStatus(ctx, actorID, walletID, status, models.AuditWalletStatus, reason)
+}
+
+func (s *AdminService) setWalletStatus(ctx context.Context, actorID, walletID uuid.UUID, status models.AccountStatus, action models.AuditAction, reason string) (*models.Wallet, error) {
+	if !status.Valid() {
+		return nil, models.ErrInvalidStatus
+	}
+
+	entry, err := newAuditEntry(actorID, action, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.adminRepo.SetWalletStatus(ctx, walletID, status, entry)
+}
+
+// SetAccountStatus переводит учётную запись пользователя в статус status.
+// Статус учётной записи действует на все её кошельки поверх их собственных.
+func (s *AdminService) SetAccountStatus(ctx context.Context, actorID, userID uuid.UUID, status models.AccountStatus, reason string) (*models.User, error) {
+	if !status.Valid() {
+		return nil, models.ErrInvalidStatus
+	}
+
+	entry, err := newAuditEntry(actorID, models.AuditAccountStatus, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.adminRepo.SetAccountStatus(ctx, userID, status, entry)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FindUser(ctx, userID, "")
+}
+
+func (s *AdminService) StatusHistory(ctx context.Context, userID uuid.UUID) ([]*models.StatusTransition, error) {
+	if _, err := s.FindUser(ctx, userID, ""); err != nil {
+		return nil, err
+	}
+
+	return s.adminRepo.ListStatusHistory(ctx, userID)
+}
+
+// AdjustBalance вносит ручную корректировку баланса кошелька walletID:
+// положительная delta зачисляет деньги, отрицательная списывает.
+func (s *AdminService) AdjustBalance(ctx context.Context, actorID, walletID uuid.UUID, delta models.Money, reason string) (*models.Wallet, error) {
+	entry, err := newAuditEntry(actorID, models.AuditBalanceAdjustment, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.adminRepo.AdjustWallet(ctx, walletID, delta, entry)
+}
+
+// SetUserRole назначает роль пользователю. Собственную роль менять нельзя,
+// чтобы последний администратор не лишил себя прав по ошибке.
+func (s *AdminService) SetUserRole(ctx context.Context, actorID, userID uuid.UUID, role models.Role, reason string) (*models.User, error) {
+	if !role.Valid() {
+		return nil, models.ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrSelfRoleChange
+	}
+
+	entry, err := newAuditEntry(actorID, models.AuditRoleChange, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.adminRepo.SetUserRole(ctx, userID, role, entry)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FindUser(ctx, userID, "")
+}
+
+// UnlockLogin снимает с пользователя блокировку входа после серии неудачных
+// попыток. Возвращает, был ли пользователь заблокирован.
+func (s *AdminService) UnlockLogin(ctx context.Context, actorID, userID uuid.UUID, reason string) (bool, error) {
+	entry, err := newAuditEntry(actorID, models.AuditLoginUnlock, reason)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := s.FindUser(ctx, userID, "")
+	if err != nil {
+		return false, err
+	}
+
+	return s.adminRepo.UnlockLogin(ctx, user.ID, user.Username, entry)
+}
+
+// ListLimits возвращает собственные лимиты пользователя или, если userID nil,
+// системные лимиты по умолчанию.
+func (s *AdminService) ListLimits(ctx context.Context, userID *uuid.UUID) ([]*models.SpendingLimit, error) {
+	if userID != nil {
+		if _, err := s.FindUser(ctx, *userID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.limitRepo.ListSpendingLimits(ctx, userID)
+}
+
+// SetLimit устанавливает лимит пользователя или, если userID nil, системный
+// лимит. Запрос без суммы удаляет лимит; тогда возвращается nil.
+func (s *AdminService) SetLimit(ctx context.Context, actorID uuid.UUID, userID *uuid.UUID, req models.SetLimitRequest) (*models.SpendingLimit, error) {
+	if !req.Operation.Valid() || !req.Period.Valid() {
+		return nil, models.ErrInvalidLimit
+	}
+	currency := models.Currency(req.Currency)
+	if err := currency.Validate(); err != nil {
+		return nil, err
+	}
+
+	entry, err := newAuditEntry(actorID, models.AuditLimitChange, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != nil {
+		if _, err := s.FindUser(ctx, *userID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Amount == nil {
+		return nil, s.adminRepo.RemoveSpendingLimit(ctx, userID, req.Operation, currency, req.Period, entry)
+	}
+
+	amount, err := models.NewMoney(*req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Amount < 0 {
+		return nil, models.ErrInvalidLimit
+	}
+
+	limit := &models.SpendingLimit{
+		UserID:    userID,
+		Operation: req.Operation,
+		Period:    req.Period,
+		Amount:    amount,
+	}
+	if err := s.adminRepo.SetSpendingLimit(ctx, limit, entry); err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
+
+func (s *AdminService) ListFeeRules(ctx context.Context) ([]*models.FeeRule, error) {
+	return s.feeRepo.ListFeeRules(ctx)
+}
+
+// SetFeeRule создаёт правило комиссии или заменяет правило той же операции,
+// пары валют и ступени оборота. Суммы правила задаются в валюте списания.
+func (s *AdminService) SetFeeRule(ctx context.Context, actorID uuid.UUID, req models.SetFeeRuleRequest) (*models.FeeRule, error) {
+	from := models.Currency(req.FromCurrency)
+	if err := from.Validate(); err != nil {
+		return nil, err
+	}
+	to := models.Currency(req.ToCurrency)
+	if to != "" {
+		if err := to.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := newAuditEntry(actorID, models.AuditFeeChange, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &models.FeeRule{
+		Operation:    req.Operation,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Percent:      req.Percent,
+	}
+	if rule.Fixed, err = models.NewMoney(req.Fixed, from); err != nil {
+		return nil, err
+	}
+	if rule.Min, err = models.NewMoney(req.Min, from); err != nil {
+		return nil, err
+	}
+	if rule.MinVolume, err = models.NewMoney(req.MinVolume, from); err != nil {
+		return nil, err
+	}
+	if req.Max != nil {
+		max, err := models.NewMoney(*req.Max, from)
+		if err != nil {
+			return nil, err
+		}
+		rule.Max = &max
+	}
+
+	if err := s.adminRepo.SetFeeRule(ctx, rule, entry); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *AdminService) DeleteFeeRule(ctx context.Context, actorID, ruleID uuid.UUID, reason string) error {
+	entry, err := newAuditEntry(actorID, models.AuditFeeChange, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.adminRepo.DeleteFeeRule(ctx, ruleID, entry)
+}
+
+func (s *AdminService) ListAudit(ctx context.Context, targetUserID *uuid.UUID, limit int) ([]*models.AuditEntry, error) {
+	if limit <= 0 || limit > maxAuditPageSize {
+		limit = maxAuditPageSize
+	}
+
+	return s.adminRepo.ListAuditEntries(ctx, models.AuditFilter{TargetUserID: targetUserID, Limit: limit})
+}
+
+func newAuditEntry(actorID uuid.UUID, action models.AuditAction, reason string) (*models.AuditEntry, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, models.ErrReasonRequired
+	}
+
+	return &models.AuditEntry{ActorID: actorID, Action: action, Reason: reason}, nil
+}
+
+const maxAuditPageSize = 100
+
+var (
+	ErrUserNotFound   = fmt.Errorf("user not found")
+	ErrSelfRoleChange = fmt.Errorf("cannot change your own role")
+)
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix отличает API-ключи от других секретов, например в логах
+// или при сканировании репозиториев на утечки.
+const apiKeyPrefix = "wk_"
+
+type APIKeyService struct {
+	repo storages.APIKeyStorage
+}
+
+func NewAPIKeyService(repo storages.APIKeyStorage) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create выпускает ключ. Сам ключ возвращается только здесь,
+// в базе хранится лишь его хэш.
+func (s *APIKeyService) Create(ctx context.Context, userID uuid.UUID, req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	scopes := make([]models.APIKeyScope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !scope.Valid() {
+			return nil, models.ErrInvalidAPIKeyScope
+		}
+		if !containsScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	allowedIPs := make([]string, 0, len(req.AllowedIPs))
+	for _, entry := range req.AllowedIPs {
+		normalized, err := normalizeAllowedIP(entry)
+		if err != nil {
+			return nil, err
+		}
+		allowedIPs = append(allowedIPs, normalized)
+	}
+
+	var ttl time.Duration
+	if req.ExpiresAt != nil {
+		if ttl = time.Until(*req.ExpiresAt); ttl <= 0 {
+			return nil, models.ErrInvalidAPIKeyTTL
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	raw := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     raw[:len(apiKeyPrefix)+6],
+		KeyHash:    hashToken(raw),
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, key, ttl); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: *key, Key: raw}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx, userID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.RevokeAPIKey(ctx, userID, id)
+}
+
+// Authenticate проверяет ключ, предъявленный с адреса ip, и отмечает его использование.
+func (s *APIKeyService) Authenticate(ctx context.Context, raw, ip string) (*models.APIKey, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetActiveAPIKey(ctx, hashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.AllowsIP(ip) {
+		return nil, models.ErrAPIKeyIPNotAllowed
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// normalizeAllowedIP приводит адрес или подсеть к каноническому виду,
+// например 10.0.0.7/8 к 10.0.0.0/8.
+func normalizeAllowedIP(entry string) (string, error) {
+	entry = strings.TrimSpace(entry)
+
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return network.String(), nil
+	}
+	if ip := net.ParseIP(entry); ip != nil {
+		return ip.String(), nil
+	}
+
+	return "", models.ErrInvalidAllowedIP
+}
+
+func containsScope(scopes []models.APIKeyScope, scope models.APIKeyScope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,11 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/storages"
@@ -9,35 +14,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type AuthService struct {
 	userRepo   storages.UserStorage
-	walletRepo storages.WalletStorage
+	wallets    *WalletService
+	tokenRepo  storages.TokenStorage
+	loginRepo  storages.LoginStorage
 	jwt        *JWTManager
+	refreshTTL time.Duration
+	policy     models.LoginPolicy
+	twoFactor  *TwoFactorService
+	uow        storages.UnitOfWork
 }
 
-func NewAuthService(userRepo storages.UserStorage, walletRepo storages.WalletStorage, jwt *JWTManager) *AuthService {
-	return &AuthService{userRepo: userRepo, walletRepo: walletRepo, jwt: jwt}
+func NewAuthService(userRepo storages.UserStorage, wallets *WalletService, tokenRepo storages.TokenStorage, loginRepo storages.LoginStorage, jwt *JWTManager, refreshTTL time.Duration, policy models.LoginPolicy, twoFactor *TwoFactorService, uow storages.UnitOfWork) *AuthService {
+	return &AuthService{
+		userRepo:   userRepo,
+		wallets:    wallets,
+		tokenRepo:  tokenRepo,
+		loginRepo:  loginRepo,
+		jwt:        jwt,
+		refreshTTL: refreshTTL,
+		policy:     policy,
+		twoFactor:  twoFactor,
+		uow:        uow,
+	}
 }
 
+// Register создаёт пользователя вместе с основным кошельком в одной
+// транзакции: пользователь без кошелька не появится даже при сбое.
 func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest) (uuid.UUID, error) {
-	exists, err := s.userRepo.ExistUserByUsername(ctx, req.Username)
-	if err != nil {
-		return uuid.Nil, err
-	}
-	if exists {
-		return uuid.Nil, ErrUserAlreadyExists
-	}
-
-	exists, err = s.userRepo.ExistUserByEmail(ctx, req.Email)
-	if err != nil {
-		return uuid.Nil, err
-	}
-	if exists {
-		return uuid.Nil, ErrEmailAlreadyExists
-	}
-
 	hash, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return uuid.Nil, err
@@ -51,30 +59,222 @@ func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest)
 		CreatedAt:    time.Now(),
 	}
 
-	err = s.userRepo.CreateUser(ctx, user)
-	if err != nil {
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		_, err := s.wallets.CreateWallet(ctx, user.ID)
+		return err
+	})
+	switch {
+	case errors.Is(err, models.ErrUsernameTaken):
+		return uuid.Nil, ErrUserAlreadyExists
+	case errors.Is(err, models.ErrEmailTaken):
+		return uuid.Nil, ErrEmailAlreadyExists
+	case err != nil:
 		return uuid.Nil, err
 	}
 
 	return user.ID, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (string, error) {
+// Login проверяет пароль и выдаёт пару токенов. Неудачные попытки считаются
+// по имени пользователя и по IP клиента; пока кто-то из них заблокирован,
+// пароль не проверяется и возвращается *models.LoginLockedError.
+// Если у пользователя включена двухфакторная аутентификация, вместо токенов
+// возвращается вызов, который завершается через LoginTwoFactor.
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest, ip string) (*models.TokenPair, *models.LoginChallenge, error) {
+	userSubject := models.LoginSubject{Scope: models.LoginScopeUsername, Value: req.Username}
+	subjects := []models.LoginSubject{userSubject}
+	if ip != "" {
+		subjects = append(subjects, models.LoginSubject{Scope: models.LoginScopeIP, Value: ip})
+	}
+
+	lockout, err := s.loginRepo.GetLoginLockout(ctx, subjects...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lockout != nil {
+		return nil, nil, &models.LoginLockedError{RetryAfter: lockout.RetryAfter}
+	}
+
 	user, err := s.userRepo.GetUserByUsername(ctx, req.Username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, s.loginFailed(ctx, subjects, uuid.Nil)
+	}
 	if err != nil {
-		return "", ErrInvalidCredentials
+		return nil, nil, err
 	}
 
 	if !utils.CheckPassword(user.PasswordHash, req.Password) {
-		return "", ErrInvalidCredentials
+		return nil, nil, s.loginFailed(ctx, subjects, user.ID)
+	}
+
+	if err := s.loginRepo.ResetLoginFailures(ctx, userSubject); err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := s.twoFactor.StartChallenge(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge != nil {
+		return nil, challenge, nil
+	}
+
+	pair, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, nil, nil
+}
+
+// LoginTwoFactor завершает вход кодом из приложения или кодом восстановления.
+func (s *AuthService) LoginTwoFactor(ctx context.Context, challengeToken, code string) (*models.TokenPair, error) {
+	userID, err := s.twoFactor.CompleteChallenge(ctx, challengeToken, code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// issueTokens открывает новую сессию пользователя.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	claims := s.jwt.NewClaims(user.ID.String(), user.Role)
+
+	refresh, token, err := s.newRefreshToken(user.ID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.CreateRefreshToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(claims, refresh)
+}
+
+// loginFailed учитывает неудачную попытку по всем subjects. Если попытка
+// привела к блокировке, возвращается *models.LoginLockedError, иначе ErrInvalidCredentials.
+func (s *AuthService) loginFailed(ctx context.Context, subjects []models.LoginSubject, userID uuid.UUID) error {
+	var retryAfter time.Duration
+	for _, subject := range subjects {
+		rule := s.policy.Username
+		if subject.Scope == models.LoginScopeIP {
+			rule = s.policy.IP
+		}
+
+		lockout, err := s.loginRepo.RecordLoginFailure(ctx, subject, rule, userID)
+		if err != nil {
+			return err
+		}
+		if lockout != nil && lockout.RetryAfter > retryAfter {
+			retryAfter = lockout.RetryAfter
+		}
+	}
+
+	if retryAfter > 0 {
+		return &models.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return ErrInvalidCredentials
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Предъявленный
+// токен после этого недействителен, а его повторное использование
+// отзывает всю сессию.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	// Владелец становится известен только после поиска токена: репозиторий
+	// проставляет его в next, и лишь затем подписывается access-токен.
+	claims := s.jwt.NewClaims("", "")
+
+	refresh, next, err := s.newRefreshToken(uuid.Nil, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.RotateRefreshToken(ctx, hashToken(refreshToken), next); err != nil {
+		return nil, err
 	}
 
-	token, err := s.jwt.Generate(user.ID.String())
+	// Роль читается заново: её могли изменить с момента входа.
+	user, err := s.userRepo.GetUserByID(ctx, next.UserID)
 	if err != nil {
+		return nil, err
+	}
+	claims.UserID, claims.Role = user.ID.String(), user.Role
+
+	return s.tokenPair(claims, refresh)
+}
+
+// Logout завершает сессию, к которой относится access-токен claims.
+func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, claims *models.AccessClaims) error {
+	return s.tokenRepo.RevokeSession(ctx, userID, claims.JTI, claims.ExpiresAt)
+}
+
+// LogoutAll завершает все сессии пользователя.
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID, claims *models.AccessClaims) error {
+	return s.tokenRepo.RevokeAllSessions(ctx, userID, claims.JTI, claims.ExpiresAt)
+}
+
+// newRefreshToken создаёт refresh-токен, парный access-токену claims.
+// Клиенту отдаётся сам токен, в базе хранится только его хэш.
+func (s *AuthService) newRefreshToken(userID uuid.UUID, claims *models.AccessClaims) (string, *models.RefreshToken, error) {
+	refresh, err := newSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := &models.RefreshToken{
+		ID:              uuid.New(),
+		UserID:          userID,
+		TokenHash:       hashToken(refresh),
+		AccessJTI:       claims.JTI,
+		AccessExpiresAt: claims.ExpiresAt,
+		ExpiresAt:       time.Now().Add(s.refreshTTL),
+	}
+
+	return refresh, token, nil
+}
+
+func (s *AuthService) tokenPair(claims *models.AccessClaims, refresh string) (*models.TokenPair, error) {
+	access, err := s.jwt.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresAt:    claims.ExpiresAt,
+	}, nil
+}
+
+// newSecret возвращает случайный токен для передачи клиенту.
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
 		return "", err
 	}
 
-	return token, nil
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 var (
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+)
+
+type FeeService struct {
+	repo          storages.FeeStorage
+	houseWalletID uuid.UUID
+}
+
+func NewFeeService(repo storages.FeeStorage, houseWalletID uuid.UUID) *FeeService {
+	return &FeeService{repo: repo, houseWalletID: houseWalletID}
+}
+
+// Charge рассчитывает комиссию за операцию operation на сумму amount
+// с кошелька walletID. to — валюта покупки при обмене. Ступень выбирается
+// по обороту пользователя за текущий месяц без учёта этой операции.
+// Операции кошелька заведения, как и операции без подходящего правила,
+// бесплатны; с nil-сервисом комиссии не взимаются вовсе.
+func (s *FeeService) Charge(ctx context.Context, userID, walletID uuid.UUID, operation models.FeeOperation, amount models.Money, to models.Currency) (models.FeeCharge, error) {
+	charge := models.FeeCharge{Amount: models.Money{Currency: amount.Currency}}
+	if s == nil || walletID == s.houseWalletID {
+		return charge, nil
+	}
+
+	rules, err := s.repo.FindFeeRules(ctx, operation, amount.Currency)
+	if err != nil || len(rules) == 0 {
+		return charge, err
+	}
+
+	volume, err := s.repo.MonthlyVolume(ctx, userID, operation, amount.Currency)
+	if err != nil {
+		return charge, err
+	}
+
+	rule := models.MatchFeeRule(rules, to, volume)
+	if rule == nil {
+		return charge, nil
+	}
+
+	charge.Amount, err = rule.Compute(amount)
+	return charge, err
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldService struct {
+	walletRepo storages.WalletStorage
+	fees       *FeeService
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewHoldService(walletRepo storages.WalletStorage, fees *FeeService, defaultTTL, maxTTL time.Duration) *HoldService {
+	return &HoldService{walletRepo: walletRepo, fees: fees, defaultTTL: defaultTTL, maxTTL: maxTTL}
+}
+
+// CreateHold резервирует amount на кошельке пользователя. Если ttl не задан,
+// используется срок по умолчанию.
+func (s *HoldService) CreateHold(ctx context.Context, userID uuid.UUID, amount models.Money, ttl time.Duration) (*models.Hold, error) {
+	switch {
+	case ttl == 0:
+		ttl = s.defaultTTL
+	case ttl < 0 || ttl > s.maxTTL:
+		return nil, models.ErrInvalidHoldTTL
+	}
+
+	wallet, err := s.walletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		UserID:   userID,
+		WalletID: wallet.ID,
+		Amount:   amount,
+	}
+
+	if err := s.walletRepo.CreateHold(ctx, hold, ttl); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (s *HoldService) GetHold(ctx context.Context, userID, holdID uuid.UUID) (*models.Hold, error) {
+	return s.walletRepo.GetHold(ctx, holdID, userID)
+}
+
+// CaptureAmount возвращает сумму, которую спишет CaptureHold с теми же аргументами.
+func (s *HoldService) CaptureAmount(ctx context.Context, userID, holdID uuid.UUID, amount *models.Decimal) (models.Money, error) {
+	hold, err := s.walletRepo.GetHold(ctx, holdID, userID)
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	return captureAmount(hold, amount)
+}
+
+// CaptureHold списывает amount в валюте холда. Если amount равен nil,
+// списывается весь оставшийся резерв. Списание холда — это вывод, поэтому
+// комиссия за вывод списывается сверх amount из доступного баланса.
+func (s *HoldService) CaptureHold(ctx context.Context, userID, holdID uuid.UUID, amount *models.Decimal) (*models.Hold, error) {
+	hold, err := s.walletRepo.GetHold(ctx, holdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	capture, err := captureAmount(hold, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := s.fees.Charge(ctx, userID, hold.WalletID, models.FeeWithdraw, capture, "")
+	if err != nil {
+		return nil, err
+	}
+
+	evt := largeOperationEvent(models.Withdraw, capture, string(capture.Currency))
+	if evt != nil {
+		evt.Details = fmt.Sprintf("hold_id=%s", hold.ID)
+	}
+
+	return s.walletRepo.CaptureHold(ctx, holdID, userID, capture, fee, evt)
+}
+
+func (s *HoldService) VoidHold(ctx context.Context, userID, holdID uuid.UUID) (*models.Hold, error) {
+	return s.walletRepo.VoidHold(ctx, holdID, userID)
+}
+
+// ExpireHolds освобождает не более limit истёкших холдов.
+func (s *HoldService) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	return s.walletRepo.ExpireHolds(ctx, limit)
+}
+
+func captureAmount(hold *models.Hold, amount *models.Decimal) (models.Money, error) {
+	if hold.OrderID != nil {
+		return models.Money{}, models.ErrHoldOwnedByOrder
+	}
+	if amount == nil {
+		return hold.Remaining(), nil
+	}
+
+	return models.NewMoney(*amount, hold.Amount.Currency)
+}
@@ -3,47 +3,133 @@ package services
 import (
 	"time"
 
+	"gw-currency-wallet/internal/jwks"
+	"gw-currency-wallet/internal/models"
+
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type JWTManager struct {
-	secretKey string
+	keys      *jwks.KeySet
+	verifier  *JWTVerifier
+	accessTTL time.Duration
+}
+
+func NewJWTManager(keys *jwks.KeySet, verifier *JWTVerifier, accessTTL time.Duration) *JWTManager {
+	return &JWTManager{keys: keys, verifier: verifier, accessTTL: accessTTL}
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
-	return &JWTManager{secretKey: secretKey}
+// Generate issues an access token for userID. The returned claims carry
+// the token's jti and expiry so the token can later be revoked.
+func (j JWTManager) Generate(userID string, role models.Role) (string, *models.AccessClaims, error) {
+	claims := j.NewClaims(userID, role)
+
+	token, err := j.Sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, claims, nil
 }
 
-func (j JWTManager) Generate(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+// NewClaims returns claims of a fresh access token with a new jti.
+func (j JWTManager) NewClaims(userID string, role models.Role) *models.AccessClaims {
+	return &models.AccessClaims{
+		UserID:    userID,
+		Role:      role,
+		JTI:       uuid.New(),
+		ExpiresAt: time.Now().Add(j.accessTTL),
 	}
+}
+
+// Sign signs claims with the current signing key and names it in the kid header.
+func (j JWTManager) Sign(claims *models.AccessClaims) (string, error) {
+	key := j.keys.SigningKey()
+
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"user_id": claims.UserID,
+		"role":    string(claims.Role),
+		"jti":     claims.JTI.String(),
+		"exp":     claims.ExpiresAt.Unix(),
+	})
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+func (j JWTManager) ParseToken(tokenStr string) (*models.AccessClaims, error) {
+	return j.verifier.Verify(tokenStr)
+}
+
+// JWTVerifier checks access tokens against the public keys of a key set.
+// It is shared by JWTManager and the JWT middleware.
+type JWTVerifier struct {
+	keys *jwks.KeySet
+}
 
-	return token.SignedString([]byte(j.secretKey))
+func NewJWTVerifier(keys *jwks.KeySet) *JWTVerifier {
+	return &JWTVerifier{keys: keys}
 }
 
-func (j JWTManager) ParseToken(tokenStr string) (string, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// Verify validates an access token and extracts its claims. The key is
+// chosen by the kid header and must match the token's algorithm, so a token
+// cannot switch to HMAC or "none". Tokens without a jti are rejected: they
+// could never be revoked.
+func (v *JWTVerifier) Verify(tokenStr string) (*models.AccessClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+
+	token, err := parser.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, err := v.keys.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, jwt.ErrSignatureInvalid
 		}
 
-		return []byte(j.secretKey), nil
+		return key.Public(), nil
 	})
 
-	if err != nil || !token.Valid {
-		return "", err
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", jwt.ErrInvalidKey
+		return nil, jwt.ErrInvalidKey
 	}
 
 	userID, _ := claims["user_id"].(string)
+	jtiStr, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	jti, err := uuid.Parse(jtiStr)
+	if err != nil || userID == "" || exp == 0 {
+		return nil, jwt.ErrInvalidKey
+	}
+
+	// Токены, выпущенные до появления ролей, не содержат claim role.
+	role := models.RoleUser
+	if v, ok := claims["role"].(string); ok {
+		role = models.Role(v)
+	}
+	if !role.Valid() {
+		return nil, models.ErrInvalidRole
+	}
 
-	return userID, nil
+	return &models.AccessClaims{
+		UserID:    userID,
+		Role:      role,
+		JTI:       jti,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}, nil
 }
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+)
+
+type LedgerService struct {
+	ledgerRepo storages.LedgerStorage
+}
+
+func NewLedgerService(ledgerRepo storages.LedgerStorage) *LedgerService {
+	return &LedgerService{ledgerRepo: ledgerRepo}
+}
+
+// Verify пересчитывает балансы по журналу и проверяет, что все проводки сбалансированы.
+func (s *LedgerService) Verify(ctx context.Context) (*models.LedgerReport, error) {
+	unbalanced, err := s.ledgerRepo.FindUnbalancedEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drift, err := s.ledgerRepo.FindBalanceDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LedgerReport{Unbalanced: unbalanced, Drift: drift}, nil
+}
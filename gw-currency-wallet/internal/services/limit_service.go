@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+)
+
+type LimitService struct {
+	repo storages.LimitStorage
+}
+
+func NewLimitService(repo storages.LimitStorage) *LimitService {
+	return &LimitService{repo: repo}
+}
+
+// GetLimits возвращает действующие лимиты пользователя и остаток по каждому
+// в текущем периоде.
+func (s *LimitService) GetLimits(ctx context.Context, userID uuid.UUID) ([]*models.LimitUsage, error) {
+	return s.repo.ListLimitUsage(ctx, userID)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// orderFailures — ошибки, с которыми заявка закрывается как неудачная.
+// Остальные ошибки (БД недоступна, взаимоблокировка) считаются временными.
+var orderFailures = []error{
+	models.ErrInsufficientFunds,
+	models.ErrLimitExceeded,
+	models.ErrWalletFrozen,
+	models.ErrWalletDebitBlocked,
+	models.ErrWalletClosed,
+	models.ErrInvalidAmount,
+	models.ErrTooPrecise,
+	models.ErrUnsupportedCurrency,
+	models.ErrCurrencyMismatch,
+	models.ErrInvalidRate,
+	models.ErrHoldNotActive,
+	models.ErrHoldExpired,
+}
+
+type OrderService struct {
+	repo       storages.OrderStorage
+	walletRepo storages.WalletStorage
+	fees       *FeeService
+	uow        storages.UnitOfWork
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewOrderService(repo storages.OrderStorage, walletRepo storages.WalletStorage, fees *FeeService, uow storages.UnitOfWork, defaultTTL, maxTTL time.Duration) *OrderService {
+	return &OrderService{
+		repo:       repo,
+		walletRepo: walletRepo,
+		fees:       fees,
+		uow:        uow,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+	}
+}
+
+// PlaceOrder выставляет заявку на обмен amount в валюту to, когда курс
+// достигнет targetRate. Сумма вместе с комиссией резервируется холдом на
+// срок действия заявки; комиссия фиксируется при выставлении, как в котировке.
+// Если walletID не задан, используется основной кошелёк, если ttl не задан —
+// срок по умолчанию.
+func (s *OrderService) PlaceOrder(ctx context.Context, userID, walletID uuid.UUID, amount models.Money, to models.Currency, targetRate models.Decimal, ttl time.Duration) (*models.LimitOrder, error) {
+	switch {
+	case ttl == 0:
+		ttl = s.defaultTTL
+	case ttl < 0 || ttl > s.maxTTL:
+		return nil, models.ErrInvalidOrderTTL
+	}
+
+	order := &models.LimitOrder{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Amount:     amount,
+		ToCurrency: to,
+		TargetRate: targetRate,
+		Status:     models.OrderOpen,
+	}
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.userWallet(ctx, userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+	order.WalletID = wallet.ID
+
+	fee, err := s.fees.Charge(ctx, userID, wallet.ID, models.FeeExchange, amount, to)
+	if err != nil {
+		return nil, err
+	}
+	order.Fee = fee.Amount
+
+	reserve, err := order.Reserve()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		hold := &models.Hold{
+			UserID:   userID,
+			WalletID: wallet.ID,
+			OrderID:  &order.ID,
+			Amount:   reserve,
+		}
+		if err := s.walletRepo.CreateHold(ctx, hold, ttl); err != nil {
+			return err
+		}
+
+		order.HoldID = hold.ID
+		order.ExpiresAt = hold.ExpiresAt
+		return s.repo.CreateOrder(ctx, order, models.NewOrderEvent(order))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListOrders возвращает заявки пользователя; пустой status означает все.
+func (s *OrderService) ListOrders(ctx context.Context, userID uuid.UUID, status models.OrderStatus) ([]*models.LimitOrder, error) {
+	if status != "" && !status.Valid() {
+		return nil, fmt.Errorf("%w: unknown status %q", models.ErrInvalidOrder, status)
+	}
+
+	return s.repo.ListOrders(ctx, userID, status)
+}
+
+func (s *OrderService) GetOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error) {
+	return s.repo.GetOrder(ctx, userID, id)
+}
+
+// CancelOrder отменяет открытую заявку и возвращает резерв в доступный
+// баланс. Заявку с истёкшим сроком отменить нельзя: её закрывает ExpireOrders.
+func (s *OrderService) CancelOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error) {
+	var order *models.LimitOrder
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.repo.LockOrder(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.OrderOpen {
+			return models.ErrOrderNotOpen
+		}
+
+		// Холд мог уже освободить ExpireHolds, заявку это не держит.
+		_, err = s.walletRepo.VoidOrderHold(ctx, order.HoldID, userID, order.ID)
+		switch {
+		case errors.Is(err, models.ErrHoldExpired):
+			return models.ErrOrderNotOpen
+		case err != nil && !errors.Is(err, models.ErrHoldNotActive):
+			return err
+		}
+
+		order.Status = models.OrderCancelled
+		return s.repo.FinishOrder(ctx, order, models.NewOrderEvent(order))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ExpireOrders закрывает не более limit заявок с истёкшим сроком.
+func (s *OrderService) ExpireOrders(ctx context.Context, limit int) (int, error) {
+	return s.repo.ExpireOrders(ctx, limit)
+}
+
+// FillOrders исполняет до limit заявок from->to, для которых курс rate
+// достиг целевого, и возвращает число заявок, закрытых этим вызовом. Заявки,
+// которые исполняет другой экземпляр сервиса, пропускаются и не считаются.
+// Ошибка одной заявки не мешает остальным; отклонённый обмен ошибкой не
+// считается, заявка закрывается со статусом failed.
+func (s *OrderService) FillOrders(ctx context.Context, from, to models.Currency, rate models.Decimal, limit int) (int, error) {
+	orders, err := s.repo.ListTriggeredOrders(ctx, from, to, rate, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	var errs []error
+	for _, order := range orders {
+		ok, err := s.fill(ctx, order.ID, rate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", order.ID, err))
+		}
+		if ok {
+			claimed++
+		}
+	}
+
+	return claimed, errors.Join(errs...)
+}
+
+// fill исполняет заявку id по курсу rate и сообщает, была ли она закрыта.
+// Снятие холда, обмен и закрытие заявки выполняются в одной транзакции.
+// Если обмен отклонён с ошибкой из orderFailures, откатывается только он:
+// резерв освобождается, а заявка закрывается со статусом failed. При прочих
+// ошибках откатывается вся транзакция, и заявка исполняется при следующем
+// запуске.
+func (s *OrderService) fill(ctx context.Context, id uuid.UUID, rate models.Decimal) (bool, error) {
+	closed := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		order, err := s.repo.ClaimOrder(ctx, id, rate)
+		if err != nil || order == nil {
+			return err
+		}
+
+		if err := s.exchange(ctx, order, rate); err != nil {
+			if !isOrderFailure(err) {
+				return err
+			}
+			order.Status = models.OrderFailed
+			order.Error = err.Error()
+		} else {
+			order.Status = models.OrderFilled
+		}
+
+		if err := s.repo.FinishOrder(ctx, order, models.NewOrderEvent(order)); err != nil {
+			return err
+		}
+
+		closed = true
+		return nil
+	})
+
+	return closed && err == nil, err
+}
+
+func (s *OrderService) exchange(ctx context.Context, order *models.LimitOrder, rate models.Decimal) error {
+	if _, err := s.walletRepo.VoidOrderHold(ctx, order.HoldID, order.UserID, order.ID); err != nil {
+		return err
+	}
+
+	converted, err := order.Amount.Convert(order.ToCurrency, rate, models.RoundDown)
+	if err != nil {
+		return err
+	}
+
+	fee := models.FeeCharge{Amount: order.Fee}
+
+	evt := largeOperationEvent(models.Exchange, order.Amount, fmt.Sprintf("%s->%s", order.Amount.Currency, order.ToCurrency))
+	if evt != nil {
+		evt.Details = fmt.Sprintf("order_id=%s", order.ID)
+	}
+
+	if _, err := s.walletRepo.ExchangeWallet(ctx, order.WalletID, order.Amount, converted, fee, rate, uuid.Nil, evt); err != nil {
+		return err
+	}
+
+	order.FilledRate = &rate
+	order.Converted = &converted
+	return nil
+}
+
+func isOrderFailure(err error) bool {
+	for _, failure := range orderFailures {
+		if errors.Is(err, failure) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// userWallet выбирает кошелёк так же, как WalletService.userWallet.
+func (s *OrderService) userWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	if walletID == uuid.Nil {
+		return s.walletRepo.GetWalletByUserID(ctx, userID)
+	}
+
+	return s.walletRepo.GetWallet(ctx, userID, walletID)
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/cron"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxScheduleRunsPageSize = 100
+
+// scheduleFailures — ошибки, при которых исполнение поручения записывается
+// как неудачное. Остальные ошибки (обменник или БД недоступны) считаются
+// временными: исполнение откатывается и повторяется при следующем запуске,
+// не приближая приостановку поручения.
+var scheduleFailures = []error{
+	models.ErrInsufficientFunds,
+	models.ErrLimitExceeded,
+	models.ErrWalletFrozen,
+	models.ErrWalletDebitBlocked,
+	models.ErrWalletClosed,
+	models.ErrWalletNotFound,
+	models.ErrUnsupportedCurrency,
+	models.ErrInvalidAmount,
+	models.ErrTooPrecise,
+	models.ErrCurrencyMismatch,
+	models.ErrSameCurrency,
+	models.ErrSelfTransfer,
+	models.ErrInvalidRate,
+	models.ErrInvalidSchedule,
+}
+
+type ScheduleService struct {
+	repo         storages.ScheduleStorage
+	wallets      *WalletService
+	uow          storages.UnitOfWork
+	maxSchedules int
+	maxFailures  int
+}
+
+func NewScheduleService(repo storages.ScheduleStorage, wallets *WalletService, uow storages.UnitOfWork, maxSchedules, maxFailures int) *ScheduleService {
+	return &ScheduleService{
+		repo:         repo,
+		wallets:      wallets,
+		uow:          uow,
+		maxSchedules: maxSchedules,
+		maxFailures:  maxFailures,
+	}
+}
+
+// CreateSchedule сохраняет постоянное поручение пользователя. Если кошелёк
+// списания не задан, используется основной. Первое исполнение — ближайшее
+// время по расписанию.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, schedule *models.ScheduledTransfer) error {
+	if schedule.WalletID == uuid.Nil {
+		wallet, err := s.wallets.GetWalletByUserID(ctx, schedule.UserID)
+		if err != nil {
+			return err
+		}
+		schedule.WalletID = wallet.ID
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	for _, walletID := range []*uuid.UUID{&schedule.WalletID, schedule.ToWalletID} {
+		if walletID == nil {
+			continue
+		}
+		wallet, err := s.wallets.GetWallet(ctx, schedule.UserID, *walletID)
+		if err != nil {
+			return err
+		}
+		if wallet.Status == models.StatusClosed {
+			return models.ErrWalletClosed
+		}
+	}
+
+	next, err := nextRun(schedule.Cron, time.Now())
+	if err != nil {
+		return err
+	}
+
+	schedule.ID = uuid.New()
+	schedule.Status = models.ScheduleActive
+	schedule.NextRunAt = next
+
+	return s.repo.CreateSchedule(ctx, schedule, s.maxSchedules)
+}
+
+func (s *ScheduleService) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error) {
+	return s.repo.ListSchedules(ctx, userID)
+}
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, userID, id uuid.UUID) (*models.ScheduledTransfer, error) {
+	return s.repo.GetSchedule(ctx, userID, id)
+}
+
+// UpdateSchedule меняет сумму, расписание или статус поручения. Изменение
+// расписания и возобновление переносят следующее исполнение на ближайшее
+// время по расписанию; возобновление также сбрасывает счётчик неудач.
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, userID, id uuid.UUID, req models.UpdateScheduleRequest) (*models.ScheduledTransfer, error) {
+	var schedule *models.ScheduledTransfer
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		schedule, err = s.repo.LockSchedule(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		reschedule := false
+		if req.Amount != nil {
+			if schedule.Amount, err = models.NewMoney(*req.Amount, schedule.Amount.Currency); err != nil {
+				return err
+			}
+		}
+		if req.Cron != nil {
+			schedule.Cron = *req.Cron
+			reschedule = true
+		}
+		if req.Status != nil {
+			if !req.Status.Valid() {
+				return fmt.Errorf("%w: unknown status %q", models.ErrInvalidSchedule, *req.Status)
+			}
+			if *req.Status == models.ScheduleActive && schedule.Status == models.SchedulePaused {
+				schedule.Failures = 0
+				reschedule = true
+			}
+			schedule.Status = *req.Status
+		}
+
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+		if reschedule {
+			if schedule.NextRunAt, err = nextRun(schedule.Cron, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		return s.repo.UpdateSchedule(ctx, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.DeleteSchedule(ctx, userID, id)
+}
+
+// ListRuns возвращает историю исполнений поручения пользователя, начиная с новых.
+func (s *ScheduleService) ListRuns(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.ScheduleRun, error) {
+	if _, err := s.repo.GetSchedule(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxScheduleRunsPageSize {
+		limit = maxScheduleRunsPageSize
+	}
+
+	return s.repo.ListScheduleRuns(ctx, id, limit)
+}
+
+// RunDue исполняет до limit поручений, время которых наступило к now, и
+// возвращает число исполненных этим вызовом. Поручения, которые исполняет
+// другой экземпляр сервиса, пропускаются и не считаются. Ошибка одного
+// поручения не мешает остальным; неудачные операции ошибкой не считаются,
+// они записываются в историю исполнений.
+func (s *ScheduleService) RunDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	now = now.UTC()
+
+	due, err := s.repo.ListDueSchedules(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	var errs []error
+	for _, schedule := range due {
+		ok, err := s.run(ctx, schedule, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", schedule.ID, err))
+		}
+		if ok {
+			executed++
+		}
+	}
+
+	return executed, errors.Join(errs...)
+}
+
+// run исполняет поручение due, запланированное на due.NextRunAt. Операция,
+// запись исполнения и перенос поручения выполняются в одной транзакции,
+// поэтому после перезапуска исполнение не повторится. Курс для обмена
+// запрашивается до начала транзакции, чтобы ожидание обменника не держало
+// блокировку поручения. Операция, отклонённая с ошибкой из scheduleFailures,
+// откатывается, а исполнение записывается как неудачное; после maxFailures
+// неудач подряд поручение приостанавливается. Пропущенные за время простоя
+// исполнения не наверстываются: следующее время считается от now.
+// Возвращает, было ли исполнение записано.
+func (s *ScheduleService) run(ctx context.Context, due *models.ScheduledTransfer, now time.Time) (bool, error) {
+	var rate models.Decimal
+	if due.Kind == models.ScheduleExchange {
+		release := s.wallets.gate()
+		var err error
+		rate, err = s.wallets.getRate(ctx, due.Amount.Currency, due.ToCurrency)
+		release()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	scheduledFor := due.NextRunAt
+	recorded := false
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		schedule, err := s.repo.ClaimScheduleRun(ctx, due.ID, scheduledFor)
+		if err != nil || schedule == nil {
+			return err
+		}
+
+		run := &models.ScheduleRun{
+			ID:           models.ExecutionID(schedule.ID, scheduledFor),
+			ScheduleID:   schedule.ID,
+			ScheduledFor: scheduledFor,
+			Status:       models.ScheduleRunSucceeded,
+		}
+
+		if err := s.execute(ctx, schedule, rate); err != nil {
+			if !isScheduleFailure(err) {
+				return err
+			}
+
+			run.Status = models.ScheduleRunFailed
+			run.Error = err.Error()
+			schedule.Failures++
+			if schedule.Failures >= s.maxFailures {
+				schedule.Status = models.SchedulePaused
+			}
+		} else {
+			schedule.Failures = 0
+		}
+
+		schedule.LastRunAt = &now
+		next, err := nextRun(schedule.Cron, now)
+		if err != nil {
+			schedule.Status = models.SchedulePaused
+		} else {
+			schedule.NextRunAt = next
+		}
+
+		if err := s.repo.FinishScheduleRun(ctx, schedule, run); err != nil {
+			return err
+		}
+
+		recorded = true
+		return nil
+	})
+
+	return recorded && err == nil, err
+}
+
+// execute выполняет операцию поручения; обмен проводится по курсу rate.
+func (s *ScheduleService) execute(ctx context.Context, schedule *models.ScheduledTransfer, rate models.Decimal) error {
+	switch schedule.Kind {
+	case models.ScheduleTransfer:
+		_, err := s.wallets.TransferBetween(ctx, schedule.UserID, schedule.WalletID, *schedule.ToWalletID, schedule.Amount)
+		return err
+	case models.ScheduleExchange:
+		_, _, err := s.wallets.exchangeAtRate(ctx, schedule.UserID, schedule.WalletID, schedule.Amount, schedule.ToCurrency, rate)
+		return err
+	default:
+		return models.ErrInvalidSchedule
+	}
+}
+
+func isScheduleFailure(err error) bool {
+	for _, failure := range scheduleFailures {
+		if errors.Is(err, failure) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextRun возвращает ближайшее после after время по расписанию expr в UTC.
+func nextRun(expr string, after time.Time) (time.Time, error) {
+	spec, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", models.ErrInvalidSchedule, err)
+	}
+
+	next := spec.Next(after.UTC())
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: cron expression %q never matches", models.ErrInvalidSchedule, expr)
+	}
+
+	return next, nil
+}
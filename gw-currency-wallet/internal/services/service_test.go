@@ -2,7 +2,11 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,9 +15,11 @@ import (
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/pkg/logger"
 	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/storages"
 	"gw-currency-wallet/internal/storages/postgres"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 func setupTestDB(t *testing.T) *postgres.PostgresDB {
@@ -26,15 +32,213 @@ func setupTestDB(t *testing.T) *postgres.PostgresDB {
 	}
 
 	_, err = db.Exec(context.Background(), `
+        DROP TABLE IF EXISTS wallet_balances;
+        DROP TABLE IF EXISTS currencies;
         DROP TABLE IF EXISTS wallets;
         CREATE TABLE wallets (
             id UUID PRIMARY KEY,
             user_id UUID NOT NULL,
-            rub NUMERIC DEFAULT 0,
-            usd NUMERIC DEFAULT 0,
-            eur NUMERIC DEFAULT 0,
+            name VARCHAR(64) NOT NULL DEFAULT 'main',
+            is_default BOOLEAN NOT NULL DEFAULT FALSE,
+            status VARCHAR(16) NOT NULL DEFAULT 'active',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
             updated_at TIMESTAMPTZ DEFAULT now()
         );
+        CREATE TABLE currencies (
+            code VARCHAR(10) PRIMARY KEY,
+            minor_units SMALLINT NOT NULL DEFAULT 2,
+            symbol VARCHAR(8) NOT NULL DEFAULT '',
+            enabled BOOLEAN NOT NULL DEFAULT TRUE,
+            created_at TIMESTAMP DEFAULT NOW()
+        );
+        INSERT INTO currencies (code) VALUES ('USD'), ('RUB'), ('EUR');
+        CREATE TABLE wallet_balances (
+            wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+            currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+            amount_minor BIGINT NOT NULL DEFAULT 0 CHECK (amount_minor >= 0),
+            held_minor BIGINT NOT NULL DEFAULT 0 CHECK (held_minor >= 0 AND held_minor <= amount_minor),
+            updated_at TIMESTAMP DEFAULT NOW(),
+            PRIMARY KEY (wallet_id, currency)
+        );
+        DROP TABLE IF EXISTS transactions;
+        CREATE TABLE transactions (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            wallet_id UUID NOT NULL,
+            type VARCHAR(20) NOT NULL,
+            from_currency VARCHAR(10) NOT NULL,
+            to_currency VARCHAR(10),
+            amount BIGINT NOT NULL,
+            to_amount BIGINT,
+            rate NUMERIC(20, 10),
+            counterparty_user_id UUID,
+            fee BIGINT NOT NULL DEFAULT 0,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS exchange_quotes;
+        CREATE TABLE exchange_quotes (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            wallet_id UUID NOT NULL,
+            from_currency VARCHAR(10) NOT NULL,
+            to_currency VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL,
+            to_amount BIGINT NOT NULL,
+            rate NUMERIC NOT NULL,
+            fee BIGINT NOT NULL DEFAULT 0,
+            expires_at TIMESTAMP NOT NULL,
+            used_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS holds;
+        CREATE TABLE holds (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            wallet_id UUID NOT NULL,
+            currency VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL,
+            captured BIGINT NOT NULL DEFAULT 0,
+            status VARCHAR(20) NOT NULL DEFAULT 'active',
+            expires_at TIMESTAMP NOT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            order_id UUID
+        );
+        DROP TABLE IF EXISTS journal_lines;
+        DROP TABLE IF EXISTS journal_entries;
+        DROP TABLE IF EXISTS ledger_accounts;
+        CREATE TABLE ledger_accounts (
+            id UUID PRIMARY KEY,
+            kind VARCHAR(20) NOT NULL,
+            wallet_id UUID,
+            currency VARCHAR(10) NOT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        CREATE UNIQUE INDEX ON ledger_accounts (wallet_id, currency) WHERE wallet_id IS NOT NULL;
+        CREATE UNIQUE INDEX ON ledger_accounts (kind, currency) WHERE wallet_id IS NULL;
+        CREATE TABLE journal_entries (
+            id UUID PRIMARY KEY,
+            type VARCHAR(20) NOT NULL,
+            transaction_id UUID,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        CREATE TABLE journal_lines (
+            id BIGSERIAL PRIMARY KEY,
+            entry_id UUID NOT NULL REFERENCES journal_entries(id),
+            account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+            currency VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL
+        );
+        DROP TABLE IF EXISTS audit_log;
+        CREATE TABLE audit_log (
+            id UUID PRIMARY KEY,
+            actor_id UUID NOT NULL,
+            action VARCHAR(32) NOT NULL,
+            target_user_id UUID,
+            wallet_id UUID,
+            reason TEXT NOT NULL,
+            details JSONB,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS users;
+        CREATE TABLE users (
+            id UUID PRIMARY KEY,
+            status VARCHAR(16) NOT NULL DEFAULT 'active'
+        );
+        DROP TABLE IF EXISTS status_history;
+        CREATE TABLE status_history (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            wallet_id UUID,
+            from_status VARCHAR(16) NOT NULL,
+            to_status VARCHAR(16) NOT NULL,
+            actor_id UUID NOT NULL,
+            reason TEXT NOT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS spending_limits;
+        CREATE TABLE spending_limits (
+            id UUID PRIMARY KEY,
+            user_id UUID,
+            operation VARCHAR(20) NOT NULL,
+            currency VARCHAR(10) NOT NULL,
+            period VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL CHECK (amount >= 0),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        CREATE UNIQUE INDEX ON spending_limits (operation, currency, period) WHERE user_id IS NULL;
+        CREATE UNIQUE INDEX ON spending_limits (user_id, operation, currency, period) WHERE user_id IS NOT NULL;
+        DROP TABLE IF EXISTS fee_rules;
+        CREATE TABLE fee_rules (
+            id UUID PRIMARY KEY,
+            operation VARCHAR(20) NOT NULL,
+            from_currency VARCHAR(10) NOT NULL,
+            to_currency VARCHAR(10),
+            percent NUMERIC(7, 4) NOT NULL DEFAULT 0,
+            fixed BIGINT NOT NULL DEFAULT 0,
+            min_fee BIGINT NOT NULL DEFAULT 0,
+            max_fee BIGINT,
+            min_volume BIGINT NOT NULL DEFAULT 0,
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        CREATE UNIQUE INDEX ON fee_rules (operation, from_currency, COALESCE(to_currency, ''), min_volume);
+        DROP TABLE IF EXISTS schedule_runs;
+        DROP TABLE IF EXISTS scheduled_transfers;
+        CREATE TABLE scheduled_transfers (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            kind VARCHAR(16) NOT NULL,
+            wallet_id UUID NOT NULL,
+            to_wallet_id UUID,
+            currency VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL CHECK (amount > 0),
+            to_currency VARCHAR(10),
+            cron VARCHAR(100) NOT NULL,
+            status VARCHAR(16) NOT NULL DEFAULT 'active',
+            failures INT NOT NULL DEFAULT 0,
+            next_run_at TIMESTAMPTZ NOT NULL,
+            last_run_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+        CREATE TABLE schedule_runs (
+            id UUID PRIMARY KEY,
+            schedule_id UUID NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+            scheduled_for TIMESTAMPTZ NOT NULL,
+            status VARCHAR(16) NOT NULL,
+            error TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS limit_orders;
+        CREATE TABLE limit_orders (
+            id UUID PRIMARY KEY,
+            user_id UUID NOT NULL,
+            wallet_id UUID NOT NULL,
+            hold_id UUID NOT NULL,
+            from_currency VARCHAR(10) NOT NULL,
+            to_currency VARCHAR(10) NOT NULL,
+            amount BIGINT NOT NULL CHECK (amount > 0),
+            target_rate NUMERIC NOT NULL,
+            fee BIGINT NOT NULL DEFAULT 0,
+            status VARCHAR(16) NOT NULL DEFAULT 'open',
+            filled_rate NUMERIC,
+            to_amount BIGINT,
+            error TEXT,
+            expires_at TIMESTAMP NOT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );
+        DROP TABLE IF EXISTS outbox;
+        CREATE TABLE outbox (
+            id UUID PRIMARY KEY,
+            message_key VARCHAR(100) NOT NULL,
+            payload JSONB NOT NULL,
+            attempts INT NOT NULL DEFAULT 0,
+            last_error TEXT,
+            next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            sent_at TIMESTAMP
+        );
     `)
 	if err != nil {
 		t.Fatalf("ошибка создания таблицы: %v", err)
@@ -55,12 +259,6 @@ func (f *mockExchangeClient) GetAllRates(ctx context.Context) ([]*exchange.GetRa
 	}, nil
 }
 
-type mockProducer struct{}
-
-func (m *mockProducer) Publish(ctx context.Context, key string, value interface{}) error {
-	return nil
-}
-
 type mockCache struct{}
 
 func (c *mockCache) UpdatedRates(rates map[string]float64) {
@@ -68,22 +266,55 @@ func (c *mockCache) UpdatedRates(rates map[string]float64) {
 }
 
 func (c *mockCache) GetRate(from, to string) (float64, bool) {
-	return 0, true
+	return 1.0, true
 }
 
 func (c *mockCache) GetAllRates() (map[string]float64, bool) {
 	return nil, true
 }
 
+// unavailableExchangeClient имитирует недоступный обменник.
+type unavailableExchangeClient struct{}
+
+func (f *unavailableExchangeClient) GetRate(ctx context.Context, from, to string) (float64, error) {
+	return 0, errors.New("exchanger unavailable")
+}
+
+func (f *unavailableExchangeClient) GetAllRates(ctx context.Context) ([]*exchange.GetRateResponse, error) {
+	return nil, errors.New("exchanger unavailable")
+}
+
+// emptyCache — кэш без курсов, каждый запрос курса идёт в обменник.
+type emptyCache struct{}
+
+func (c *emptyCache) UpdatedRates(rates map[string]float64) {}
+
+func (c *emptyCache) GetRate(from, to string) (float64, bool) {
+	return 0, false
+}
+
+func (c *emptyCache) GetAllRates() (map[string]float64, bool) {
+	return nil, false
+}
+
+// brokenExchangeRepo имитирует сбой БД при обмене.
+type brokenExchangeRepo struct {
+	storages.WalletStorage
+}
+
+func (r brokenExchangeRepo) ExchangeWallet(ctx context.Context, walletID uuid.UUID, debit, credit models.Money, fee models.FeeCharge, rate models.Decimal, quoteID uuid.UUID, evt *models.EventMessage) (*models.Wallet, error) {
+	return nil, errors.New("conn closed")
+}
+
 func TestWalletService_ConcurrentWithdrawals(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
 	repo := postgres.NewWalletRepo(db)
+	currencyRepo := postgres.NewCurrencyRepo(db)
 	mockExchange := &mockExchangeClient{}
-	mockProducer := &mockProducer{}
 	mockCachce := &mockCache{}
-	svc := services.NewWalletService(repo, mockExchange, mockCachce, mockProducer, 100)
+	svc := services.NewWalletService(repo, currencyRepo, postgres.NewQuoteRepo(db), mockExchange, mockCachce, time.Minute, 100, 10, nil)
 
 	userID := uuid.New()
 	_, err := svc.CreateWallet(context.Background(), userID)
@@ -91,13 +322,13 @@ func TestWalletService_ConcurrentWithdrawals(t *testing.T) {
 		t.Fatalf("ошибка создания кошелька: %v", err)
 	}
 
-	_, err = svc.DepositWallet(context.Background(), userID, models.RUB, 100000.00)
+	_, err = svc.DepositWallet(context.Background(), userID, models.Money{Currency: models.RUB, Amount: 10000000})
 	if err != nil {
 		t.Fatalf("ошибка пополнения кошелька: %v", err)
 	}
 
 	const goroutines = 1000
-	const withdrawAmount = 0.1
+	withdrawAmount := models.Money{Currency: models.RUB, Amount: 10}
 
 	var wg sync.WaitGroup
 	wg.Add(goroutines)
@@ -108,7 +339,7 @@ func TestWalletService_ConcurrentWithdrawals(t *testing.T) {
 	for i := 0; i < goroutines; i++ {
 		go func() {
 			defer wg.Done()
-			_, err := svc.WithdrawWallet(context.Background(), userID, models.RUB, withdrawAmount)
+			_, err := svc.WithdrawWallet(context.Background(), userID, withdrawAmount)
 			mu.Lock()
 			if err == nil {
 				successCount++
@@ -133,7 +364,1338 @@ func TestWalletService_ConcurrentWithdrawals(t *testing.T) {
 		t.Fatalf("ошибка получения кошелька: %v", err)
 	}
 
-	if wallet.RUB < 0 {
-		t.Errorf("баланс не может быть отрицательным: %f", float64(wallet.RUB))
+	if wallet.Balances[models.RUB] < 0 {
+		t.Errorf("баланс не может быть отрицательным: %f", float64(wallet.Balances[models.RUB]))
+	}
+}
+
+var errInjected = errors.New("injected failure")
+
+// faultyDB оборачивает PostgresDB и ломает каждую n-ю запись в transactions,
+// то есть уже после изменения балансов внутри транзакции.
+type faultyDB struct {
+	*postgres.PostgresDB
+	every int64
+	calls atomic.Int64
+}
+
+func (db *faultyDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := db.PostgresDB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyTx{Tx: tx, db: db}, nil
+}
+
+type faultyTx struct {
+	pgx.Tx
+	db *faultyDB
+}
+
+func (tx *faultyTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "INSERT INTO transactions") && tx.db.calls.Add(1)%tx.db.every == 0 {
+		return failedRow{}
+	}
+	return tx.Tx.QueryRow(ctx, sql, args...)
+}
+
+type failedRow struct{}
+
+func (failedRow) Scan(dest ...any) error {
+	return errInjected
+}
+
+func TestWalletService_ConcurrentExchanges(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := postgres.NewWalletRepo(&faultyDB{PostgresDB: db, every: 3})
+	svc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db), &mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+
+	userID := uuid.New()
+	if _, err := svc.CreateWallet(context.Background(), userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+
+	const initial int64 = 100000
+	if _, err := svc.DepositWallet(context.Background(), userID, models.Money{Currency: models.RUB, Amount: initial}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+	if _, err := svc.DepositWallet(context.Background(), userID, models.Money{Currency: models.USD, Amount: initial}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	const goroutines = 500
+	const exchangeAmount int64 = 500
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	var injected atomic.Int64
+	for i := 0; i < goroutines; i++ {
+		from, to := models.RUB, models.USD
+		if i%2 == 1 {
+			from, to = to, from
+		}
+
+		go func() {
+			defer wg.Done()
+			_, err := svc.ExchangeCurrency(context.Background(), userID, models.Money{Currency: from, Amount: exchangeAmount}, to)
+			switch {
+			case err == nil, errors.Is(err, models.ErrInsufficientFunds):
+			case errors.Is(err, errInjected):
+				injected.Add(1)
+			default:
+				t.Errorf("неожиданная ошибка: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if injected.Load() == 0 {
+		t.Fatal("ни одна ошибка не была внедрена")
+	}
+
+	wallet, err := svc.GetWalletByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+
+	rub, usd := wallet.Balances[models.RUB], wallet.Balances[models.USD]
+	if rub < 0 || usd < 0 {
+		t.Errorf("баланс не может быть отрицательным: RUB=%d USD=%d", rub, usd)
+	}
+
+	total := rub + usd
+	want := 2 * initial
+	if total != want {
+		t.Errorf("суммарный баланс изменился: %d, ожидалось %d", total, want)
+	}
+
+	report, err := services.NewLedgerService(postgres.NewLedgerRepo(db)).Verify(context.Background())
+	if err != nil {
+		t.Fatalf("ошибка сверки журнала: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("журнал расходится с балансами: %+v", report)
+	}
+}
+
+func TestWalletService_QuoteExecutedOnce(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	svc := services.NewWalletService(postgres.NewWalletRepo(db), postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+
+	userID := uuid.New()
+	if _, err := svc.CreateWallet(context.Background(), userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := svc.DepositWallet(context.Background(), userID, models.Money{Currency: models.USD, Amount: 100000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	quote, err := svc.QuoteExchange(context.Background(), userID, models.Money{Currency: models.USD, Amount: 1000}, models.EUR)
+	if err != nil {
+		t.Fatalf("ошибка создания котировки: %v", err)
+	}
+
+	if _, _, err := svc.ExchangeByQuote(context.Background(), uuid.New(), quote.ID); !errors.Is(err, models.ErrQuoteNotFound) {
+		t.Errorf("чужая котировка: ошибка %v, ожидалось ErrQuoteNotFound", err)
+	}
+
+	const goroutines = 50
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	var succeeded atomic.Int64
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			_, _, err := svc.ExchangeByQuote(context.Background(), userID, quote.ID)
+			switch {
+			case err == nil:
+				succeeded.Add(1)
+			case errors.Is(err, models.ErrQuoteUsed):
+			default:
+				t.Errorf("неожиданная ошибка: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := succeeded.Load(); got != 1 {
+		t.Fatalf("котировка исполнена %d раз, ожидалось 1", got)
+	}
+
+	wallet, err := svc.GetWalletByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+	if got := wallet.Balances[models.EUR]; got != quote.Converted.Amount {
+		t.Errorf("баланс EUR = %d, ожидалось %d", got, quote.Converted.Amount)
+	}
+
+	expiring := services.NewWalletService(postgres.NewWalletRepo(db), postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &mockExchangeClient{}, &mockCache{}, 0, 100, 10, nil)
+
+	expired, err := expiring.QuoteExchange(context.Background(), userID, models.Money{Currency: models.USD, Amount: 1000}, models.EUR)
+	if err != nil {
+		t.Fatalf("ошибка создания котировки: %v", err)
+	}
+	if _, _, err := expiring.ExchangeByQuote(context.Background(), userID, expired.ID); !errors.Is(err, models.ErrQuoteExpired) {
+		t.Errorf("истёкшая котировка: ошибка %v, ожидалось ErrQuoteExpired", err)
+	}
+}
+
+func TestWalletRepo_Transfers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+
+	wallets := make([]*models.Wallet, 2)
+	for i := range wallets {
+		userID := uuid.New()
+		if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+			t.Fatalf("ошибка создания кошелька: %v", err)
+		}
+		wallet, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 10000})
+		if err != nil {
+			t.Fatalf("ошибка пополнения кошелька: %v", err)
+		}
+		wallets[i] = wallet
+	}
+	a, b := wallets[0].ID, wallets[1].ID
+
+	if _, err := repo.TransferWallet(ctx, a, a, models.Money{Currency: models.USD, Amount: 100}, nil); !errors.Is(err, models.ErrSelfTransfer) {
+		t.Errorf("перевод самому себе: ошибка %v, ожидалось ErrSelfTransfer", err)
+	}
+	if _, err := repo.TransferWallet(ctx, a, b, models.Money{Currency: models.USD, Amount: 10001}, nil); !errors.Is(err, models.ErrInsufficientFunds) {
+		t.Errorf("перевод сверх баланса: ошибка %v, ожидалось ErrInsufficientFunds", err)
+	}
+	if _, err := repo.TransferWallet(ctx, a, uuid.New(), models.Money{Currency: models.USD, Amount: 100}, nil); !errors.Is(err, models.ErrWalletNotFound) {
+		t.Errorf("перевод на несуществующий кошелёк: ошибка %v, ожидалось ErrWalletNotFound", err)
+	}
+	if _, err := repo.TransferWallet(ctx, uuid.New(), b, models.Money{Currency: models.USD, Amount: 100}, nil); !errors.Is(err, models.ErrWalletNotFound) {
+		t.Errorf("перевод с несуществующего кошелька: ошибка %v, ожидалось ErrWalletNotFound", err)
+	}
+
+	// Встречные переводы блокируют кошельки в одном порядке и не приводят
+	// к взаимной блокировке.
+	const transfers = 50
+	var wg sync.WaitGroup
+	wg.Add(2 * transfers)
+	for i := 0; i < transfers; i++ {
+		for _, pair := range [][2]uuid.UUID{{a, b}, {b, a}} {
+			go func() {
+				defer wg.Done()
+				if _, err := repo.TransferWallet(ctx, pair[0], pair[1], models.Money{Currency: models.USD, Amount: 100}, nil); err != nil {
+					t.Errorf("встречный перевод: %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, wallet := range wallets {
+		got, err := walletSvc.GetWalletByUserID(ctx, wallet.UserID)
+		if err != nil {
+			t.Fatalf("ошибка получения кошелька: %v", err)
+		}
+		if got.Balances[models.USD] != 10000 {
+			t.Errorf("баланс USD кошелька %s = %d, ожидалось 10000", wallet.ID, got.Balances[models.USD])
+		}
+	}
+}
+
+func TestHoldService_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	holdSvc := services.NewHoldService(repo, nil, time.Minute, time.Hour)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 10000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	hold, err := holdSvc.CreateHold(ctx, userID, models.Money{Currency: models.USD, Amount: 6000}, 0)
+	if err != nil {
+		t.Fatalf("ошибка создания холда: %v", err)
+	}
+
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 5000}); !errors.Is(err, models.ErrInsufficientFunds) {
+		t.Errorf("списание сверх доступного баланса: ошибка %v, ожидалось ErrInsufficientFunds", err)
+	}
+
+	capture := models.NewDecimal(2500, 2)
+	hold, err = holdSvc.CaptureHold(ctx, userID, hold.ID, &capture)
+	if err != nil {
+		t.Fatalf("ошибка частичного списания: %v", err)
+	}
+	if hold.Status != models.HoldActive || hold.Remaining().Amount != 3500 {
+		t.Errorf("после частичного списания: статус %s, остаток %d", hold.Status, hold.Remaining().Amount)
+	}
+
+	if _, err := holdSvc.VoidHold(ctx, userID, hold.ID); err != nil {
+		t.Fatalf("ошибка отмены холда: %v", err)
+	}
+	if _, err := holdSvc.VoidHold(ctx, userID, hold.ID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("повторная отмена: ошибка %v, ожидалось ErrHoldNotActive", err)
+	}
+
+	wallet, err := walletSvc.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+	if got := wallet.Balances[models.USD]; got != 7500 {
+		t.Errorf("баланс USD = %d, ожидалось 7500", got)
+	}
+	if got := wallet.Held[models.USD]; got != 0 {
+		t.Errorf("резерв USD = %d, ожидалось 0", got)
+	}
+
+	expiring := services.NewHoldService(repo, nil, time.Nanosecond, time.Hour)
+	expired, err := expiring.CreateHold(ctx, userID, models.Money{Currency: models.USD, Amount: 7500}, 0)
+	if err != nil {
+		t.Fatalf("ошибка создания холда: %v", err)
+	}
+
+	n, err := expiring.ExpireHolds(ctx, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("освобождено %d холдов (ошибка %v), ожидался 1", n, err)
+	}
+
+	expired, err = expiring.GetHold(ctx, userID, expired.ID)
+	if err != nil {
+		t.Fatalf("ошибка получения холда: %v", err)
+	}
+	if expired.Status != models.HoldExpired {
+		t.Errorf("статус истёкшего холда %s, ожидалось %s", expired.Status, models.HoldExpired)
+	}
+
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 7500}); err != nil {
+		t.Errorf("списание после истечения холда: %v", err)
+	}
+}
+
+func TestAdminService_FreezeAndAdjust(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	adminSvc := services.NewAdminService(postgres.NewAdminRepo(db), nil, repo, postgres.NewLimitRepo(db), postgres.NewFeeRepo(db))
+
+	actorID, userID := uuid.New(), uuid.New()
+	walletID, err := walletSvc.CreateWallet(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 10000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	if _, err := adminSvc.SetWalletFrozen(ctx, actorID, walletID, true, "  "); !errors.Is(err, models.ErrReasonRequired) {
+		t.Fatalf("заморозка без причины: ошибка %v, ожидалось ErrReasonRequired", err)
+	}
+
+	wallet, err := adminSvc.SetWalletFrozen(ctx, actorID, walletID, true, "запрос службы безопасности")
+	if err != nil || wallet.Status != models.StatusFrozen {
+		t.Fatalf("ошибка заморозки: %v", err)
+	}
+
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100}); !errors.Is(err, models.ErrWalletFrozen) {
+		t.Errorf("списание с замороженного кошелька: ошибка %v, ожидалось ErrWalletFrozen", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100}); !errors.Is(err, models.ErrWalletFrozen) {
+		t.Errorf("пополнение замороженного кошелька: ошибка %v, ожидалось ErrWalletFrozen", err)
+	}
+
+	// Корректировка проходит и для замороженного кошелька.
+	wallet, err = adminSvc.AdjustBalance(ctx, actorID, walletID, models.Money{Currency: models.USD, Amount: -3000}, "возврат ошибочного пополнения")
+	if err != nil {
+		t.Fatalf("ошибка корректировки: %v", err)
+	}
+	if got := wallet.Balances[models.USD]; got != 7000 {
+		t.Errorf("баланс USD после корректировки = %d, ожидалось 7000", got)
+	}
+
+	if _, err := adminSvc.AdjustBalance(ctx, actorID, walletID, models.Money{Currency: models.USD, Amount: -8000}, "лишнее списание"); !errors.Is(err, models.ErrInsufficientFunds) {
+		t.Errorf("корректировка сверх баланса: ошибка %v, ожидалось ErrInsufficientFunds", err)
+	}
+
+	if _, err := adminSvc.SetWalletFrozen(ctx, actorID, walletID, false, "проверка завершена"); err != nil {
+		t.Fatalf("ошибка разморозки: %v", err)
+	}
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100}); err != nil {
+		t.Errorf("списание после разморозки: %v", err)
+	}
+
+	entries, err := adminSvc.ListAudit(ctx, &userID, 0)
+	if err != nil {
+		t.Fatalf("ошибка чтения аудита: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("записей аудита %d, ожидалось 3", len(entries))
+	}
+	if entries[0].Action != models.AuditWalletUnfreeze || entries[1].Action != models.AuditBalanceAdjustment {
+		t.Errorf("неожиданный порядок записей аудита: %s, %s", entries[0].Action, entries[1].Action)
+	}
+
+	// Дополнительный кошелёк замораживается по своему идентификатору,
+	// основной при этом остаётся активным.
+	savings, err := walletSvc.OpenWallet(ctx, userID, "savings")
+	if err != nil {
+		t.Fatalf("ошибка открытия кошелька: %v", err)
+	}
+	if wallet, err = adminSvc.SetWalletFrozen(ctx, actorID, savings.ID, true, "проверка перевода"); err != nil || wallet.ID != savings.ID {
+		t.Fatalf("ошибка заморозки дополнительного кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositTo(ctx, userID, savings.ID, models.Money{Currency: models.USD, Amount: 100}); !errors.Is(err, models.ErrWalletFrozen) {
+		t.Errorf("пополнение замороженного дополнительного кошелька: ошибка %v, ожидалось ErrWalletFrozen", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100}); err != nil {
+		t.Errorf("пополнение основного кошелька: %v", err)
+	}
+	if wallet, err = adminSvc.GetWallet(ctx, savings.ID); err != nil || wallet.Status != models.StatusFrozen {
+		t.Errorf("дополнительный кошелёк: %+v, ошибка %v", wallet, err)
+	}
+	if _, err := adminSvc.AdjustBalance(ctx, actorID, uuid.New(), models.Money{Currency: models.USD, Amount: 100}, "нет кошелька"); !errors.Is(err, models.ErrWalletNotFound) {
+		t.Errorf("корректировка несуществующего кошелька: ошибка %v, ожидалось ErrWalletNotFound", err)
+	}
+
+	report, err := services.NewLedgerService(postgres.NewLedgerRepo(db)).Verify(ctx)
+	if err != nil {
+		t.Fatalf("ошибка сверки журнала: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("журнал расходится с балансами: %+v", report)
+	}
+}
+
+func TestAdminService_StatusLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	adminRepo := postgres.NewAdminRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	adminSvc := services.NewAdminService(adminRepo, nil, repo, postgres.NewLimitRepo(db), postgres.NewFeeRepo(db))
+
+	actorID, userID := uuid.New(), uuid.New()
+	if _, err := db.Exec(ctx, `INSERT INTO users (id) VALUES ($1)`, userID); err != nil {
+		t.Fatalf("ошибка создания пользователя: %v", err)
+	}
+	walletID, err := walletSvc.CreateWallet(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(10000)); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	// Блокировка списаний пропускает только входящие деньги.
+	wallet, err := adminSvc.SetWalletStatus(ctx, actorID, walletID, models.StatusDebitBlocked, "запрос наследников")
+	if err != nil || wallet.Status != models.StatusDebitBlocked {
+		t.Fatalf("ошибка блокировки списаний: %v", err)
+	}
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, usd(100)); !errors.Is(err, models.ErrWalletDebitBlocked) {
+		t.Errorf("списание при блокировке: ошибка %v, ожидалось ErrWalletDebitBlocked", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(100)); err != nil {
+		t.Errorf("пополнение при блокировке списаний: %v", err)
+	}
+
+	if _, err := adminSvc.SetWalletStatus(ctx, actorID, walletID, models.StatusClosed, "закрытие"); !errors.Is(err, models.ErrWalletNotEmpty) {
+		t.Errorf("закрытие кошелька со средствами: ошибка %v, ожидалось ErrWalletNotEmpty", err)
+	}
+	if _, err := adminSvc.SetWalletStatus(ctx, actorID, walletID, models.StatusActive, "проверка завершена"); err != nil {
+		t.Fatalf("ошибка снятия блокировки: %v", err)
+	}
+
+	// Статус учётной записи действует поверх статуса кошелька.
+	entry := func(reason string) *models.AuditEntry {
+		return &models.AuditEntry{ActorID: actorID, Action: models.AuditAccountStatus, Reason: reason}
+	}
+	if err := adminRepo.SetAccountStatus(ctx, userID, models.StatusFrozen, entry("взлом учётной записи")); err != nil {
+		t.Fatalf("ошибка заморозки учётной записи: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(100)); !errors.Is(err, models.ErrWalletFrozen) {
+		t.Errorf("пополнение при замороженной учётной записи: ошибка %v, ожидалось ErrWalletFrozen", err)
+	}
+	if err := adminRepo.SetAccountStatus(ctx, userID, models.StatusActive, entry("доступ восстановлен")); err != nil {
+		t.Fatalf("ошибка разморозки учётной записи: %v", err)
+	}
+
+	if _, err := adminSvc.AdjustBalance(ctx, actorID, walletID, usd(-10100), "выплата наследникам"); err != nil {
+		t.Fatalf("ошибка корректировки: %v", err)
+	}
+	if _, err := adminSvc.SetWalletStatus(ctx, actorID, walletID, models.StatusClosed, "закрытие"); err != nil {
+		t.Fatalf("ошибка закрытия кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(100)); !errors.Is(err, models.ErrWalletClosed) {
+		t.Errorf("пополнение закрытого кошелька: ошибка %v, ожидалось ErrWalletClosed", err)
+	}
+	if _, err := adminSvc.SetWalletStatus(ctx, actorID, walletID, models.StatusActive, "ошибка"); !errors.Is(err, models.ErrInvalidStatusTransition) {
+		t.Errorf("открытие закрытого кошелька: ошибка %v, ожидалось ErrInvalidStatusTransition", err)
+	}
+
+	history, err := adminRepo.ListStatusHistory(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка чтения истории статусов: %v", err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("записей в истории %d, ожидалось 5", len(history))
+	}
+	if h := history[0]; h.To != models.StatusClosed || h.WalletID == nil || h.ActorID != actorID {
+		t.Errorf("неожиданная последняя запись истории: %+v", h)
+	}
+	if h := history[1]; h.From != models.StatusFrozen || h.WalletID != nil {
+		t.Errorf("неожиданная запись о статусе учётной записи: %+v", h)
+	}
+}
+
+func TestWalletService_LimitsEnforced(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	limitRepo := postgres.NewLimitRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	adminSvc := services.NewAdminService(postgres.NewAdminRepo(db), nil, repo, limitRepo, postgres.NewFeeRepo(db))
+	limitSvc := services.NewLimitService(limitRepo)
+
+	actorID, userID := uuid.New(), uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	daily := models.NewDecimal(10000, 2)
+	_, err := adminSvc.SetLimit(ctx, actorID, nil, models.SetLimitRequest{
+		Operation: models.LimitWithdraw, Currency: "USD", Period: models.LimitDaily, Amount: &daily, Reason: "лимит по умолчанию",
+	})
+	if err != nil {
+		t.Fatalf("ошибка установки лимита: %v", err)
+	}
+
+	// Параллельные списания не должны вместе превысить лимит в 100.00.
+	withdrawAll := func(n int) int64 {
+		var wg sync.WaitGroup
+		var success atomic.Int64
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			go func() {
+				defer wg.Done()
+				_, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 1000})
+				switch {
+				case err == nil:
+					success.Add(1)
+				case !errors.Is(err, models.ErrLimitExceeded):
+					t.Errorf("неожиданная ошибка: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		return success.Load()
+	}
+
+	if got := withdrawAll(30); got != 10 {
+		t.Errorf("успешных списаний %d, ожидалось 10", got)
+	}
+
+	_, err = walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 1})
+	var limitErr *models.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Period != models.LimitDaily || limitErr.Remaining.Amount != 0 {
+		t.Fatalf("списание сверх лимита: ошибка %v, ожидалась LimitExceededError", err)
+	}
+
+	// Собственный лимит пользователя заменяет системный.
+	raised, err := models.ParseMoney("200.00", models.USD)
+	if err != nil {
+		t.Fatalf("ошибка суммы: %v", err)
+	}
+	err = postgres.NewAdminRepo(db).SetSpendingLimit(ctx, &models.SpendingLimit{
+		UserID: &userID, Operation: models.LimitWithdraw, Period: models.LimitDaily, Amount: raised,
+	}, &models.AuditEntry{ActorID: actorID, Action: models.AuditLimitChange, Reason: "повышение после проверки"})
+	if err != nil {
+		t.Fatalf("ошибка установки лимита пользователя: %v", err)
+	}
+
+	if got := withdrawAll(30); got != 10 {
+		t.Errorf("успешных списаний после повышения лимита %d, ожидалось 10", got)
+	}
+
+	usage, err := limitSvc.GetLimits(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка чтения лимитов: %v", err)
+	}
+	if len(usage) != 1 || !usage[0].Override || usage[0].Remaining.Sign() != 0 {
+		t.Errorf("неожиданные лимиты: %+v", usage)
+	}
+
+	wallet, err := walletSvc.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+	if got := wallet.Balances[models.USD]; got != 80000 {
+		t.Errorf("баланс USD = %d, ожидалось 80000", got)
+	}
+}
+
+func TestHoldService_CaptureLimits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	adminSvc := services.NewAdminService(postgres.NewAdminRepo(db), nil, repo, postgres.NewLimitRepo(db), postgres.NewFeeRepo(db))
+	holdSvc := services.NewHoldService(repo, nil, time.Minute, time.Hour)
+
+	userID := uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 100000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+
+	daily := models.NewDecimal(10000, 2)
+	_, err := adminSvc.SetLimit(ctx, uuid.New(), nil, models.SetLimitRequest{
+		Operation: models.LimitWithdraw, Currency: "USD", Period: models.LimitDaily, Amount: &daily, Reason: "лимит по умолчанию",
+	})
+	if err != nil {
+		t.Fatalf("ошибка установки лимита: %v", err)
+	}
+
+	hold, err := holdSvc.CreateHold(ctx, userID, models.Money{Currency: models.USD, Amount: 15000}, 0)
+	if err != nil {
+		t.Fatalf("ошибка создания холда: %v", err)
+	}
+
+	capture := models.NewDecimal(8000, 2)
+	if _, err := holdSvc.CaptureHold(ctx, userID, hold.ID, &capture); err != nil {
+		t.Fatalf("ошибка списания в пределах лимита: %v", err)
+	}
+
+	// Списание холда сверх остатка лимита отклоняется, как и вывод.
+	capture = models.NewDecimal(3000, 2)
+	if _, err := holdSvc.CaptureHold(ctx, userID, hold.ID, &capture); !errors.Is(err, models.ErrLimitExceeded) {
+		t.Fatalf("списание холда сверх лимита: ошибка %v, ожидалось ErrLimitExceeded", err)
+	}
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 3000}); !errors.Is(err, models.ErrLimitExceeded) {
+		t.Errorf("вывод после списания холда: ошибка %v, ожидалось ErrLimitExceeded", err)
+	}
+
+	capture = models.NewDecimal(2000, 2)
+	hold, err = holdSvc.CaptureHold(ctx, userID, hold.ID, &capture)
+	if err != nil {
+		t.Fatalf("ошибка списания остатка лимита: %v", err)
+	}
+	if got := hold.Remaining().Amount; got != 5000 {
+		t.Errorf("остаток холда %d, ожидалось 5000", got)
+	}
+}
+
+func TestWalletRepo_TransferLimits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	adminSvc := services.NewAdminService(postgres.NewAdminRepo(db), nil, repo, postgres.NewLimitRepo(db), postgres.NewFeeRepo(db))
+
+	senderID, recipientID := uuid.New(), uuid.New()
+	senderWallet, err := walletSvc.CreateWallet(ctx, senderID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, senderID, models.Money{Currency: models.USD, Amount: 100000}); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+	savings, err := walletSvc.OpenWallet(ctx, senderID, "savings")
+	if err != nil {
+		t.Fatalf("ошибка открытия кошелька: %v", err)
+	}
+	recipientWallet, err := walletSvc.CreateWallet(ctx, recipientID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+
+	daily := models.NewDecimal(10000, 2)
+	_, err = adminSvc.SetLimit(ctx, uuid.New(), nil, models.SetLimitRequest{
+		Operation: models.LimitWithdraw, Currency: "USD", Period: models.LimitDaily, Amount: &daily, Reason: "лимит по умолчанию",
+	})
+	if err != nil {
+		t.Fatalf("ошибка установки лимита: %v", err)
+	}
+
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := repo.TransferWallet(ctx, senderWallet, recipientWallet, usd(8000), nil); err != nil {
+		t.Fatalf("ошибка перевода в пределах лимита: %v", err)
+	}
+
+	// Перевод другому пользователю сверх остатка лимита отклоняется, как и вывод,
+	// и уже сделанный перевод уменьшает остаток для вывода.
+	if _, err := repo.TransferWallet(ctx, senderWallet, recipientWallet, usd(3000), nil); !errors.Is(err, models.ErrLimitExceeded) {
+		t.Fatalf("перевод сверх лимита: ошибка %v, ожидалось ErrLimitExceeded", err)
+	}
+	if _, err := walletSvc.WithdrawWallet(ctx, senderID, usd(3000)); !errors.Is(err, models.ErrLimitExceeded) {
+		t.Errorf("вывод после перевода: ошибка %v, ожидалось ErrLimitExceeded", err)
+	}
+
+	// Переводы между своими кошельками лимит не тратят.
+	if _, err := repo.TransferWallet(ctx, senderWallet, savings.ID, usd(50000), nil); err != nil {
+		t.Fatalf("ошибка перевода между своими кошельками: %v", err)
+	}
+	if _, err := repo.TransferWallet(ctx, senderWallet, recipientWallet, usd(2000), nil); err != nil {
+		t.Errorf("ошибка перевода остатка лимита: %v", err)
+	}
+}
+
+func TestWalletService_NamedWallets(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	walletSvc := services.NewWalletService(postgres.NewWalletRepo(db), postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &mockExchangeClient{}, &mockCache{}, time.Minute, 100, 3, nil)
+
+	userID := uuid.New()
+	mainID, err := walletSvc.CreateWallet(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+
+	savings, err := walletSvc.OpenWallet(ctx, userID, "  Savings ")
+	if err != nil {
+		t.Fatalf("ошибка открытия кошелька: %v", err)
+	}
+	if savings.Name != "Savings" || savings.Default || savings.Status != models.StatusActive {
+		t.Errorf("неожиданный новый кошелёк: %+v", savings)
+	}
+	if _, err := walletSvc.OpenWallet(ctx, userID, "savings"); !errors.Is(err, models.ErrWalletNameTaken) {
+		t.Errorf("повторное имя: ошибка %v, ожидалось ErrWalletNameTaken", err)
+	}
+	if _, err := walletSvc.OpenWallet(ctx, userID, " "); !errors.Is(err, models.ErrInvalidWalletName) {
+		t.Errorf("пустое имя: ошибка %v, ожидалось ErrInvalidWalletName", err)
+	}
+	business, err := walletSvc.OpenWallet(ctx, userID, "business")
+	if err != nil {
+		t.Fatalf("ошибка открытия кошелька: %v", err)
+	}
+	if _, err := walletSvc.OpenWallet(ctx, userID, "travel"); !errors.Is(err, models.ErrTooManyWallets) {
+		t.Errorf("кошелёк сверх лимита: ошибка %v, ожидалось ErrTooManyWallets", err)
+	}
+
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := walletSvc.DepositTo(ctx, userID, savings.ID, usd(5000)); err != nil {
+		t.Fatalf("ошибка пополнения кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositTo(ctx, uuid.New(), savings.ID, usd(5000)); !errors.Is(err, models.ErrWalletNotFound) {
+		t.Errorf("пополнение чужого кошелька: ошибка %v, ожидалось ErrWalletNotFound", err)
+	}
+
+	// Перевод между своими кошельками не трогает основной кошелёк.
+	if _, err := walletSvc.TransferBetween(ctx, userID, savings.ID, business.ID, usd(2000)); err != nil {
+		t.Fatalf("ошибка перевода между кошельками: %v", err)
+	}
+	if _, err := walletSvc.CloseWallet(ctx, userID, business.ID); !errors.Is(err, models.ErrWalletNotEmpty) {
+		t.Errorf("закрытие кошелька со средствами: ошибка %v, ожидалось ErrWalletNotEmpty", err)
+	}
+	if _, _, err := walletSvc.WithdrawFrom(ctx, userID, business.ID, usd(2000)); err != nil {
+		t.Fatalf("ошибка списания: %v", err)
+	}
+	closed, err := walletSvc.CloseWallet(ctx, userID, business.ID)
+	if err != nil || closed.Status != models.StatusClosed {
+		t.Fatalf("ошибка закрытия кошелька: %v", err)
+	}
+	if _, err := walletSvc.CloseWallet(ctx, userID, mainID); !errors.Is(err, models.ErrDefaultWallet) {
+		t.Errorf("закрытие основного кошелька: ошибка %v, ожидалось ErrDefaultWallet", err)
+	}
+
+	// Имя и место закрытого кошелька освобождаются.
+	renamed, err := walletSvc.RenameWallet(ctx, userID, savings.ID, "business")
+	if err != nil || renamed.Name != "business" {
+		t.Fatalf("ошибка переименования кошелька: %v", err)
+	}
+	if _, err := walletSvc.OpenWallet(ctx, userID, "travel"); err != nil {
+		t.Errorf("ошибка открытия кошелька вместо закрытого: %v", err)
+	}
+
+	wallets, err := walletSvc.ListWallets(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка чтения кошельков: %v", err)
+	}
+	if len(wallets) != 4 || wallets[0].ID != mainID || !wallets[0].Default {
+		t.Fatalf("неожиданный список кошельков: %+v", wallets)
+	}
+	main, err := walletSvc.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+	if main.ID != mainID || main.Balances[models.USD] != 0 {
+		t.Errorf("основной кошелёк изменился: %+v", main)
+	}
+	if renamed.Balances[models.USD] != 3000 {
+		t.Errorf("баланс USD = %d, ожидалось 3000", renamed.Balances[models.USD])
+	}
+}
+
+func TestWalletService_Fees(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	feeRepo := postgres.NewFeeRepo(db)
+
+	houseID, houseOwner := uuid.New(), uuid.New()
+	if err := repo.CreateWallet(ctx, &models.Wallet{ID: houseID, UserID: houseOwner, Name: "revenue"}); err != nil {
+		t.Fatalf("ошибка создания кошелька заведения: %v", err)
+	}
+
+	feeSvc := services.NewFeeService(feeRepo, houseID)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, feeSvc)
+	holdSvc := services.NewHoldService(repo, feeSvc, time.Minute, time.Hour)
+	adminSvc := services.NewAdminService(postgres.NewAdminRepo(db), nil, repo, postgres.NewLimitRepo(db), feeRepo)
+
+	actorID := uuid.New()
+	withdrawRule, err := adminSvc.SetFeeRule(ctx, actorID, models.SetFeeRuleRequest{
+		Operation: models.FeeWithdraw, FromCurrency: "USD",
+		Percent: models.NewDecimal(1, 0), Min: models.NewDecimal(50, 2), Reason: "тариф на вывод",
+	})
+	if err != nil {
+		t.Fatalf("ошибка установки комиссии на вывод: %v", err)
+	}
+	// Базовая ставка обмена и бесплатная ступень с оборота 100.00 USD в месяц.
+	for _, req := range []models.SetFeeRuleRequest{
+		{Operation: models.FeeExchange, FromCurrency: "USD", Percent: models.NewDecimal(5, 1)},
+		{Operation: models.FeeExchange, FromCurrency: "USD", MinVolume: models.NewDecimal(100, 0)},
+	} {
+		req.Reason = "тариф на обмен"
+		if _, err := adminSvc.SetFeeRule(ctx, actorID, req); err != nil {
+			t.Fatalf("ошибка установки комиссии на обмен: %v", err)
+		}
+	}
+	maxFee := models.NewDecimal(1, 0)
+	_, err = adminSvc.SetFeeRule(ctx, actorID, models.SetFeeRuleRequest{
+		Operation: models.FeeWithdraw, FromCurrency: "USD", Min: models.NewDecimal(2, 0), Max: &maxFee, Reason: "ошибка",
+	})
+	if !errors.Is(err, models.ErrInvalidFeeRule) {
+		t.Errorf("минимум больше максимума: ошибка %v, ожидалось ErrInvalidFeeRule", err)
+	}
+
+	userID := uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(100000)); err != nil {
+		t.Fatalf("ошибка пополнения: %v", err)
+	}
+
+	// 1% от 10.00 меньше минимума 0.50.
+	if _, fee, err := walletSvc.WithdrawFrom(ctx, userID, uuid.Nil, usd(1000)); err != nil || fee != usd(50) {
+		t.Fatalf("вывод: комиссия %v, ошибка %v, ожидалось 0.50 USD", fee, err)
+	}
+	if _, fee, err := walletSvc.ExchangeIn(ctx, userID, uuid.Nil, usd(20000), models.EUR); err != nil || fee != usd(100) {
+		t.Fatalf("обмен: комиссия %v, ошибка %v, ожидалось 1.00 USD", fee, err)
+	}
+
+	// Оборот за месяц достиг следующей ступени, и котировка фиксирует нулевую комиссию.
+	quote, err := walletSvc.QuoteExchange(ctx, userID, usd(10000), models.EUR)
+	if err != nil || quote.Fee != usd(0) {
+		t.Fatalf("котировка: комиссия %v, ошибка %v, ожидалась нулевая", quote, err)
+	}
+	if _, _, err := walletSvc.ExchangeByQuote(ctx, userID, quote.ID); err != nil {
+		t.Fatalf("ошибка обмена по котировке: %v", err)
+	}
+
+	// Списание холда платит комиссию за вывод сверх резерва.
+	hold, err := holdSvc.CreateHold(ctx, userID, usd(10000), 0)
+	if err != nil {
+		t.Fatalf("ошибка создания холда: %v", err)
+	}
+	if _, err := holdSvc.CaptureHold(ctx, userID, hold.ID, nil); err != nil {
+		t.Fatalf("ошибка списания холда: %v", err)
+	}
+	wallet, err := walletSvc.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка получения кошелька: %v", err)
+	}
+	if wallet.Balances[models.USD] != 58750 {
+		t.Errorf("баланс USD после списания холда = %d, ожидалось 58750", wallet.Balances[models.USD])
+	}
+
+	if _, err := walletSvc.WithdrawWallet(ctx, userID, usd(58750)); !errors.Is(err, models.ErrInsufficientFunds) {
+		t.Errorf("вывод всего баланса без учёта комиссии: ошибка %v, ожидалось ErrInsufficientFunds", err)
+	}
+
+	if err := adminSvc.DeleteFeeRule(ctx, actorID, withdrawRule.ID, "отмена тарифа"); err != nil {
+		t.Fatalf("ошибка удаления правила: %v", err)
+	}
+	if err := adminSvc.DeleteFeeRule(ctx, actorID, withdrawRule.ID, "отмена тарифа"); !errors.Is(err, models.ErrFeeRuleNotFound) {
+		t.Errorf("повторное удаление: ошибка %v, ожидалось ErrFeeRuleNotFound", err)
+	}
+	if _, fee, err := walletSvc.WithdrawFrom(ctx, userID, uuid.Nil, usd(58750)); err != nil || fee.Amount != 0 {
+		t.Fatalf("вывод без правила: комиссия %v, ошибка %v", fee, err)
+	}
+
+	// Комиссии копятся на счёте комиссий и зачисляются заведению отдельным
+	// шагом, который не проходит, пока кошелёк заведения заморожен.
+	houseBalance := func() int64 {
+		house, err := repo.GetWallet(ctx, houseOwner, houseID)
+		if err != nil {
+			t.Fatalf("ошибка получения кошелька заведения: %v", err)
+		}
+		return house.Balances[models.USD]
+	}
+	if got := houseBalance(); got != 0 {
+		t.Errorf("выручка USD до зачисления = %d, ожидалось 0", got)
+	}
+	if _, err := adminSvc.SetWalletFrozen(ctx, actorID, houseID, true, "проверка выручки"); err != nil {
+		t.Fatalf("ошибка заморозки кошелька заведения: %v", err)
+	}
+	if _, err := feeRepo.SettleFees(ctx, houseID); !errors.Is(err, models.ErrWalletFrozen) {
+		t.Errorf("зачисление на замороженный кошелёк: ошибка %v, ожидалось ErrWalletFrozen", err)
+	}
+	if _, err := adminSvc.SetWalletFrozen(ctx, actorID, houseID, false, "проверка завершена"); err != nil {
+		t.Fatalf("ошибка разморозки кошелька заведения: %v", err)
+	}
+	if settled, err := feeRepo.SettleFees(ctx, houseID); err != nil || settled != 1 {
+		t.Fatalf("зачисление комиссий: валют %d, ошибка %v, ожидалась 1", settled, err)
+	}
+	if got := houseBalance(); got != 250 {
+		t.Errorf("выручка USD = %d, ожидалось 250", got)
+	}
+	if settled, err := feeRepo.SettleFees(ctx, houseID); err != nil || settled != 0 {
+		t.Errorf("повторное зачисление: валют %d, ошибка %v, ожидалось 0", settled, err)
+	}
+
+	history, err := postgres.NewTransactionRepo(db).ListTransactionsByUser(ctx, userID, models.TransactionFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("ошибка чтения истории: %v", err)
+	}
+	var charged int64
+	for _, tx := range history {
+		if tx.Fee != nil {
+			charged += tx.Fee.Amount
+		}
+	}
+	if charged != 250 {
+		t.Errorf("комиссии в истории = %d, ожидалось 250", charged)
+	}
+
+	report, err := services.NewLedgerService(postgres.NewLedgerRepo(db)).Verify(ctx)
+	if err != nil {
+		t.Fatalf("ошибка сверки журнала: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("журнал расходится с балансами: %+v", report)
+	}
+}
+
+func TestScheduleService_Runs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	walletSvc := services.NewWalletService(postgres.NewWalletRepo(db), postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &mockExchangeClient{}, &mockCache{}, time.Minute, 100, 3, nil)
+	scheduleSvc := services.NewScheduleService(postgres.NewScheduleRepo(db), walletSvc,
+		postgres.NewUnitOfWork(db), 5, 2)
+
+	userID := uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	savings, err := walletSvc.OpenWallet(ctx, userID, "savings")
+	if err != nil {
+		t.Fatalf("ошибка открытия кошелька: %v", err)
+	}
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(5000)); err != nil {
+		t.Fatalf("ошибка пополнения: %v", err)
+	}
+
+	invalid := &models.ScheduledTransfer{UserID: userID, Kind: models.ScheduleTransfer, ToWalletID: &savings.ID, Amount: usd(1000), Cron: "0 25 * * *"}
+	if err := scheduleSvc.CreateSchedule(ctx, invalid); !errors.Is(err, models.ErrInvalidSchedule) {
+		t.Errorf("неверное расписание: ошибка %v, ожидалось ErrInvalidSchedule", err)
+	}
+
+	schedule := &models.ScheduledTransfer{UserID: userID, Kind: models.ScheduleTransfer, ToWalletID: &savings.ID, Amount: usd(1000), Cron: "@daily"}
+	if err := scheduleSvc.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("ошибка создания поручения: %v", err)
+	}
+	if !schedule.NextRunAt.After(time.Now()) {
+		t.Errorf("следующее исполнение %v не в будущем", schedule.NextRunAt)
+	}
+	if _, err := scheduleSvc.GetSchedule(ctx, uuid.New(), schedule.ID); !errors.Is(err, models.ErrScheduleNotFound) {
+		t.Errorf("чужое поручение: ошибка %v, ожидалось ErrScheduleNotFound", err)
+	}
+
+	// due переносит исполнение поручения в прошлое, как после простоя.
+	now := time.Now().UTC().Truncate(time.Second)
+	due := func(at time.Time) {
+		if _, err := db.Exec(ctx, `UPDATE scheduled_transfers SET next_run_at = $1 WHERE id = $2`, at, schedule.ID); err != nil {
+			t.Fatalf("ошибка переноса исполнения: %v", err)
+		}
+	}
+	balance := func() int64 {
+		wallet, err := walletSvc.GetWallet(ctx, userID, savings.ID)
+		if err != nil {
+			t.Fatalf("ошибка получения кошелька: %v", err)
+		}
+		return wallet.Balances[models.USD]
+	}
+
+	due(now.Add(-3 * time.Hour))
+	if n, err := scheduleSvc.RunDue(ctx, now, 10); err != nil || n != 1 {
+		t.Fatalf("исполнение: выбрано %d, ошибка %v", n, err)
+	}
+	if n, err := scheduleSvc.RunDue(ctx, now, 10); err != nil || n != 0 {
+		t.Errorf("повторный запуск: выбрано %d, ошибка %v, ожидалось 0", n, err)
+	}
+	if got := balance(); got != 1000 {
+		t.Fatalf("баланс после исполнения = %d, ожидалось 1000", got)
+	}
+
+	// То же исполнение после перезапуска не повторяется.
+	due(now.Add(-3 * time.Hour))
+	if _, err := scheduleSvc.RunDue(ctx, now, 10); err == nil {
+		t.Error("повторное исполнение с тем же идентификатором не отклонено")
+	}
+	if got := balance(); got != 1000 {
+		t.Errorf("баланс после повторного исполнения = %d, ожидалось 1000", got)
+	}
+
+	// Неудачные исполнения записываются, и после двух подряд поручение приостанавливается.
+	amount := models.NewDecimal(1000, 0)
+	if _, err := scheduleSvc.UpdateSchedule(ctx, userID, schedule.ID, models.UpdateScheduleRequest{Amount: &amount}); err != nil {
+		t.Fatalf("ошибка изменения суммы: %v", err)
+	}
+	for i, at := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour)} {
+		due(at)
+		if n, err := scheduleSvc.RunDue(ctx, now, 10); err != nil || n != 1 {
+			t.Fatalf("неудачное исполнение %d: выбрано %d, ошибка %v", i+1, n, err)
+		}
+	}
+	got, err := scheduleSvc.GetSchedule(ctx, userID, schedule.ID)
+	if err != nil {
+		t.Fatalf("ошибка получения поручения: %v", err)
+	}
+	if got.Status != models.SchedulePaused || got.Failures != 2 {
+		t.Errorf("после неудач: статус %s, неудач %d, ожидалось paused и 2", got.Status, got.Failures)
+	}
+	if got := balance(); got != 1000 {
+		t.Errorf("баланс после неудач = %d, ожидалось 1000", got)
+	}
+
+	runs, err := scheduleSvc.ListRuns(ctx, userID, schedule.ID, 0)
+	if err != nil {
+		t.Fatalf("ошибка чтения истории: %v", err)
+	}
+	if len(runs) != 3 || runs[0].Status != models.ScheduleRunFailed || runs[0].Error == "" ||
+		runs[2].Status != models.ScheduleRunSucceeded {
+		t.Fatalf("неожиданная история исполнений: %+v", runs)
+	}
+
+	active := models.ScheduleActive
+	resumed, err := scheduleSvc.UpdateSchedule(ctx, userID, schedule.ID, models.UpdateScheduleRequest{Status: &active})
+	if err != nil {
+		t.Fatalf("ошибка возобновления: %v", err)
+	}
+	if resumed.Status != models.ScheduleActive || resumed.Failures != 0 || !resumed.NextRunAt.After(now) {
+		t.Errorf("неожиданное возобновлённое поручение: %+v", resumed)
+	}
+
+	if err := scheduleSvc.DeleteSchedule(ctx, userID, schedule.ID); err != nil {
+		t.Fatalf("ошибка удаления поручения: %v", err)
+	}
+	if _, err := scheduleSvc.ListRuns(ctx, userID, schedule.ID, 0); !errors.Is(err, models.ErrScheduleNotFound) {
+		t.Errorf("история удалённого поручения: ошибка %v, ожидалось ErrScheduleNotFound", err)
+	}
+}
+
+func TestScheduleService_ExchangerOutage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	walletSvc := services.NewWalletService(postgres.NewWalletRepo(db), postgres.NewCurrencyRepo(db),
+		postgres.NewQuoteRepo(db), &unavailableExchangeClient{}, &emptyCache{}, time.Minute, 100, 3, nil)
+	scheduleSvc := services.NewScheduleService(postgres.NewScheduleRepo(db), walletSvc,
+		postgres.NewUnitOfWork(db), 5, 2)
+
+	userID := uuid.New()
+	if _, err := walletSvc.CreateWallet(ctx, userID); err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	if _, err := walletSvc.DepositWallet(ctx, userID, models.Money{Currency: models.USD, Amount: 5000}); err != nil {
+		t.Fatalf("ошибка пополнения: %v", err)
+	}
+
+	schedule := &models.ScheduledTransfer{UserID: userID, Kind: models.ScheduleExchange,
+		Amount: models.Money{Currency: models.USD, Amount: 1000}, ToCurrency: models.EUR, Cron: "@daily"}
+	if err := scheduleSvc.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("ошибка создания поручения: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	scheduledFor := now.Add(-time.Hour)
+	if _, err := db.Exec(ctx, `UPDATE scheduled_transfers SET next_run_at = $1 WHERE id = $2`, scheduledFor, schedule.ID); err != nil {
+		t.Fatalf("ошибка переноса исполнения: %v", err)
+	}
+
+	// Недоступный обменник — временная ошибка: поручение остаётся к исполнению
+	// и не приостанавливается, сколько бы запусков ни прошло.
+	for i := 0; i < 3; i++ {
+		if n, err := scheduleSvc.RunDue(ctx, now, 10); err == nil || n != 0 {
+			t.Fatalf("запуск %d: исполнено %d, ошибка %v, ожидалась ошибка обменника", i+1, n, err)
+		}
+	}
+
+	got, err := scheduleSvc.GetSchedule(ctx, userID, schedule.ID)
+	if err != nil {
+		t.Fatalf("ошибка получения поручения: %v", err)
+	}
+	if got.Status != models.ScheduleActive || got.Failures != 0 || !got.NextRunAt.Equal(scheduledFor) {
+		t.Errorf("после сбоев обменника: статус %s, неудач %d, следующее исполнение %v, ожидалось active, 0 и %v",
+			got.Status, got.Failures, got.NextRunAt, scheduledFor)
+	}
+
+	runs, err := scheduleSvc.ListRuns(ctx, userID, schedule.ID, 0)
+	if err != nil {
+		t.Fatalf("ошибка чтения истории: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("сбои обменника записаны в историю: %+v", runs)
+	}
+}
+
+func TestOrderService_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := postgres.NewWalletRepo(db)
+	walletSvc := services.NewWalletService(repo, postgres.NewCurrencyRepo(db), postgres.NewQuoteRepo(db),
+		&mockExchangeClient{}, &mockCache{}, time.Minute, 100, 10, nil)
+	orderSvc := services.NewOrderService(postgres.NewOrderRepo(db), repo, nil, postgres.NewUnitOfWork(db), time.Hour, 24*time.Hour)
+
+	userID := uuid.New()
+	walletID, err := walletSvc.CreateWallet(ctx, userID)
+	if err != nil {
+		t.Fatalf("ошибка создания кошелька: %v", err)
+	}
+	usd := func(amount int64) models.Money { return models.Money{Currency: models.USD, Amount: amount} }
+	if _, err := walletSvc.DepositWallet(ctx, userID, usd(100000)); err != nil {
+		t.Fatalf("ошибка пополнения: %v", err)
+	}
+	wallet := func() *models.Wallet {
+		w, err := walletSvc.GetWallet(ctx, userID, walletID)
+		if err != nil {
+			t.Fatalf("ошибка получения кошелька: %v", err)
+		}
+		return w
+	}
+
+	order, err := orderSvc.PlaceOrder(ctx, userID, uuid.Nil, usd(60000), models.EUR, models.NewDecimal(95, 2), 0)
+	if err != nil {
+		t.Fatalf("ошибка выставления заявки: %v", err)
+	}
+	if order.Status != models.OrderOpen || order.WalletID != walletID || wallet().Held[models.USD] != 60000 {
+		t.Fatalf("неожиданная заявка или резерв: %+v, резерв %d", order, wallet().Held[models.USD])
+	}
+	if _, err := orderSvc.PlaceOrder(ctx, userID, uuid.Nil, usd(50000), models.EUR, models.NewDecimal(95, 2), 0); !errors.Is(err, models.ErrInsufficientFunds) {
+		t.Errorf("заявка сверх доступного баланса: ошибка %v, ожидалось ErrInsufficientFunds", err)
+	}
+	if _, err := orderSvc.PlaceOrder(ctx, userID, uuid.Nil, usd(100), models.EUR, models.NewDecimal(1, 0), 48*time.Hour); !errors.Is(err, models.ErrInvalidOrderTTL) {
+		t.Errorf("срок сверх максимума: ошибка %v, ожидалось ErrInvalidOrderTTL", err)
+	}
+
+	// Курс ниже целевого заявку не исполняет, достигнутый — исполняет по текущему курсу.
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(94, 2), 10); err != nil || n != 0 {
+		t.Fatalf("курс ниже целевого: выбрано %d, ошибка %v", n, err)
+	}
+
+	// Сбой БД при обмене не закрывает заявку: транзакция откатывается вместе
+	// со снятием холда, и заявка исполняется при следующем запуске.
+	brokenSvc := services.NewOrderService(postgres.NewOrderRepo(db), brokenExchangeRepo{repo}, nil,
+		postgres.NewUnitOfWork(db), time.Hour, 24*time.Hour)
+	if n, err := brokenSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(96, 2), 10); err == nil || n != 0 {
+		t.Fatalf("сбой БД при исполнении: закрыто %d, ошибка %v, ожидалась ошибка", n, err)
+	}
+	if got, err := orderSvc.GetOrder(ctx, userID, order.ID); err != nil || got.Status != models.OrderOpen {
+		t.Fatalf("заявка после сбоя БД: %+v, ошибка %v, ожидался статус open", got, err)
+	}
+	if hold, err := repo.GetHold(ctx, order.HoldID, userID); err != nil || hold.Status != models.HoldActive {
+		t.Fatalf("холд после сбоя БД: %+v, ошибка %v, ожидался статус active", hold, err)
+	}
+	if w := wallet(); w.Held[models.USD] != 60000 {
+		t.Errorf("резерв после сбоя БД = %d, ожидалось 60000", w.Held[models.USD])
+	}
+
+	// Заявку, заблокированную другим экземпляром, исполнение пропускает и не считает.
+	lock, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("ошибка начала транзакции: %v", err)
+	}
+	if _, err := lock.Exec(ctx, `SELECT id FROM limit_orders WHERE id = $1 FOR UPDATE`, order.ID); err != nil {
+		t.Fatalf("ошибка блокировки заявки: %v", err)
+	}
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(96, 2), 10); err != nil || n != 0 {
+		t.Errorf("заблокированная заявка: закрыто %d, ошибка %v, ожидалось 0", n, err)
+	}
+	if err := lock.Rollback(ctx); err != nil {
+		t.Fatalf("ошибка снятия блокировки: %v", err)
+	}
+
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(96, 2), 10); err != nil || n != 1 {
+		t.Fatalf("исполнение: выбрано %d, ошибка %v", n, err)
+	}
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(96, 2), 10); err != nil || n != 0 {
+		t.Errorf("повторное исполнение: выбрано %d, ошибка %v", n, err)
+	}
+	filled, err := orderSvc.GetOrder(ctx, userID, order.ID)
+	if err != nil {
+		t.Fatalf("ошибка получения заявки: %v", err)
+	}
+	if filled.Status != models.OrderFilled || filled.Converted == nil || filled.Converted.Amount != 57600 {
+		t.Fatalf("неожиданная исполненная заявка: %+v", filled)
+	}
+	if w := wallet(); w.Balances[models.USD] != 40000 || w.Balances[models.EUR] != 57600 || w.Held[models.USD] != 0 {
+		t.Errorf("после исполнения: балансы %v, резерв %v", w.Balances, w.Held)
+	}
+
+	cancelled, err := orderSvc.PlaceOrder(ctx, userID, walletID, usd(10000), models.EUR, models.NewDecimal(2, 0), 0)
+	if err != nil {
+		t.Fatalf("ошибка выставления заявки: %v", err)
+	}
+	if _, err := orderSvc.CancelOrder(ctx, userID, cancelled.ID); err != nil {
+		t.Fatalf("ошибка отмены заявки: %v", err)
+	}
+	if _, err := orderSvc.CancelOrder(ctx, userID, cancelled.ID); !errors.Is(err, models.ErrOrderNotOpen) {
+		t.Errorf("повторная отмена: ошибка %v, ожидалось ErrOrderNotOpen", err)
+	}
+	if _, err := orderSvc.CancelOrder(ctx, uuid.New(), cancelled.ID); !errors.Is(err, models.ErrOrderNotFound) {
+		t.Errorf("отмена чужой заявки: ошибка %v, ожидалось ErrOrderNotFound", err)
+	}
+
+	// Истёкшую заявку закрывает ExpireOrders, а резерв освобождается вместе с холдом.
+	expired, err := orderSvc.PlaceOrder(ctx, userID, uuid.Nil, usd(10000), models.EUR, models.NewDecimal(2, 0), 0)
+	if err != nil {
+		t.Fatalf("ошибка выставления заявки: %v", err)
+	}
+	for table, id := range map[string]uuid.UUID{"limit_orders": expired.ID, "holds": expired.HoldID} {
+		if _, err := db.Exec(ctx, `UPDATE `+table+` SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, id); err != nil {
+			t.Fatalf("ошибка переноса срока: %v", err)
+		}
+	}
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(3, 0), 10); err != nil || n != 0 {
+		t.Errorf("истёкшая заявка выбрана для исполнения: %d, ошибка %v", n, err)
+	}
+	if n, err := orderSvc.ExpireOrders(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("истечение заявок: закрыто %d, ошибка %v", n, err)
+	}
+	if _, err := repo.ExpireHolds(ctx, 10); err != nil {
+		t.Fatalf("ошибка освобождения холдов: %v", err)
+	}
+
+	// Холд заявки нельзя ни отменить, ни списать через API холдов.
+	failed, err := orderSvc.PlaceOrder(ctx, userID, uuid.Nil, usd(10000), models.EUR, models.NewDecimal(1, 0), 0)
+	if err != nil {
+		t.Fatalf("ошибка выставления заявки: %v", err)
+	}
+	holdSvc := services.NewHoldService(repo, nil, time.Minute, time.Hour)
+	if _, err := holdSvc.VoidHold(ctx, userID, failed.HoldID); !errors.Is(err, models.ErrHoldOwnedByOrder) {
+		t.Errorf("отмена холда заявки: ошибка %v, ожидалось ErrHoldOwnedByOrder", err)
+	}
+	if _, err := holdSvc.CaptureHold(ctx, userID, failed.HoldID, nil); !errors.Is(err, models.ErrHoldOwnedByOrder) {
+		t.Errorf("списание холда заявки: ошибка %v, ожидалось ErrHoldOwnedByOrder", err)
+	}
+	if _, err := repo.CaptureHold(ctx, failed.HoldID, userID, usd(100), models.FeeCharge{}, nil); !errors.Is(err, models.ErrHoldOwnedByOrder) {
+		t.Errorf("списание холда заявки в обход сервиса: ошибка %v, ожидалось ErrHoldOwnedByOrder", err)
+	}
+	if hold, err := repo.GetHold(ctx, failed.HoldID, userID); err != nil || hold.Status != models.HoldActive ||
+		hold.OrderID == nil || *hold.OrderID != failed.ID {
+		t.Fatalf("холд заявки: %+v, ошибка %v, ожидался активный холд заявки", hold, err)
+	}
+
+	// Холд, освобождённый раньше заявки, не даёт её исполнить: она закрывается как неудачная.
+	if _, err := db.Exec(ctx, `UPDATE holds SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, failed.HoldID); err != nil {
+		t.Fatalf("ошибка переноса срока: %v", err)
+	}
+	if n, err := repo.ExpireHolds(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("освобождение холда: %d, ошибка %v", n, err)
+	}
+	if n, err := orderSvc.FillOrders(ctx, models.USD, models.EUR, models.NewDecimal(1, 0), 10); err != nil || n != 1 {
+		t.Fatalf("исполнение без резерва: выбрано %d, ошибка %v", n, err)
+	}
+
+	list, err := orderSvc.ListOrders(ctx, userID, "")
+	if err != nil {
+		t.Fatalf("ошибка чтения заявок: %v", err)
+	}
+	statuses := make(map[models.OrderStatus]int)
+	for _, o := range list {
+		statuses[o.Status]++
+	}
+	want := map[models.OrderStatus]int{models.OrderFilled: 1, models.OrderCancelled: 1, models.OrderExpired: 1, models.OrderFailed: 1}
+	if len(list) != 4 || !maps.Equal(statuses, want) {
+		t.Errorf("статусы заявок %v, ожидалось %v", statuses, want)
+	}
+	if w := wallet(); w.Balances[models.USD] != 40000 || w.Held[models.USD] != 0 {
+		t.Errorf("итог: балансы %v, резерв %v", w.Balances, w.Held)
+	}
+
+	var events int
+	err = db.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE payload->>'event' LIKE 'order\_%'`).Scan(&events)
+	if err != nil {
+		t.Fatalf("ошибка чтения outbox: %v", err)
+	}
+	if events != 8 {
+		t.Errorf("событий заявок в outbox = %d, ожидалось 8", events)
+	}
+
+	report, err := services.NewLedgerService(postgres.NewLedgerRepo(db)).Verify(ctx)
+	if err != nil {
+		t.Fatalf("ошибка сверки журнала: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("журнал расходится с балансами: %+v", report)
 	}
 }
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTransactionsLimit = 20
+	MaxTransactionsLimit     = 100
+)
+
+type TransactionService struct {
+	transactionRepo storages.TransactionStorage
+}
+
+func NewTransactionService(transactionRepo storages.TransactionStorage) *TransactionService {
+	return &TransactionService{transactionRepo: transactionRepo}
+}
+
+// ListTransactions возвращает страницу истории операций пользователя.
+func (s *TransactionService) ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	if filter.Type != "" && !filter.Type.Valid() {
+		return nil, ErrInvalidTransactionType
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = DefaultTransactionsLimit
+	case filter.Limit > MaxTransactionsLimit:
+		filter.Limit = MaxTransactionsLimit
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	items, err := s.transactionRepo.ListTransactionsByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TransactionPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = models.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	if page.Items == nil {
+		page.Items = []*models.Transaction{}
+	}
+
+	return page, nil
+}
+
+var (
+	ErrInvalidTransactionType = fmt.Errorf("invalid transaction type")
+	ErrInvalidDateRange       = fmt.Errorf("invalid date range")
+)
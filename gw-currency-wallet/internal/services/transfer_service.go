@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type TransferService struct {
+	walletRepo storages.WalletStorage
+	userRepo   storages.UserStorage
+}
+
+func NewTransferService(walletRepo storages.WalletStorage, userRepo storages.UserStorage) *TransferService {
+	return &TransferService{walletRepo: walletRepo, userRepo: userRepo}
+}
+
+// Transfer переводит amount из кошелька fromUserID получателю, найденному
+// по имени пользователя или по идентификатору. Должен быть задан ровно один из них.
+func (s *TransferService) Transfer(ctx context.Context, fromUserID uuid.UUID, toUsername string, toUserID uuid.UUID, amount models.Money) (*models.Wallet, error) {
+	recipient, err := s.findRecipient(ctx, toUsername, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == fromUserID {
+		return nil, models.ErrSelfTransfer
+	}
+
+	sender, err := s.walletRepo.GetWalletByUserID(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientWallet, err := s.walletRepo.GetWalletByUserID(ctx, recipient.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	evt := largeOperationEvent(models.Transfer, amount, string(amount.Currency))
+	if evt != nil {
+		evt.Details = fmt.Sprintf("recipient_user_id=%s recipient_wallet_id=%s", recipient.ID, recipientWallet.ID)
+	}
+
+	return s.walletRepo.TransferWallet(ctx, sender.ID, recipientWallet.ID, amount, evt)
+}
+
+func (s *TransferService) findRecipient(ctx context.Context, username string, userID uuid.UUID) (*models.User, error) {
+	if (username == "") == (userID == uuid.Nil) {
+		return nil, ErrInvalidRecipient
+	}
+
+	var (
+		user *models.User
+		err  error
+	)
+	if username != "" {
+		user, err = s.userRepo.GetUserByUsername(ctx, username)
+	} else {
+		user, err = s.userRepo.GetUserByID(ctx, userID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+var (
+	ErrInvalidRecipient  = fmt.Errorf("exactly one of recipient username or user id must be set")
+	ErrRecipientNotFound = fmt.Errorf("recipient not found")
+)
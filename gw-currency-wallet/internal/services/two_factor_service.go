@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/secretbox"
+	"gw-currency-wallet/internal/storages"
+	"gw-currency-wallet/internal/totp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TwoFactorService управляет TOTP-приложениями пользователей: подключением,
+// проверкой кодов при входе и подтверждением крупных операций.
+type TwoFactorService struct {
+	repo         storages.TwoFactorStorage
+	userRepo     storages.UserStorage
+	box          *secretbox.Box
+	issuer       string
+	stepUp       models.StepUpThresholds
+	challengeTTL time.Duration
+	maxAttempts  int
+	lockout      time.Duration
+}
+
+func NewTwoFactorService(repo storages.TwoFactorStorage, userRepo storages.UserStorage, box *secretbox.Box, issuer string, stepUp models.StepUpThresholds, challengeTTL time.Duration, maxAttempts int, lockout time.Duration) *TwoFactorService {
+	return &TwoFactorService{
+		repo:         repo,
+		userRepo:     userRepo,
+		box:          box,
+		issuer:       issuer,
+		stepUp:       stepUp,
+		challengeTTL: challengeTTL,
+		maxAttempts:  maxAttempts,
+		lockout:      lockout,
+	}
+}
+
+// Enroll создаёт секрет нового приложения. Двухфакторная аутентификация
+// включается только после Confirm с первым кодом из приложения.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.box.Seal(secret, userID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTOTPSecret(ctx, userID, sealed); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:          totp.EncodeSecret(secret),
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm включает двухфакторную аутентификацию по первому коду из приложения
+// и возвращает коды восстановления. Они показываются только один раз.
+func (s *TwoFactorService) Confirm(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Enabled() {
+		return nil, models.ErrTwoFactorAlreadyEnabled
+	}
+	if t.Locked {
+		return nil, models.ErrTwoFactorLocked
+	}
+
+	secret, err := s.box.Open(t.EncryptedSecret, userID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	counter, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		if err := s.repo.RecordTOTPFailure(ctx, userID, s.maxAttempts, s.lockout); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidTwoFactorCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err := s.repo.EnableTOTP(ctx, userID, counter, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// Disable отключает двухфакторную аутентификацию. Нужен код из приложения
+// или код восстановления.
+func (s *TwoFactorService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	if err := s.Verify(ctx, userID, code, true); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(ctx, userID)
+}
+
+// Verify проверяет код из приложения, а если allowRecovery, то и код восстановления.
+func (s *TwoFactorService) Verify(ctx context.Context, userID uuid.UUID, code string, allowRecovery bool) error {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.verify(ctx, t, code, allowRecovery)
+}
+
+// RequireStepUp требует код из приложения для операции на сумму от порога
+// подтверждения в её валюте. Без подключённого приложения такие операции недоступны.
+func (s *TwoFactorService) RequireStepUp(ctx context.Context, userID uuid.UUID, amount models.Money, code string) error {
+	if !s.stepUp.Requires(amount) {
+		return nil
+	}
+
+	return s.Verify(ctx, userID, code, false)
+}
+
+// StartChallenge создаёт вызов второго шага входа. Если двухфакторная
+// аутентификация не включена, возвращает nil.
+func (s *TwoFactorService) StartChallenge(ctx context.Context, userID uuid.UUID) (*models.LoginChallenge, error) {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if errors.Is(err, models.ErrTwoFactorNotEnabled) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.Enabled() {
+		return nil, nil
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &models.TwoFactorChallenge{UserID: userID, TokenHash: hashToken(token)}
+	if err := s.repo.CreateChallenge(ctx, challenge, s.challengeTTL); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresAt:         challenge.ExpiresAt,
+	}, nil
+}
+
+// CompleteChallenge проверяет код второго шага входа и возвращает пользователя.
+func (s *TwoFactorService) CompleteChallenge(ctx context.Context, challengeToken, code string) (uuid.UUID, error) {
+	if challengeToken == "" {
+		return uuid.Nil, models.ErrInvalidChallenge
+	}
+
+	challenge, err := s.repo.GetChallenge(ctx, hashToken(challengeToken))
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := s.Verify(ctx, challenge.UserID, code, true); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := s.repo.UseChallenge(ctx, challenge.ID); err != nil {
+		return uuid.Nil, err
+	}
+
+	return challenge.UserID, nil
+}
+
+func (s *TwoFactorService) verify(ctx context.Context, t *models.TOTP, code string, allowRecovery bool) error {
+	if !t.Enabled() {
+		return models.ErrTwoFactorNotEnabled
+	}
+	if t.Locked {
+		return models.ErrTwoFactorLocked
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return models.ErrTwoFactorRequired
+	}
+
+	secret, err := s.box.Open(t.EncryptedSecret, t.UserID[:])
+	if err != nil {
+		return err
+	}
+
+	if counter, ok := totp.Validate(secret, code, time.Now(), totpSkew); ok {
+		// Повторно предъявленный код считается неверным.
+		err := s.repo.RecordTOTPSuccess(ctx, t.UserID, counter)
+		if !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			return err
+		}
+	} else if allowRecovery {
+		err := s.repo.UseRecoveryCode(ctx, t.UserID, hashToken(normalizeRecoveryCode(code)))
+		if !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			return err
+		}
+	}
+
+	if err := s.repo.RecordTOTPFailure(ctx, t.UserID, s.maxAttempts, s.lockout); err != nil {
+		return err
+	}
+
+	return models.ErrInvalidTwoFactorCode
+}
+
+// newRecoveryCode возвращает код вида abcd-efgh-ijkl-mnop.
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// normalizeRecoveryCode позволяет вводить код без дефисов и в любом регистре.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+const (
+	// totpSkew допускает расхождение часов клиента на один шаг в каждую сторону.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
@@ -5,34 +5,50 @@ import (
 	"fmt"
 	"gw-currency-wallet/gw-exchanger/proto/exchange"
 	grpcClient "gw-currency-wallet/internal/grpc"
-	"gw-currency-wallet/internal/kafka"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/storages"
 	"gw-currency-wallet/internal/utils"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 type WalletService struct {
 	walletRepo     storages.WalletStorage
+	currencyRepo   storages.CurrencyStorage
+	quoteRepo      storages.QuoteStorage
 	exchangeClient grpcClient.ExchangeClient
-	producer       kafka.ProducerInterface
 	rateCache      utils.RateCacheInterface
+	quoteTTL       time.Duration
 	sem            chan struct{}
+	maxWallets     int
+	fees           *FeeService
+
+	currenciesLoaded atomic.Bool
 }
 
 func NewWalletService(walletRepo storages.WalletStorage,
+	currencyRepo storages.CurrencyStorage,
+	quoteRepo storages.QuoteStorage,
 	exchangeClient grpcClient.ExchangeClient,
 	rateCache utils.RateCacheInterface,
-	producer kafka.ProducerInterface,
-	maxIn int32) *WalletService {
+	quoteTTL time.Duration,
+	maxIn int32,
+	maxWallets int,
+	fees *FeeService) *WalletService {
 	return &WalletService{
 		walletRepo:     walletRepo,
+		currencyRepo:   currencyRepo,
+		quoteRepo:      quoteRepo,
 		exchangeClient: exchangeClient,
-		producer:       producer,
 		rateCache:      rateCache,
+		quoteTTL:       quoteTTL,
 		sem:            make(chan struct{}, maxIn),
+		maxWallets:     maxWallets,
+		fees:           fees,
 	}
 }
 
@@ -42,16 +58,9 @@ func (s *WalletService) gate() func() {
 }
 
 func (s *WalletService) CreateWallet(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
-	wallet := models.Wallet{
-		ID:        uuid.New(),
-		UserID:    userID,
-		USD:       0,
-		RUB:       0,
-		EUR:       0,
-		UpdatedAt: time.Now(),
-	}
+	wallet := models.NewDefaultWallet(userID)
 
-	err := s.walletRepo.CreateWallet(ctx, &wallet)
+	err := s.walletRepo.CreateWallet(ctx, wallet)
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -63,66 +72,140 @@ func (s *WalletService) GetWalletByUserID(ctx context.Context, userID uuid.UUID)
 	return s.walletRepo.GetWalletByUserID(ctx, userID)
 }
 
-func (s *WalletService) DepositWallet(ctx context.Context, userID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error) {
-	release := s.gate()
-	defer release()
+// ListWallets возвращает все кошельки пользователя, включая закрытые.
+func (s *WalletService) ListWallets(ctx context.Context, userID uuid.UUID) ([]*models.Wallet, error) {
+	return s.walletRepo.ListWallets(ctx, userID)
+}
 
-	wallet, err := s.walletRepo.GetWalletByUserID(ctx, userID)
+func (s *WalletService) GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	return s.walletRepo.GetWallet(ctx, userID, walletID)
+}
+
+// OpenWallet открывает пользователю дополнительный кошелёк с именем name.
+func (s *WalletService) OpenWallet(ctx context.Context, userID uuid.UUID, name string) (*models.Wallet, error) {
+	name, err := normalizeWalletName(name)
 	if err != nil {
 		return nil, err
 	}
 
-	updateWallet, err := s.walletRepo.DepositWallet(ctx, wallet.ID, currency, amount)
+	wallet := &models.Wallet{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   name,
+	}
+
+	if err := s.walletRepo.OpenWallet(ctx, wallet, s.maxWallets); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (s *WalletService) RenameWallet(ctx context.Context, userID, walletID uuid.UUID, name string) (*models.Wallet, error) {
+	name, err := normalizeWalletName(name)
 	if err != nil {
 		return nil, err
 	}
 
-	if amount >= models.EventAmount {
-		evt := models.EventMessage{
-			EventID:   uuid.New(),
-			Event:     models.Deposit,
-			UserID:    userID,
-			WalletID:  wallet.ID,
-			Amount:    amount,
-			Currency:  string(currency),
-			Timestamp: time.Now(),
-			Details:   "",
+	return s.walletRepo.RenameWallet(ctx, userID, walletID, name)
+}
+
+// CloseWallet закрывает пустой дополнительный кошелёк. Основной кошелёк
+// закрывается только вместе с учётной записью.
+func (s *WalletService) CloseWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	return s.walletRepo.CloseWallet(ctx, userID, walletID)
+}
+
+// TransferBetween переводит amount между двумя кошельками пользователя.
+// Такие переводы бесплатны и не требуют подтверждения кодом.
+func (s *WalletService) TransferBetween(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, amount models.Money) (*models.Wallet, error) {
+	release := s.gate()
+	defer release()
+
+	for _, walletID := range []uuid.UUID{fromWalletID, toWalletID} {
+		if _, err := s.walletRepo.GetWallet(ctx, userID, walletID); err != nil {
+			return nil, err
 		}
-		_ = s.producer.Publish(ctx, userID.String(), evt)
 	}
 
-	return updateWallet, nil
+	return s.walletRepo.TransferWallet(ctx, fromWalletID, toWalletID, amount, nil)
+}
+
+func (s *WalletService) DepositWallet(ctx context.Context, userID uuid.UUID, amount models.Money) (*models.Wallet, error) {
+	return s.DepositTo(ctx, userID, uuid.Nil, amount)
 }
 
-func (s *WalletService) WithdrawWallet(ctx context.Context, userID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error) {
+// DepositTo пополняет кошелёк walletID пользователя, а если walletID
+// не задан — основной кошелёк.
+func (s *WalletService) DepositTo(ctx context.Context, userID, walletID uuid.UUID, amount models.Money) (*models.Wallet, error) {
 	release := s.gate()
 	defer release()
 
-	wallet, err := s.walletRepo.GetWalletByUserID(ctx, userID)
+	wallet, err := s.userWallet(ctx, userID, walletID)
 	if err != nil {
 		return nil, err
 	}
 
-	updateWallet, err := s.walletRepo.WithdrawWallet(ctx, wallet.ID, currency, amount)
+	evt := largeOperationEvent(models.Deposit, amount, string(amount.Currency))
+
+	updateWallet, err := s.walletRepo.DepositWallet(ctx, wallet.ID, amount, evt)
 	if err != nil {
 		return nil, err
 	}
 
-	if amount >= models.EventAmount {
-		evt := models.EventMessage{
-			EventID:   uuid.New(),
-			Event:     models.Withdraw,
-			UserID:    userID,
-			WalletID:  wallet.ID,
-			Amount:    amount,
-			Currency:  string(currency),
-			Timestamp: time.Now(),
-			Details:   "",
-		}
-		_ = s.producer.Publish(ctx, userID.String(), evt)
+	return updateWallet, nil
+}
+
+func (s *WalletService) WithdrawWallet(ctx context.Context, userID uuid.UUID, amount models.Money) (*models.Wallet, error) {
+	wallet, _, err := s.WithdrawFrom(ctx, userID, uuid.Nil, amount)
+	return wallet, err
+}
+
+// WithdrawFrom списывает amount с кошелька walletID пользователя, а если
+// walletID не задан — с основного кошелька. Комиссия списывается сверх
+// amount и возвращается вместе с кошельком.
+func (s *WalletService) WithdrawFrom(ctx context.Context, userID, walletID uuid.UUID, amount models.Money) (*models.Wallet, models.Money, error) {
+	release := s.gate()
+	defer release()
+
+	wallet, err := s.userWallet(ctx, userID, walletID)
+	if err != nil {
+		return nil, models.Money{}, err
 	}
 
-	return updateWallet, nil
+	fee, err := s.fees.Charge(ctx, userID, wallet.ID, models.FeeWithdraw, amount, "")
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	evt := largeOperationEvent(models.Withdraw, amount, string(amount.Currency))
+
+	updateWallet, err := s.walletRepo.WithdrawWallet(ctx, wallet.ID, amount, fee, evt)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	return updateWallet, fee.Amount, nil
+}
+
+// userWallet возвращает кошелёк walletID пользователя или, если walletID
+// равен uuid.Nil, его основной кошелёк.
+func (s *WalletService) userWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	if walletID == uuid.Nil {
+		return s.walletRepo.GetWalletByUserID(ctx, userID)
+	}
+
+	return s.walletRepo.GetWallet(ctx, userID, walletID)
+}
+
+// normalizeWalletName убирает пробелы по краям имени и проверяет его длину.
+func normalizeWalletName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > models.MaxWalletNameLength {
+		return "", models.ErrInvalidWalletName
+	}
+
+	return name, nil
 }
 
 func (s *WalletService) GetAllRates(ctx context.Context) (map[string]float64, error) {
@@ -135,61 +218,290 @@ func (s *WalletService) GetAllRates(ctx context.Context) (map[string]float64, er
 		return nil, err
 	}
 
+	if err := s.registerCurrencies(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	if err := s.ReloadCurrencies(ctx); err != nil {
+		return nil, err
+	}
+
 	return s.createCacheRates(rates), nil
 }
 
-func (s *WalletService) ExchangeCurrency(ctx context.Context, userID uuid.UUID, from, to models.Currency, amount float64) (*models.Wallet, error) {
-	release := s.gate()
-	defer release()
+// SyncCurrencies добавляет в реестр все валюты, известные обменнику,
+// чтобы они стали доступны для операций без изменения кода.
+func (s *WalletService) SyncCurrencies(ctx context.Context) error {
+	rates, err := s.exchangeClient.GetAllRates(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.registerCurrencies(ctx, rates); err != nil {
+		return err
+	}
 
-	wallet, err := s.walletRepo.GetWalletByUserID(ctx, userID)
+	if err := s.ReloadCurrencies(ctx); err != nil {
+		return err
+	}
+
+	s.createCacheRates(rates)
+	return nil
+}
+
+// ReloadCurrencies загружает метаданные валют из БД в models.Currencies.
+func (s *WalletService) ReloadCurrencies(ctx context.Context) error {
+	currencies, err := s.currencyRepo.ListCurrencies(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if _, err := wallet.Withdraw(amount, from); err != nil {
-		return nil, err
+	infos := make([]models.CurrencyInfo, 0, len(currencies))
+	for _, c := range currencies {
+		infos = append(infos, *c)
 	}
 
-	rate, exists := s.rateCache.GetRate(string(from), string(to))
-	if !exists {
-		rates, err := s.exchangeClient.GetAllRates(ctx)
-		if err != nil {
+	models.Currencies.Replace(infos)
+	s.currenciesLoaded.Store(true)
+	return nil
+}
+
+// ListCurrencies возвращает включённые валюты с их точностью из
+// models.Currencies. Реестр загружается при старте, перезагружается при
+// каждой синхронизации с обменником и периодически, чтобы изменения в БД
+// доходили до всех экземпляров; здесь он читается из БД, только если ещё
+// ни разу не был загружен.
+func (s *WalletService) ListCurrencies(ctx context.Context) ([]models.CurrencyInfo, error) {
+	if !s.currenciesLoaded.Load() {
+		if err := s.ReloadCurrencies(ctx); err != nil {
 			return nil, err
 		}
-		s.createCacheRates(rates)
+	}
 
-		rate, exists = s.rateCache.GetRate(string(from), string(to))
-		if !exists {
-			return nil, fmt.Errorf("rate form %s to %s not found even after refresh", from, to)
+	return models.Currencies.List(), nil
+}
+
+func (s *WalletService) registerCurrencies(ctx context.Context, rates []*exchange.GetRateResponse) error {
+	seen := make(map[models.Currency]struct{})
+	var codes []models.Currency
+
+	for _, rate := range rates {
+		for _, code := range []string{rate.FromCurrency, rate.ToCurrency} {
+			currency := models.Currency(code)
+			if _, ok := seen[currency]; ok || code == "" {
+				continue
+			}
+			seen[currency] = struct{}{}
+			codes = append(codes, currency)
 		}
 	}
 
-	converted := amount * rate
+	return s.currencyRepo.EnsureCurrencies(ctx, codes)
+}
+
+func (s *WalletService) ExchangeCurrency(ctx context.Context, userID uuid.UUID, amount models.Money, to models.Currency) (*models.Wallet, error) {
+	wallet, _, err := s.ExchangeIn(ctx, userID, uuid.Nil, amount, to)
+	return wallet, err
+}
 
-	if _, err = s.walletRepo.WithdrawWallet(ctx, wallet.ID, from, amount); err != nil {
+// ExchangeIn обменивает валюту в кошельке walletID пользователя, а если
+// walletID не задан — в основном кошельке. Комиссия списывается в продаваемой
+// валюте сверх amount и возвращается вместе с кошельком.
+func (s *WalletService) ExchangeIn(ctx context.Context, userID, walletID uuid.UUID, amount models.Money, to models.Currency) (*models.Wallet, models.Money, error) {
+	release := s.gate()
+	defer release()
+
+	return s.exchangeIn(ctx, userID, walletID, amount, to, s.getRate)
+}
+
+// exchangeAtRate — ExchangeIn по уже полученному курсу rate. Не обращается
+// к обменнику и не ждёт семафор, поэтому подходит для вызова внутри
+// транзакции.
+func (s *WalletService) exchangeAtRate(ctx context.Context, userID, walletID uuid.UUID, amount models.Money, to models.Currency, rate models.Decimal) (*models.Wallet, models.Money, error) {
+	return s.exchangeIn(ctx, userID, walletID, amount, to, func(context.Context, models.Currency, models.Currency) (models.Decimal, error) {
+		return rate, nil
+	})
+}
+
+func (s *WalletService) exchangeIn(ctx context.Context, userID, walletID uuid.UUID, amount models.Money, to models.Currency,
+	getRate func(ctx context.Context, from, to models.Currency) (models.Decimal, error)) (*models.Wallet, models.Money, error) {
+	from := amount.Currency
+
+	wallet, err := s.userWallet(ctx, userID, walletID)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	if from == to {
+		return nil, models.Money{}, models.ErrSameCurrency
+	}
+
+	fee, err := s.fees.Charge(ctx, userID, wallet.ID, models.FeeExchange, amount, to)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	if err := checkCovers(wallet, amount, fee.Amount); err != nil {
+		return nil, models.Money{}, err
+	}
+
+	rate, err := getRate(ctx, from, to)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	converted, err := amount.Convert(to, rate, models.RoundDown)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	evt := largeOperationEvent(models.Exchange, amount, fmt.Sprintf("%s->%s", from, to))
+
+	updatedWallet, err := s.walletRepo.ExchangeWallet(ctx, wallet.ID, amount, converted, fee, rate, uuid.Nil, evt)
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	return updatedWallet, fee.Amount, nil
+}
+
+// checkCovers проверяет, что на кошельке хватает средств на amount вместе
+// с комиссией fee в той же валюте.
+func checkCovers(wallet *models.Wallet, amount, fee models.Money) error {
+	total, err := amount.Add(fee)
+	if err != nil {
+		return err
+	}
+
+	_, err = wallet.Withdraw(total)
+	return err
+}
+
+// QuoteExchange фиксирует текущий курс from->to для amount и сохраняет котировку,
+// которую пользователь может исполнить через ExchangeByQuote до её истечения.
+func (s *WalletService) QuoteExchange(ctx context.Context, userID uuid.UUID, amount models.Money, to models.Currency) (*models.ExchangeQuote, error) {
+	return s.QuoteExchangeIn(ctx, userID, uuid.Nil, amount, to)
+}
+
+// QuoteExchangeIn — QuoteExchange для кошелька walletID пользователя.
+func (s *WalletService) QuoteExchangeIn(ctx context.Context, userID, walletID uuid.UUID, amount models.Money, to models.Currency) (*models.ExchangeQuote, error) {
+	from := amount.Currency
+	if from == to {
+		return nil, models.ErrSameCurrency
+	}
+
+	wallet, err := s.userWallet(ctx, userID, walletID)
+	if err != nil {
 		return nil, err
 	}
-	updatedWallet, err := s.walletRepo.DepositWallet(ctx, wallet.ID, to, converted)
+
+	fee, err := s.fees.Charge(ctx, userID, wallet.ID, models.FeeExchange, amount, to)
 	if err != nil {
 		return nil, err
 	}
 
-	if amount >= models.EventAmount {
-		evt := models.EventMessage{
-			EventID:   uuid.New(),
-			Event:     models.Exchange,
-			UserID:    userID,
-			WalletID:  wallet.ID,
-			Amount:    amount,
-			Currency:  fmt.Sprintf("%s->%s", from, to),
-			Timestamp: time.Now(),
-			Details:   "",
+	if err := checkCovers(wallet, amount, fee.Amount); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.getRate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := amount.Convert(to, rate, models.RoundDown)
+	if err != nil {
+		return nil, err
+	}
+	if !converted.IsPositive() {
+		return nil, models.ErrInvalidAmount
+	}
+
+	quote := &models.ExchangeQuote{
+		UserID:    userID,
+		WalletID:  wallet.ID,
+		Amount:    amount,
+		Converted: converted,
+		Rate:      rate,
+		Fee:       fee.Amount,
+	}
+
+	if err := s.quoteRepo.CreateQuote(ctx, quote, s.quoteTTL); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// ExchangeByQuote исполняет обмен по зафиксированному в котировке курсу.
+// Котировка должна принадлежать пользователю, не истечь и не быть использованной.
+func (s *WalletService) ExchangeByQuote(ctx context.Context, userID, quoteID uuid.UUID) (*models.Wallet, *models.ExchangeQuote, error) {
+	return s.ExchangeByQuoteIn(ctx, userID, uuid.Nil, quoteID)
+}
+
+// ExchangeByQuoteIn исполняет котировку, выданную для кошелька walletID.
+// Котировку другого кошелька пользователя считает ненайденной. Если walletID
+// не задан, исполняет котировку любого кошелька пользователя.
+func (s *WalletService) ExchangeByQuoteIn(ctx context.Context, userID, walletID, quoteID uuid.UUID) (*models.Wallet, *models.ExchangeQuote, error) {
+	release := s.gate()
+	defer release()
+
+	quote, err := s.quoteRepo.GetQuote(ctx, quoteID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if walletID != uuid.Nil && quote.WalletID != walletID {
+		return nil, nil, models.ErrQuoteNotFound
+	}
+	if quote.UsedAt != nil {
+		return nil, nil, models.ErrQuoteUsed
+	}
+
+	from, to := quote.Amount.Currency, quote.Converted.Currency
+	evt := largeOperationEvent(models.Exchange, quote.Amount, fmt.Sprintf("%s->%s", from, to))
+
+	// Комиссия фиксируется в котировке вместе с курсом.
+	fee := models.FeeCharge{Amount: quote.Fee}
+
+	wallet, err := s.walletRepo.ExchangeWallet(ctx, quote.WalletID, quote.Amount, quote.Converted, fee, quote.Rate, quote.ID, evt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wallet, quote, nil
+}
+
+// largeOperationEvent возвращает событие для крупной операции или nil.
+// Событие сохраняется в outbox вместе с изменением баланса.
+func largeOperationEvent(event models.EventType, amount models.Money, currency string) *models.EventMessage {
+	if !models.IsLargeAmount(amount) {
+		return nil
+	}
+
+	return &models.EventMessage{
+		EventID:   uuid.New(),
+		Event:     event,
+		Amount:    amount,
+		Currency:  currency,
+		Timestamp: time.Now(),
+	}
+}
+
+// getRate возвращает курс from->to из кэша, обновляя кэш при промахе.
+func (s *WalletService) getRate(ctx context.Context, from, to models.Currency) (models.Decimal, error) {
+	rate, exists := s.rateCache.GetRate(string(from), string(to))
+	if !exists {
+		if err := s.SyncCurrencies(ctx); err != nil {
+			return models.Decimal{}, err
+		}
+
+		rate, exists = s.rateCache.GetRate(string(from), string(to))
+		if !exists {
+			return models.Decimal{}, fmt.Errorf("rate form %s to %s not found even after refresh", from, to)
 		}
-		_ = s.producer.Publish(ctx, userID.String(), evt)
 	}
 
-	return updatedWallet, nil
+	return models.DecimalFromFloat(rate)
 }
 
 func (s *WalletService) createCacheRates(rates []*exchange.GetRateResponse) map[string]float64 {
@@ -0,0 +1,605 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AdminRepo struct {
+	db storages.DB
+}
+
+func NewAdminRepo(db storages.DB) storages.AdminStorage {
+	return &AdminRepo{db: db}
+}
+
+// GetWallet возвращает любой кошелёк с балансами, независимо от владельца.
+func (r *AdminRepo) GetWallet(ctx context.Context, walletID uuid.UUID) (*models.Wallet, error) {
+	wallet, err := loadWallet(ctx, r.db, walletID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+
+	return wallet, err
+}
+
+// SetWalletStatus переводит кошелёк в статус status. Переход, запись
+// в истории статусов и запись аудита сохраняются в одной транзакции.
+// Закрыть можно только кошелёк без средств.
+func (r *AdminRepo) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.AccountStatus, entry *models.AuditEntry) (*models.Wallet, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строки дожидается операций, уже начатых с кошельком.
+	var current models.AccountStatus
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT status, user_id FROM wallets WHERE id = $1 FOR NO KEY UPDATE`,
+		walletID,
+	).Scan(&current, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !current.CanTransitionTo(status) {
+		return nil, models.ErrInvalidStatusTransition
+	}
+	if status == models.StatusClosed {
+		if err := checkWalletsEmpty(ctx, tx, walletID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE wallets SET status = $1, updated_at = NOW() WHERE id = $2`,
+		string(status), walletID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertStatusTransition(ctx, tx, &models.StatusTransition{
+		UserID: userID, WalletID: &walletID, From: current, To: status,
+		ActorID: entry.ActorID, Reason: entry.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.WalletID, entry.TargetUserID = &wallet.ID, &wallet.UserID
+	entry.Details = map[string]any{"from": current, "to": status}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// SetAccountStatus переводит учётную запись пользователя в статус status.
+// Кошельки пользователя блокируются, чтобы смена статуса дождалась начатых
+// операций, а следующие увидели новый статус. Закрыть можно только учётную
+// запись, на кошельках которой не осталось средств.
+func (r *AdminRepo) SetAccountStatus(ctx context.Context, userID uuid.UUID, status models.AccountStatus, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var current models.AccountStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM users WHERE id = $1 FOR NO KEY UPDATE`,
+		userID,
+	).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	if !current.CanTransitionTo(status) {
+		return models.ErrInvalidStatusTransition
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM wallets WHERE user_id = $1 ORDER BY id FOR NO KEY UPDATE`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	var walletIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		walletIDs = append(walletIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if status == models.StatusClosed {
+		if err := checkWalletsEmpty(ctx, tx, walletIDs...); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET status = $1 WHERE id = $2`, string(status), userID)
+	if err != nil {
+		return err
+	}
+
+	err = insertStatusTransition(ctx, tx, &models.StatusTransition{
+		UserID: userID, From: current, To: status, ActorID: entry.ActorID, Reason: entry.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	entry.TargetUserID = &userID
+	entry.Details = map[string]any{"from": current, "to": status}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListStatusHistory возвращает смены статусов учётной записи и кошельков
+// пользователя, новые первыми.
+func (r *AdminRepo) ListStatusHistory(ctx context.Context, userID uuid.UUID) ([]*models.StatusTransition, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, user_id, wallet_id, from_status, to_status, actor_id, reason, created_at
+		FROM status_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]*models.StatusTransition, 0)
+	for rows.Next() {
+		var t models.StatusTransition
+		err := rows.Scan(&t.ID, &t.UserID, &t.WalletID, &t.From, &t.To, &t.ActorID, &t.Reason, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, &t)
+	}
+
+	return history, rows.Err()
+}
+
+// AdjustWallet изменяет баланс кошелька на delta в обход пользовательских
+// проверок: корректировка проходит и для замороженного кошелька. Списание
+// не может затронуть зарезервированную холдами часть баланса.
+func (r *AdminRepo) AdjustWallet(ctx context.Context, walletID uuid.UUID, delta models.Money, entry *models.AuditEntry) (*models.Wallet, error) {
+	if delta.Amount == 0 {
+		return nil, models.ErrInvalidAmount
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Заморозка и блокировка списаний корректировку не запрещают, поэтому
+	// кошелёк блокируется без lockWallet. Закрытый кошелёк не меняется.
+	var status models.AccountStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM wallets WHERE id = $1 FOR NO KEY UPDATE`,
+		walletID,
+	).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == models.StatusClosed {
+		return nil, models.ErrWalletClosed
+	}
+
+	balance, err := lockBalance(ctx, tx, walletID, delta.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &models.Transaction{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     models.TransactionAdjustmentIn,
+		Amount:   delta,
+	}
+	if delta.Amount < 0 {
+		if balance.available() < -delta.Amount {
+			return nil, models.ErrInsufficientFunds
+		}
+		operation.Type = models.TransactionAdjustmentOut
+		operation.Amount.Amount = -delta.Amount
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          operation.Type,
+		TransactionID: operation.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(walletID, delta.Currency), Amount: delta.Amount},
+			{Account: models.SystemAccount(models.AccountAdjustment, delta.Currency), Amount: -delta.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	operation.UserID = wallet.UserID
+	if err := insertTransaction(ctx, tx, operation); err != nil {
+		return nil, err
+	}
+
+	entry.WalletID, entry.TargetUserID = &wallet.ID, &wallet.UserID
+	if entry.Details == nil {
+		entry.Details = make(map[string]any)
+	}
+	entry.Details["transaction_id"] = operation.ID
+	entry.Details["currency"] = delta.Currency
+	entry.Details["amount"] = delta.Decimal().String()
+
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// SetUserRole назначает пользователю роль и сохраняет запись аудита.
+func (r *AdminRepo) SetUserRole(ctx context.Context, userID uuid.UUID, role models.Role, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous models.Role
+	err = tx.QueryRow(ctx,
+		`SELECT role FROM users WHERE id = $1 FOR UPDATE`,
+		userID,
+	).Scan(&previous)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET role = $1 WHERE id = $2`, string(role), userID)
+	if err != nil {
+		return err
+	}
+
+	entry.TargetUserID = &userID
+	entry.Details = map[string]any{"from": previous, "to": role}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UnlockLogin снимает блокировку входа по имени пользователя и сбрасывает
+// счётчик неудачных попыток. Возвращает, был ли пользователь заблокирован.
+// Блокировки по IP не снимаются: адрес может быть общим для многих клиентов.
+func (r *AdminRepo) UnlockLogin(ctx context.Context, userID uuid.UUID, username string, entry *models.AuditEntry) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	locked, err := clearLoginFailures(ctx, tx, models.LoginSubject{Scope: models.LoginScopeUsername, Value: username})
+	if err != nil {
+		return false, err
+	}
+
+	entry.TargetUserID = &userID
+	entry.Details = map[string]any{"was_locked": locked}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return false, err
+	}
+
+	return locked, tx.Commit(ctx)
+}
+
+// SetSpendingLimit устанавливает лимит пользователя или, если UserID nil,
+// системный лимит по умолчанию. Запись аудита хранит прежнее значение.
+func (r *AdminRepo) SetSpendingLimit(ctx context.Context, limit *models.SpendingLimit, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	previous, err := deleteSpendingLimit(ctx, tx, limit.UserID, limit.Operation, limit.Amount.Currency, limit.Period)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO spending_limits (id, user_id, operation, currency, period, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING updated_at`,
+		uuid.New(), limit.UserID, string(limit.Operation), string(limit.Amount.Currency), string(limit.Period),
+		limit.Amount.Amount,
+	).Scan(&limit.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	entry.TargetUserID = limit.UserID
+	entry.Details = limitAuditDetails(limit.Operation, limit.Amount.Currency, limit.Period, previous)
+	entry.Details["to"] = limit.Amount.String()
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveSpendingLimit удаляет лимит пользователя, после чего снова действует
+// системный, или системный лимит, после чего операция не ограничена.
+func (r *AdminRepo) RemoveSpendingLimit(ctx context.Context, userID *uuid.UUID, operation models.LimitOperation, currency models.Currency, period models.LimitPeriod, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	previous, err := deleteSpendingLimit(ctx, tx, userID, operation, currency, period)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return models.ErrLimitNotFound
+	}
+
+	entry.TargetUserID = userID
+	entry.Details = limitAuditDetails(operation, currency, period, previous)
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// deleteSpendingLimit удаляет лимит и возвращает его прежнее значение
+// или nil, если лимита не было.
+func deleteSpendingLimit(ctx context.Context, q querier, userID *uuid.UUID, operation models.LimitOperation, currency models.Currency, period models.LimitPeriod) (*models.Money, error) {
+	amount := models.Money{Currency: currency}
+	err := q.QueryRow(ctx,
+		`DELETE FROM spending_limits
+		WHERE user_id IS NOT DISTINCT FROM $1 AND operation = $2 AND currency = $3 AND period = $4
+		RETURNING amount`,
+		userID, string(operation), string(currency), string(period),
+	).Scan(&amount.Amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &amount, nil
+}
+
+func limitAuditDetails(operation models.LimitOperation, currency models.Currency, period models.LimitPeriod, previous *models.Money) map[string]any {
+	details := map[string]any{"operation": operation, "currency": currency, "period": period}
+	if previous != nil {
+		details["from"] = previous.String()
+	}
+	return details
+}
+
+// SetFeeRule создаёт правило комиссии или заменяет правило той же операции,
+// пары валют и ступени оборота.
+func (r *AdminRepo) SetFeeRule(ctx context.Context, rule *models.FeeRule, entry *models.AuditEntry) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var to *string
+	if rule.ToCurrency != "" {
+		s := string(rule.ToCurrency)
+		to = &s
+	}
+	var maxFee *int64
+	if rule.Max != nil {
+		maxFee = &rule.Max.Amount
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO fee_rules (id, operation, from_currency, to_currency, percent, fixed, min_fee, max_fee, min_volume)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (operation, from_currency, COALESCE(to_currency, ''), min_volume) DO UPDATE
+		SET percent = EXCLUDED.percent, fixed = EXCLUDED.fixed, min_fee = EXCLUDED.min_fee,
+			max_fee = EXCLUDED.max_fee, updated_at = NOW()
+		RETURNING id, updated_at`,
+		uuid.New(), string(rule.Operation), string(rule.FromCurrency), to, rule.Percent.String(),
+		rule.Fixed.Amount, rule.Min.Amount, maxFee, rule.MinVolume.Amount,
+	).Scan(&rule.ID, &rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	entry.Details = feeAuditDetails(rule)
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteFeeRule удаляет правило комиссии. Запись аудита хранит удалённое правило.
+func (r *AdminRepo) DeleteFeeRule(ctx context.Context, id uuid.UUID, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`DELETE FROM fee_rules WHERE id = $1
+		RETURNING `+feeRuleColumns,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	rules, err := scanFeeRules(rows)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return models.ErrFeeRuleNotFound
+	}
+
+	entry.Details = feeAuditDetails(rules[0])
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func feeAuditDetails(rule *models.FeeRule) map[string]any {
+	details := map[string]any{
+		"rule_id":       rule.ID,
+		"operation":     rule.Operation,
+		"from_currency": rule.FromCurrency,
+		"percent":       rule.Percent.String(),
+		"fixed":         rule.Fixed.String(),
+		"min":           rule.Min.String(),
+		"min_volume":    rule.MinVolume.String(),
+	}
+	if rule.ToCurrency != "" {
+		details["to_currency"] = rule.ToCurrency
+	}
+	if rule.Max != nil {
+		details["max"] = rule.Max.String()
+	}
+	return details
+}
+
+// ListAuditEntries возвращает записи аудита, начиная с самых новых.
+func (r *AdminRepo) ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, actor_id, action, target_user_id, wallet_id, reason, details, created_at
+		FROM audit_log
+		WHERE $1::UUID IS NULL OR target_user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		filter.TargetUserID, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetUserID, &e.WalletID,
+			&e.Reason, &e.Details, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// checkWalletsEmpty возвращает ErrWalletNotEmpty, если на кошельках
+// остались средства. Резерв холдов входит в баланс, поэтому проверяется
+// только он.
+func checkWalletsEmpty(ctx context.Context, q querier, walletIDs ...uuid.UUID) error {
+	var funded bool
+	err := q.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM wallet_balances WHERE wallet_id = ANY($1) AND amount_minor <> 0)`,
+		walletIDs,
+	).Scan(&funded)
+	if err != nil {
+		return err
+	}
+	if funded {
+		return models.ErrWalletNotEmpty
+	}
+
+	return nil
+}
+
+func insertStatusTransition(ctx context.Context, q querier, t *models.StatusTransition) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	return q.QueryRow(ctx,
+		`INSERT INTO status_history (id, user_id, wallet_id, from_status, to_status, actor_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		t.ID, t.UserID, t.WalletID, string(t.From), string(t.To), t.ActorID, t.Reason,
+	).Scan(&t.CreatedAt)
+}
+
+func insertAudit(ctx context.Context, q querier, entry *models.AuditEntry) error {
+	if entry.Reason == "" {
+		return models.ErrReasonRequired
+	}
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+
+	return q.QueryRow(ctx,
+		`INSERT INTO audit_log (id, actor_id, action, target_user_id, wallet_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		entry.ID, entry.ActorID, string(entry.Action), entry.TargetUserID, entry.WalletID,
+		entry.Reason, entry.Details,
+	).Scan(&entry.CreatedAt)
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyRepo struct {
+	db storages.DB
+}
+
+func NewAPIKeyRepo(db storages.DB) storages.APIKeyStorage {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, allowed_ips,
+	expires_at, last_used_at, created_at, revoked_at`
+
+// CreateAPIKey сохраняет ключ. Нулевой ttl означает бессрочный ключ.
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key *models.APIKey, ttl time.Duration) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+
+	var expiresIn *float64
+	if ttl > 0 {
+		secs := ttl.Seconds()
+		expiresIn = &secs
+	}
+
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	return r.db.QueryRow(ctx,
+		`INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW() + make_interval(secs => $8))
+		RETURNING expires_at, created_at`,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, scopeStrings(key.Scopes), allowedIPs, expiresIn,
+	).Scan(&key.ExpiresAt, &key.CreatedAt)
+}
+
+// GetActiveAPIKey ищет ключ по хэшу. Отозванные и истёкшие ключи не находятся.
+func (r *APIKeyRepo) GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(ctx,
+		`SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > NOW())`,
+		keyHash,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey отзывает ключ пользователя. Повторный отзыв не ошибка.
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes []string
+
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &key.AllowedIPs,
+		&key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]models.APIKeyScope, len(scopes))
+	for i, s := range scopes {
+		key.Scopes[i] = models.APIKeyScope(s)
+	}
+
+	return &key, nil
+}
+
+func scopeStrings(scopes []models.APIKeyScope) []string {
+	out := make([]string, len(scopes))
+	for i, s := range scopes {
+		out[i] = string(s)
+	}
+	return out
+}
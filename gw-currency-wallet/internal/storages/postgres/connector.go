@@ -36,19 +36,34 @@ func NewPostgres(dsn string, maxConns, minConns int32, connLifetime time.Duratio
 	return &PostgresDB{Pool: pool}, nil
 }
 
+// Exec, Query, QueryRow и Begin выполняются в транзакции UnitOfWork,
+// если она открыта в ctx, иначе — на соединении из пула.
 func (db *PostgresDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
 	return db.Pool.Exec(ctx, sql, args...)
 }
 
 func (db *PostgresDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
 	return db.Pool.Query(ctx, sql, args...)
 }
 
 func (db *PostgresDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
 	return db.Pool.QueryRow(ctx, sql, args...)
 }
 
+// Begin внутри транзакции UnitOfWork открывает точку сохранения.
 func (db *PostgresDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Begin(ctx)
+	}
 	return db.Pool.Begin(ctx)
 }
 
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+)
+
+type CurrencyRepo struct {
+	db storages.DB
+}
+
+func NewCurrencyRepo(db storages.DB) storages.CurrencyStorage {
+	return &CurrencyRepo{db: db}
+}
+
+// ListCurrencies возвращает все валюты из реестра, включая отключённые.
+func (r *CurrencyRepo) ListCurrencies(ctx context.Context) ([]*models.CurrencyInfo, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT code, minor_units, symbol, enabled, created_at
+		FROM currencies ORDER BY code`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []*models.CurrencyInfo
+	for rows.Next() {
+		var c models.CurrencyInfo
+		if err := rows.Scan(&c.Code, &c.MinorUnits, &c.Symbol, &c.Enabled, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+// EnsureCurrencies добавляет в реестр валюты, которых там ещё нет.
+func (r *CurrencyRepo) EnsureCurrencies(ctx context.Context, codes []models.Currency) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(codes))
+	for _, code := range codes {
+		values = append(values, string(code))
+	}
+
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO currencies (code)
+		SELECT UNNEST($1::VARCHAR[])
+		ON CONFLICT (code) DO NOTHING`,
+		values,
+	)
+	return err
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type EmailTokenRepo struct {
+	db storages.DB
+}
+
+func NewEmailTokenRepo(db storages.DB) storages.EmailTokenStorage {
+	return &EmailTokenRepo{db: db}
+}
+
+// CreateEmailToken сохраняет токен со сроком действия ttl. Неиспользованные
+// токены пользователя с тем же назначением перестают действовать.
+func (r *EmailTokenRepo) CreateEmailToken(ctx context.Context, token *models.EmailToken, ttl time.Duration) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM email_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		token.UserID, string(token.Purpose),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO email_tokens (id, user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		RETURNING expires_at, created_at`,
+		token.ID, token.UserID, string(token.Purpose), token.TokenHash, ttl.Seconds(),
+	).Scan(&token.ExpiresAt, &token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// VerifyEmail гасит токен подтверждения и отмечает почту владельца подтверждённой.
+func (r *EmailTokenRepo) VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := useEmailToken(ctx, tx, tokenHash, models.EmailVerification)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := markEmailVerified(ctx, tx, userID); err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, tx.Commit(ctx)
+}
+
+// ResetPassword гасит токен сброса и заменяет хэш пароля владельца. Все его
+// сессии завершаются, а блокировка входа по имени снимается. Раз письмо
+// дошло до владельца, его почта заодно считается подтверждённой.
+func (r *EmailTokenRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := useEmailToken(ctx, tx, tokenHash, models.PasswordReset)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var username string
+	err = tx.QueryRow(ctx,
+		`UPDATE users SET password_hash = $1 WHERE id = $2 RETURNING username`,
+		passwordHash, userID,
+	).Scan(&username)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := markEmailVerified(ctx, tx, userID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := revokeFamilies(ctx, tx, `user_id = $1`, userID); err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = clearLoginFailures(ctx, tx, models.LoginSubject{Scope: models.LoginScopeUsername, Value: username})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, tx.Commit(ctx)
+}
+
+// useEmailToken помечает действующий токен использованным и возвращает его владельца.
+func useEmailToken(ctx context.Context, q querier, tokenHash string, purpose models.EmailTokenPurpose) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := q.QueryRow(ctx,
+		`UPDATE email_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		tokenHash, string(purpose),
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, models.ErrInvalidEmailToken
+	}
+
+	return userID, err
+}
+
+func markEmailVerified(ctx context.Context, q querier, userID uuid.UUID) error {
+	_, err := q.Exec(ctx,
+		`UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email_verified_at IS NULL`,
+		userID,
+	)
+	return err
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type FeeRepo struct {
+	db storages.DB
+}
+
+func NewFeeRepo(db storages.DB) storages.FeeStorage {
+	return &FeeRepo{db: db}
+}
+
+const feeRuleColumns = `id, operation, from_currency, COALESCE(to_currency, ''), trim_scale(percent)::TEXT,
+	fixed, min_fee, max_fee, min_volume, updated_at`
+
+// ListFeeRules возвращает все правила комиссий.
+func (r *FeeRepo) ListFeeRules(ctx context.Context) ([]*models.FeeRule, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		ORDER BY operation, from_currency, to_currency NULLS FIRST, min_volume`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFeeRules(rows)
+}
+
+// FindFeeRules возвращает правила операции operation для валюты списания from
+// со всеми ступенями оборота.
+func (r *FeeRepo) FindFeeRules(ctx context.Context, operation models.FeeOperation, from models.Currency) ([]*models.FeeRule, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		WHERE operation = $1 AND from_currency = $2`,
+		string(operation), string(from),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFeeRules(rows)
+}
+
+// MonthlyVolume возвращает сумму операций operation пользователя в валюте
+// currency с начала текущего календарного месяца. Списания холдов входят
+// в оборот вывода.
+func (r *FeeRepo) MonthlyVolume(ctx context.Context, userID uuid.UUID, operation models.FeeOperation, currency models.Currency) (int64, error) {
+	var volume int64
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0)::BIGINT FROM transactions
+		WHERE user_id = $1 AND from_currency = $3
+			AND (type = $2 OR $2 = 'withdraw' AND type = 'hold_capture')
+			AND created_at >= date_trunc('month', NOW())`,
+		userID, string(operation), string(currency),
+	).Scan(&volume)
+
+	return volume, err
+}
+
+// SettleFees зачисляет остаток счёта комиссий на кошелёк заведения
+// houseWalletID и возвращает число валют, по которым прошло зачисление.
+// Пока статус кошелька не допускает зачислений, комиссии остаются на счёте
+// комиссий; валюты, отключённые в реестре, пропускаются.
+func (r *FeeRepo) SettleFees(ctx context.Context, houseWalletID uuid.UUID) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка кошелька выстраивает параллельные зачисления в очередь,
+	// поэтому остаток счёта читается уже после предыдущего зачисления.
+	if err := lockWallet(ctx, tx, houseWalletID, creditAccess); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT a.currency, SUM(l.amount)::BIGINT
+		FROM ledger_accounts a
+		JOIN journal_lines l ON l.account_id = a.id
+		WHERE a.kind = $1 AND a.wallet_id IS NULL
+		GROUP BY a.currency
+		HAVING SUM(l.amount) > 0
+		ORDER BY a.currency`,
+		string(models.AccountFees),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var collected []models.Money
+	for rows.Next() {
+		var amount models.Money
+		var currency string
+		if err := rows.Scan(&currency, &amount.Amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		amount.Currency = models.Currency(currency)
+		collected = append(collected, amount)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, amount := range collected {
+		_, err := lockBalance(ctx, tx, houseWalletID, amount.Currency)
+		if errors.Is(err, models.ErrUnsupportedCurrency) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		err = postJournal(ctx, tx, &models.JournalEntry{
+			Type: models.TransactionFeeSettlement,
+			Lines: []models.JournalLine{
+				{Account: models.SystemAccount(models.AccountFees, amount.Currency), Amount: -amount.Amount},
+				{Account: models.WalletAccount(houseWalletID, amount.Currency), Amount: amount.Amount},
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		settled++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return settled, nil
+}
+
+func scanFeeRules(rows pgx.Rows) ([]*models.FeeRule, error) {
+	defer rows.Close()
+
+	rules := make([]*models.FeeRule, 0)
+	for rows.Next() {
+		var rule models.FeeRule
+		var to, percent string
+		var maxFee *int64
+
+		err := rows.Scan(&rule.ID, &rule.Operation, &rule.FromCurrency, &to, &percent,
+			&rule.Fixed.Amount, &rule.Min.Amount, &maxFee, &rule.MinVolume.Amount, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		rule.ToCurrency = models.Currency(to)
+		rule.Percent, err = models.ParseDecimal(percent)
+		if err != nil {
+			return nil, err
+		}
+
+		from := rule.FromCurrency
+		rule.Fixed.Currency, rule.Min.Currency, rule.MinVolume.Currency = from, from, from
+		if maxFee != nil {
+			rule.Max = &models.Money{Currency: from, Amount: *maxFee}
+		}
+
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// feeAmount возвращает комиссию для записи в историю операций или nil,
+// если операция бесплатна.
+func feeAmount(fee models.FeeCharge) *models.Money {
+	if fee.Amount.Amount == 0 {
+		return nil
+	}
+	return &fee.Amount
+}
+
+// chargeFee переводит комиссию с кошелька walletID на счёт комиссий
+// в рамках операции transactionID. Кошелёк заведения здесь не блокируется:
+// собранное зачисляет на него SettleFees, поэтому операции с комиссией
+// не выстраиваются в очередь за одной строкой баланса.
+func chargeFee(ctx context.Context, q querier, walletID uuid.UUID, fee models.FeeCharge, entryType models.TransactionType, transactionID uuid.UUID) error {
+	if fee.Amount.Amount == 0 {
+		return nil
+	}
+
+	return postJournal(ctx, q, &models.JournalEntry{
+		Type:          entryType,
+		TransactionID: transactionID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(walletID, fee.Amount.Currency), Amount: -fee.Amount.Amount},
+			{Account: models.SystemAccount(models.AccountFees, fee.Amount.Currency), Amount: fee.Amount.Amount},
+		},
+	})
+}
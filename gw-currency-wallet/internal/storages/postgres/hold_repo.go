@@ -0,0 +1,364 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const holdColumns = `id, user_id, wallet_id, order_id, currency, amount, captured, status, expires_at, created_at, updated_at`
+
+// CreateHold резервирует hold.Amount на кошельке hold.WalletID на время ttl.
+// Резерв уменьшает доступный баланс, но не общий. Холд с hold.OrderID
+// принадлежит заявке и снимается только через VoidOrderHold.
+func (r *WalletRepo) CreateHold(ctx context.Context, hold *models.Hold, ttl time.Duration) error {
+	if !hold.Amount.IsPositive() {
+		return models.ErrInvalidAmount
+	}
+	if hold.ID == uuid.Nil {
+		hold.ID = uuid.New()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	currency := hold.Amount.Currency
+
+	if err := lockWallet(ctx, tx, hold.WalletID, debitAccess); err != nil {
+		return err
+	}
+
+	balance, err := lockBalance(ctx, tx, hold.WalletID, currency)
+	if err != nil {
+		return err
+	}
+
+	if balance.available() < hold.Amount.Amount {
+		return models.ErrInsufficientFunds
+	}
+
+	if err := setHeld(ctx, tx, hold.WalletID, currency, balance.held+hold.Amount.Amount); err != nil {
+		return err
+	}
+
+	row := tx.QueryRow(ctx,
+		`INSERT INTO holds (id, user_id, wallet_id, order_id, currency, amount, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW() + make_interval(secs => $8))
+		RETURNING `+holdColumns,
+		hold.ID, hold.UserID, hold.WalletID, hold.OrderID, string(currency), hold.Amount.Amount,
+		string(models.HoldActive), ttl.Seconds(),
+	)
+	err = scanHold(row, hold)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetHold возвращает холд пользователя. Чужие холды не видны.
+func (r *WalletRepo) GetHold(ctx context.Context, holdID, userID uuid.UUID) (*models.Hold, error) {
+	var hold models.Hold
+	row := r.db.QueryRow(ctx,
+		`SELECT `+holdColumns+` FROM holds WHERE id = $1 AND user_id = $2`,
+		holdID, userID,
+	)
+	err := scanHold(row, &hold)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// CaptureHold списывает amount из активного холда. Холд может списываться частями;
+// когда резерв исчерпан, холд переходит в статус captured.
+// Если evt не nil, событие записывается в outbox в той же транзакции.
+// Холд заявки списать нельзя.
+func (r *WalletRepo) CaptureHold(ctx context.Context, holdID, userID uuid.UUID, amount models.Money, fee models.FeeCharge, evt *models.EventMessage) (*models.Hold, error) {
+	if !amount.IsPositive() || fee.Amount.Amount < 0 || fee.Amount.Amount > 0 && fee.Amount.Currency != amount.Currency {
+		return nil, models.ErrInvalidAmount
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	hold, err := lockActiveHold(ctx, tx, holdID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.OrderID != nil {
+		return nil, models.ErrHoldOwnedByOrder
+	}
+
+	if amount.Currency != hold.Amount.Currency {
+		return nil, models.ErrCurrencyMismatch
+	}
+	if amount.Amount > hold.Remaining().Amount {
+		return nil, models.ErrCaptureExceedsHold
+	}
+
+	if err := lockWallet(ctx, tx, hold.WalletID, debitAccess); err != nil {
+		return nil, err
+	}
+
+	balance, err := lockBalance(ctx, tx, hold.WalletID, amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Комиссия не входит в резерв и берётся из доступной части баланса,
+	// которая после списания холда не меняется.
+	if balance.available() < fee.Amount.Amount {
+		return nil, models.ErrInsufficientFunds
+	}
+
+	// Списание холда выводит деньги и расходует лимит вывода.
+	if err := checkLimits(ctx, tx, hold.WalletID, models.LimitWithdraw, amount); err != nil {
+		return nil, err
+	}
+
+	operation := &models.Transaction{
+		ID:       uuid.New(),
+		UserID:   hold.UserID,
+		WalletID: hold.WalletID,
+		Type:     models.TransactionHoldCapture,
+		Amount:   amount,
+		Fee:      feeAmount(fee),
+	}
+
+	// Сначала снимается резерв, иначе held_minor на мгновение превысит баланс.
+	if err := setHeld(ctx, tx, hold.WalletID, amount.Currency, balance.held-amount.Amount); err != nil {
+		return nil, err
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          operation.Type,
+		TransactionID: operation.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(hold.WalletID, amount.Currency), Amount: -amount.Amount},
+			{Account: models.SystemAccount(models.AccountCashOut, amount.Currency), Amount: amount.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chargeFee(ctx, tx, hold.WalletID, fee, operation.Type, operation.ID); err != nil {
+		return nil, err
+	}
+
+	hold.Captured.Amount += amount.Amount
+	status := models.HoldActive
+	if hold.Remaining().Amount == 0 {
+		status = models.HoldCaptured
+	}
+
+	if err := updateHold(ctx, tx, hold, status); err != nil {
+		return nil, err
+	}
+
+	if err := insertTransaction(ctx, tx, operation); err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, hold.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, evt, wallet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// VoidHold отменяет активный холд и возвращает остаток резерва в доступный баланс.
+// Холд заявки отменить нельзя: его снимает VoidOrderHold.
+func (r *WalletRepo) VoidHold(ctx context.Context, holdID, userID uuid.UUID) (*models.Hold, error) {
+	return r.voidHold(ctx, holdID, userID, nil)
+}
+
+// VoidOrderHold отменяет холд, которым заявка orderID резервирует сумму.
+func (r *WalletRepo) VoidOrderHold(ctx context.Context, holdID, userID, orderID uuid.UUID) (*models.Hold, error) {
+	return r.voidHold(ctx, holdID, userID, &orderID)
+}
+
+// voidHold отменяет холд, принадлежащий заявке orderID, или холд без заявки,
+// если orderID равен nil.
+func (r *WalletRepo) voidHold(ctx context.Context, holdID, userID uuid.UUID, orderID *uuid.UUID) (*models.Hold, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	hold, err := lockActiveHold(ctx, tx, holdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case orderID == nil && hold.OrderID != nil:
+		return nil, models.ErrHoldOwnedByOrder
+	case orderID != nil && (hold.OrderID == nil || *hold.OrderID != *orderID):
+		return nil, models.ErrHoldNotFound
+	}
+
+	if err := releaseHold(ctx, tx, hold, models.HoldVoided); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ExpireHolds освобождает не более limit истёкших холдов и возвращает их количество.
+// Каждый холд освобождается в отдельной транзакции, а уже заблокированные
+// строки пропускаются, поэтому несколько экземпляров сервиса не мешают друг другу.
+func (r *WalletRepo) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	expired := 0
+	for expired < limit {
+		ok, err := r.expireHold(ctx)
+		if err != nil {
+			return expired, err
+		}
+		if !ok {
+			break
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (r *WalletRepo) expireHold(ctx context.Context) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var hold models.Hold
+	row := tx.QueryRow(ctx,
+		`SELECT `+holdColumns+` FROM holds
+		WHERE status = $1 AND expires_at <= NOW()
+		ORDER BY expires_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+		string(models.HoldActive),
+	)
+	err = scanHold(row, &hold)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := releaseHold(ctx, tx, &hold, models.HoldExpired); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// lockActiveHold блокирует холд пользователя. Холд с истёкшим сроком
+// не может быть ни списан, ни отменён: его освобождает ExpireHolds.
+func lockActiveHold(ctx context.Context, q querier, holdID, userID uuid.UUID) (*models.Hold, error) {
+	var hold models.Hold
+	var expired bool
+
+	row := q.QueryRow(ctx,
+		`SELECT `+holdColumns+`, expires_at <= NOW() FROM holds
+		WHERE id = $1 AND user_id = $2
+		FOR UPDATE`,
+		holdID, userID,
+	)
+	err := scanHold(row, &hold, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status != models.HoldActive {
+		return nil, models.ErrHoldNotActive
+	}
+	if expired {
+		return nil, models.ErrHoldExpired
+	}
+
+	return &hold, nil
+}
+
+// releaseHold возвращает остаток резерва в доступный баланс и закрывает холд со статусом status.
+// Резерв снимается и в валюте, отключённой после создания холда. Кошелёк
+// блокируется раньше строки баланса, как и в остальных операциях.
+func releaseHold(ctx context.Context, q querier, hold *models.Hold, status models.HoldStatus) error {
+	if err := lockWallet(ctx, q, hold.WalletID, releaseAccess); err != nil {
+		return err
+	}
+
+	remaining := hold.Remaining()
+
+	_, err := q.Exec(ctx,
+		`UPDATE wallet_balances SET held_minor = held_minor - $1, updated_at = NOW()
+		WHERE wallet_id = $2 AND currency = $3`,
+		remaining.Amount, hold.WalletID, string(remaining.Currency),
+	)
+	if err != nil {
+		return err
+	}
+
+	return updateHold(ctx, q, hold, status)
+}
+
+func updateHold(ctx context.Context, q querier, hold *models.Hold, status models.HoldStatus) error {
+	return q.QueryRow(ctx,
+		`UPDATE holds SET captured = $1, status = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING status, updated_at`,
+		hold.Captured.Amount, string(status), hold.ID,
+	).Scan(&hold.Status, &hold.UpdatedAt)
+}
+
+// scanHold читает строку с колонками holdColumns, за которыми следуют extra.
+func scanHold(row pgx.Row, hold *models.Hold, extra ...any) error {
+	var currency string
+	dest := append([]any{
+		&hold.ID, &hold.UserID, &hold.WalletID, &hold.OrderID, &currency,
+		&hold.Amount.Amount, &hold.Captured.Amount, &hold.Status,
+		&hold.ExpiresAt, &hold.CreatedAt, &hold.UpdatedAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	hold.Amount.Currency = models.Currency(currency)
+	hold.Captured.Currency = models.Currency(currency)
+	return nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepo struct {
+	db storages.DB
+}
+
+func NewIdempotencyRepo(db storages.DB) storages.IdempotencyStorage {
+	return &IdempotencyRepo{db: db}
+}
+
+// ReserveIdempotencyKey атомарно занимает ключ. Если ключ уже занят, возвращает
+// существующую запись и false. Незавершённая запись старше lockTimeout
+// помечается брошенной, но новому запросу не передаётся: первый запрос мог
+// успеть провести операцию до сбоя, и повтор выполнил бы её дважды.
+func (r *IdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTimeout time.Duration) (*models.IdempotencyRecord, bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO idempotency_keys (user_id, key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO NOTHING`,
+		userID, key, requestHash,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	var record models.IdempotencyRecord
+	var statusCode *int
+	err = r.db.QueryRow(ctx,
+		`SELECT user_id, key, request_hash, status_code, response_body, created_at, completed_at,
+			completed_at IS NULL AND created_at < NOW() - make_interval(secs => $3)
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`,
+		userID, key, lockTimeout.Seconds(),
+	).Scan(&record.UserID, &record.Key, &record.RequestHash, &statusCode,
+		&record.ResponseBody, &record.CreatedAt, &record.CompletedAt, &record.Abandoned)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+
+	return &record, false, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ на запрос с ключом.
+func (r *IdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, statusCode int, body []byte) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE idempotency_keys
+		SET status_code = $3, response_body = $4, completed_at = NOW()
+		WHERE user_id = $1 AND key = $2`,
+		userID, key, statusCode, body,
+	)
+	return err
+}
+
+// ReleaseIdempotencyKey освобождает незавершённый ключ, чтобы запрос можно было повторить.
+func (r *IdempotencyRepo) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND completed_at IS NULL`,
+		userID, key,
+	)
+	return err
+}
+
+// PurgeIdempotencyKeys удаляет не более limit ключей старше ttl, включая
+// брошенные, и возвращает их количество. После этого ключ можно использовать снова.
+func (r *IdempotencyRepo) PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration, limit int) (int, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM idempotency_keys
+		WHERE (user_id, key) IN (
+			SELECT user_id, key FROM idempotency_keys
+			WHERE created_at < NOW() - make_interval(secs => $1)
+			LIMIT $2
+		)`,
+		ttl.Seconds(), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
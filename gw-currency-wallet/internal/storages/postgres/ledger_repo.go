@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type LedgerRepo struct {
+	db storages.DB
+}
+
+func NewLedgerRepo(db storages.DB) storages.LedgerStorage {
+	return &LedgerRepo{db: db}
+}
+
+// FindBalanceDrift пересчитывает балансы кошельков по строкам журнала
+// и возвращает те, что расходятся с wallet_balances.
+func (r *LedgerRepo) FindBalanceDrift(ctx context.Context) ([]models.BalanceDrift, error) {
+	rows, err := r.db.Query(ctx,
+		`WITH ledger AS (
+			SELECT a.wallet_id, a.currency, SUM(l.amount) AS amount
+			FROM journal_lines l
+			JOIN ledger_accounts a ON a.id = l.account_id
+			WHERE a.kind = $1
+			GROUP BY a.wallet_id, a.currency
+		)
+		SELECT COALESCE(b.wallet_id, l.wallet_id), COALESCE(b.currency, l.currency),
+			COALESCE(b.amount_minor, 0), COALESCE(l.amount, 0)::BIGINT
+		FROM wallet_balances b
+		FULL JOIN ledger l ON l.wallet_id = b.wallet_id AND l.currency = b.currency
+		WHERE COALESCE(b.amount_minor, 0) <> COALESCE(l.amount, 0)
+		ORDER BY 1, 2`,
+		string(models.AccountWallet),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drift []models.BalanceDrift
+	for rows.Next() {
+		var d models.BalanceDrift
+		var currency string
+		if err := rows.Scan(&d.WalletID, &currency, &d.Projected, &d.Ledger); err != nil {
+			return nil, err
+		}
+		d.Currency = models.Currency(currency)
+		drift = append(drift, d)
+	}
+
+	return drift, rows.Err()
+}
+
+// FindUnbalancedEntries возвращает проводки, строки которых не сходятся в ноль.
+func (r *LedgerRepo) FindUnbalancedEntries(ctx context.Context) ([]models.UnbalancedEntry, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT entry_id, currency, SUM(amount)::BIGINT
+		FROM journal_lines
+		GROUP BY entry_id, currency
+		HAVING SUM(amount) <> 0
+		ORDER BY entry_id, currency`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.UnbalancedEntry
+	for rows.Next() {
+		var e models.UnbalancedEntry
+		var currency string
+		if err := rows.Scan(&e.EntryID, &currency, &e.Sum); err != nil {
+			return nil, err
+		}
+		e.Currency = models.Currency(currency)
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// postJournal записывает проводку и применяет строки по счетам кошельков
+// к проекции wallet_balances. Строки балансов должны быть уже заблокированы.
+func postJournal(ctx context.Context, q querier, entry *models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+
+	var transactionID *uuid.UUID
+	if entry.TransactionID != uuid.Nil {
+		transactionID = &entry.TransactionID
+	}
+
+	_, err := q.Exec(ctx,
+		`INSERT INTO journal_entries (id, type, transaction_id) VALUES ($1, $2, $3)`,
+		entry.ID, string(entry.Type), transactionID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range entry.Lines {
+		accountID, err := ledgerAccount(ctx, q, line.Account)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.Exec(ctx,
+			`INSERT INTO journal_lines (entry_id, account_id, currency, amount) VALUES ($1, $2, $3, $4)`,
+			entry.ID, accountID, string(line.Account.Currency), line.Amount,
+		)
+		if err != nil {
+			return err
+		}
+
+		if line.Account.Kind == models.AccountWallet {
+			if err := applyBalance(ctx, q, line.Account.WalletID, line.Account.Currency, line.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ledgerAccount возвращает идентификатор счёта, создавая его при первом обращении.
+func ledgerAccount(ctx context.Context, q querier, ref models.AccountRef) (uuid.UUID, error) {
+	var walletID *uuid.UUID
+	if ref.Kind == models.AccountWallet {
+		walletID = &ref.WalletID
+	}
+
+	var id uuid.UUID
+	err := q.QueryRow(ctx,
+		`SELECT id FROM ledger_accounts
+		WHERE kind = $1 AND wallet_id IS NOT DISTINCT FROM $2 AND currency = $3`,
+		string(ref.Kind), walletID, string(ref.Currency),
+	).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, err
+	}
+
+	// Счёт мог создать параллельный запрос: в этом случае вставка
+	// ничего не делает, и счёт перечитывается.
+	_, err = q.Exec(ctx,
+		`INSERT INTO ledger_accounts (id, kind, wallet_id, currency)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`,
+		uuid.New(), string(ref.Kind), walletID, string(ref.Currency),
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = q.QueryRow(ctx,
+		`SELECT id FROM ledger_accounts
+		WHERE kind = $1 AND wallet_id IS NOT DISTINCT FROM $2 AND currency = $3`,
+		string(ref.Kind), walletID, string(ref.Currency),
+	).Scan(&id)
+
+	return id, err
+}
+
+// applyBalance изменяет проекцию баланса кошелька на delta.
+func applyBalance(ctx context.Context, q querier, walletID uuid.UUID, currency models.Currency, delta int64) error {
+	_, err := q.Exec(ctx,
+		`UPDATE wallet_balances SET amount_minor = amount_minor + $1, updated_at = NOW()
+		WHERE wallet_id = $2 AND currency = $3`,
+		delta, walletID, string(currency),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `UPDATE wallets SET updated_at = NOW() WHERE id = $1`, walletID)
+	return err
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LimitRepo struct {
+	db storages.DB
+}
+
+func NewLimitRepo(db storages.DB) storages.LimitStorage {
+	return &LimitRepo{db: db}
+}
+
+// ListLimitUsage возвращает действующие для пользователя лимиты и потраченные
+// в текущем периоде суммы.
+func (r *LimitRepo) ListLimitUsage(ctx context.Context, userID uuid.UUID) ([]*models.LimitUsage, error) {
+	rows, err := queryLimitUsage(ctx, r.db, userID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*models.LimitUsage, 0, len(rows))
+	for _, row := range rows {
+		limit := models.Money{Currency: row.currency, Amount: row.limit}
+		used := models.Money{Currency: row.currency, Amount: row.used}
+		remaining := models.Money{Currency: row.currency, Amount: max(row.limit-row.used, 0)}
+
+		usage = append(usage, &models.LimitUsage{
+			Operation: row.operation,
+			Currency:  row.currency,
+			Period:    row.period,
+			Limit:     limit.Decimal(),
+			Used:      used.Decimal(),
+			Remaining: remaining.Decimal(),
+			Override:  row.override,
+			ResetsAt:  row.resetsAt,
+		})
+	}
+
+	return usage, nil
+}
+
+// ListSpendingLimits возвращает лимиты пользователя или, если userID nil,
+// системные лимиты по умолчанию.
+func (r *LimitRepo) ListSpendingLimits(ctx context.Context, userID *uuid.UUID) ([]*models.SpendingLimit, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id, operation, currency, period, amount, updated_at
+		FROM spending_limits
+		WHERE user_id IS NOT DISTINCT FROM $1
+		ORDER BY operation, currency, period`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make([]*models.SpendingLimit, 0)
+	for rows.Next() {
+		var limit models.SpendingLimit
+		err := rows.Scan(&limit.UserID, &limit.Operation, &limit.Amount.Currency, &limit.Period,
+			&limit.Amount.Amount, &limit.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, &limit)
+	}
+
+	return limits, rows.Err()
+}
+
+type limitRow struct {
+	operation models.LimitOperation
+	currency  models.Currency
+	period    models.LimitPeriod
+	limit     int64
+	used      int64
+	override  bool
+	resetsAt  time.Time
+}
+
+// queryLimitUsage выбирает лимиты пользователя, а где их нет — системные,
+// вместе с суммой операций за текущий календарный период. Списания холдов и
+// переводы другим пользователям выводят деньги так же, как вывод, и считаются
+// в его лимит. operation и currency, если заданы, сужают выборку.
+func queryLimitUsage(ctx context.Context, q querier, userID uuid.UUID, operation *models.LimitOperation, currency *models.Currency) ([]limitRow, error) {
+	var operationFilter, currencyFilter *string
+	if operation != nil {
+		s := string(*operation)
+		operationFilter = &s
+	}
+	if currency != nil {
+		s := string(*currency)
+		currencyFilter = &s
+	}
+
+	rows, err := q.Query(ctx,
+		`WITH effective AS (
+			SELECT DISTINCT ON (operation, currency, period)
+				operation, currency, period, amount, user_id IS NOT NULL AS override,
+				date_trunc(CASE period WHEN 'daily' THEN 'day' ELSE 'month' END, NOW()) AS starts_at
+			FROM spending_limits
+			WHERE (user_id = $1 OR user_id IS NULL)
+				AND ($2::text IS NULL OR operation = $2)
+				AND ($3::text IS NULL OR currency = $3)
+			ORDER BY operation, currency, period, user_id NULLS LAST
+		)
+		SELECT e.operation, e.currency, e.period, e.amount, e.override,
+			COALESCE((
+				SELECT SUM(t.amount) FROM transactions t
+				WHERE t.user_id = $1 AND t.from_currency = e.currency
+					AND (t.type = e.operation OR e.operation = 'withdraw' AND (t.type = 'hold_capture'
+						OR t.type = 'transfer_out' AND t.counterparty_user_id <> t.user_id))
+					AND t.created_at >= e.starts_at
+			), 0)::BIGINT,
+			e.starts_at + CASE e.period WHEN 'daily' THEN INTERVAL '1 day' ELSE INTERVAL '1 month' END
+		FROM effective e
+		ORDER BY e.operation, e.currency, e.period`,
+		userID, operationFilter, currencyFilter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []limitRow
+	for rows.Next() {
+		var row limitRow
+		err := rows.Scan(&row.operation, &row.currency, &row.period, &row.limit, &row.override,
+			&row.used, &row.resetsAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// checkLimits проверяет, что списание amount операцией operation укладывается
+// в лимиты владельца кошелька. Лимиты общие для всех кошельков пользователя,
+// поэтому до конца транзакции берётся блокировка пользователя: параллельная
+// операция с любым его кошельком не изменит потраченную сумму, и проверка
+// со списанием атомарны.
+func checkLimits(ctx context.Context, q querier, walletID uuid.UUID, operation models.LimitOperation, amount models.Money) error {
+	var userID uuid.UUID
+	if err := q.QueryRow(ctx, `SELECT user_id FROM wallets WHERE id = $1`, walletID).Scan(&userID); err != nil {
+		return err
+	}
+
+	if err := lockUser(ctx, q, userID); err != nil {
+		return err
+	}
+
+	rows, err := queryLimitUsage(ctx, q, userID, &operation, &amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if row.used+amount.Amount > row.limit {
+			return &models.LimitExceededError{
+				Operation: row.operation,
+				Period:    row.period,
+				Remaining: models.Money{Currency: row.currency, Amount: max(row.limit-row.used, 0)},
+			}
+		}
+	}
+
+	return nil
+}
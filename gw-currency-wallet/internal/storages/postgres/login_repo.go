@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type LoginRepo struct {
+	db storages.DB
+}
+
+func NewLoginRepo(db storages.DB) storages.LoginStorage {
+	return &LoginRepo{db: db}
+}
+
+// GetLoginLockout возвращает самую долгую из действующих блокировок subjects
+// или nil, если ни один из них не заблокирован.
+func (r *LoginRepo) GetLoginLockout(ctx context.Context, subjects ...models.LoginSubject) (*models.LoginLockout, error) {
+	var found *models.LoginLockout
+	for _, subject := range subjects {
+		lockout := models.LoginLockout{Subject: subject}
+		var retryAfter float64
+		err := r.db.QueryRow(ctx,
+			`SELECT level, locked_until, EXTRACT(EPOCH FROM locked_until - NOW())::FLOAT8
+			FROM login_lockouts
+			WHERE scope = $1 AND subject = $2 AND locked_until > NOW()`,
+			string(subject.Scope), subject.Value,
+		).Scan(&lockout.Level, &lockout.LockedUntil, &retryAfter)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lockout.RetryAfter = time.Duration(retryAfter * float64(time.Second))
+		if found == nil || lockout.RetryAfter > found.RetryAfter {
+			found = &lockout
+		}
+	}
+
+	return found, nil
+}
+
+// RecordLoginFailure учитывает неудачную попытку входа subject. Если за окно
+// rule.Window набралось rule.MaxFailures попыток, subject блокируется,
+// а событие о блокировке записывается в outbox в той же транзакции.
+// userID попадает в событие и равен uuid.Nil, если пользователь неизвестен.
+func (r *LoginRepo) RecordLoginFailure(ctx context.Context, subject models.LoginSubject, rule models.LockoutRule, userID uuid.UUID) (*models.LoginLockout, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	scope := string(subject.Scope)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO login_lockouts (scope, subject) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`,
+		scope, subject.Value,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Строка блокировки упорядочивает параллельные попытки одного subject.
+	lockout := models.LoginLockout{Subject: subject}
+	var locked, stale bool
+	err = tx.QueryRow(ctx,
+		`SELECT level, COALESCE(locked_until > NOW(), FALSE),
+			COALESCE(locked_until <= NOW() - make_interval(secs => $3), TRUE)
+		FROM login_lockouts
+		WHERE scope = $1 AND subject = $2
+		FOR UPDATE`,
+		scope, subject.Value, rule.ResetAfter.Seconds(),
+	).Scan(&lockout.Level, &locked, &stale)
+	if err != nil {
+		return nil, err
+	}
+
+	// Попытка, начатая до блокировки, не продлевает её.
+	if locked {
+		return nil, tx.Commit(ctx)
+	}
+
+	// Основной запрос не видит строку, вставленную в CTE, поэтому
+	// текущая попытка добавляется к счёту отдельно.
+	var failures int
+	err = tx.QueryRow(ctx,
+		`WITH expired AS (
+			DELETE FROM login_failures
+			WHERE scope = $1 AND subject = $2 AND created_at <= NOW() - make_interval(secs => $3)
+		), inserted AS (
+			INSERT INTO login_failures (scope, subject) VALUES ($1, $2)
+		)
+		SELECT COUNT(*) + 1 FROM login_failures
+		WHERE scope = $1 AND subject = $2 AND created_at > NOW() - make_interval(secs => $3)`,
+		scope, subject.Value, rule.Window.Seconds(),
+	).Scan(&failures)
+	if err != nil {
+		return nil, err
+	}
+
+	if failures < rule.MaxFailures {
+		return nil, tx.Commit(ctx)
+	}
+
+	if stale {
+		lockout.Level = 0
+	}
+	lockout.Level++
+	duration := rule.Duration(lockout.Level)
+
+	err = tx.QueryRow(ctx,
+		`UPDATE login_lockouts SET level = $3, locked_until = NOW() + make_interval(secs => $4)
+		WHERE scope = $1 AND subject = $2
+		RETURNING locked_until`,
+		scope, subject.Value, lockout.Level, duration.Seconds(),
+	).Scan(&lockout.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	lockout.RetryAfter = duration
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM login_failures WHERE scope = $1 AND subject = $2`,
+		scope, subject.Value,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := models.NewLockoutEvent(&lockout, userID, failures)
+	key := subject.Value
+	if userID != uuid.Nil {
+		key = userID.String()
+	}
+	if err := insertOutbox(ctx, tx, key, evt.EventID, evt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &lockout, nil
+}
+
+// ResetLoginFailures забывает неудачные попытки и блокировки subject.
+func (r *LoginRepo) ResetLoginFailures(ctx context.Context, subject models.LoginSubject) error {
+	_, err := clearLoginFailures(ctx, r.db, subject)
+	return err
+}
+
+// clearLoginFailures удаляет попытки и блокировку subject и сообщает,
+// был ли он заблокирован.
+func clearLoginFailures(ctx context.Context, q querier, subject models.LoginSubject) (bool, error) {
+	var locked bool
+	err := q.QueryRow(ctx,
+		`WITH failures AS (
+			DELETE FROM login_failures WHERE scope = $1 AND subject = $2
+		), lockouts AS (
+			DELETE FROM login_lockouts WHERE scope = $1 AND subject = $2
+			RETURNING locked_until
+		)
+		SELECT EXISTS (SELECT 1 FROM lockouts WHERE locked_until > NOW())`,
+		string(subject.Scope), subject.Value,
+	).Scan(&locked)
+
+	return locked, err
+}
@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type OrderRepo struct {
+	db storages.DB
+}
+
+func NewOrderRepo(db storages.DB) storages.OrderStorage {
+	return &OrderRepo{db: db}
+}
+
+const orderColumns = `id, user_id, wallet_id, hold_id, from_currency, to_currency, amount, target_rate::TEXT,
+	fee, status, filled_rate::TEXT, to_amount, COALESCE(error, ''), expires_at, created_at, updated_at`
+
+// CreateOrder сохраняет открытую заявку и событие о ней. Холд заявки должен
+// быть создан в той же транзакции: срок действия заявки совпадает с его сроком.
+func (r *OrderRepo) CreateOrder(ctx context.Context, order *models.LimitOrder, evt *models.EventMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO limit_orders (id, user_id, wallet_id, hold_id, from_currency, to_currency,
+			amount, target_rate, fee, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`,
+		order.ID, order.UserID, order.WalletID, order.HoldID,
+		string(order.Amount.Currency), string(order.ToCurrency),
+		order.Amount.Amount, order.TargetRate.String(), order.Fee.Amount, string(order.Status), order.ExpiresAt,
+	).Scan(&order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertOrderEvent(ctx, tx, evt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListOrders возвращает заявки пользователя, начиная с новых. Если status
+// задан, возвращаются только заявки в этом статусе.
+func (r *OrderRepo) ListOrders(ctx context.Context, userID uuid.UUID, status models.OrderStatus) ([]*models.LimitOrder, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+orderColumns+`
+		FROM limit_orders
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC`,
+		userID, string(status),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOrders(rows)
+}
+
+func (r *OrderRepo) GetOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error) {
+	return r.getOrder(ctx, userID, id, "")
+}
+
+// LockOrder возвращает заявку пользователя и блокирует её строку до конца
+// транзакции из ctx, чтобы отмена не разошлась с исполнением.
+func (r *OrderRepo) LockOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error) {
+	return r.getOrder(ctx, userID, id, " FOR UPDATE")
+}
+
+func (r *OrderRepo) getOrder(ctx context.Context, userID, id uuid.UUID, lock string) (*models.LimitOrder, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+orderColumns+`
+		FROM limit_orders
+		WHERE id = $1 AND user_id = $2`+lock,
+		id, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, models.ErrOrderNotFound
+	}
+
+	return orders[0], nil
+}
+
+// ListTriggeredOrders возвращает до limit открытых и не истёкших заявок
+// from->to, целевой курс которых не выше rate, начиная со старых.
+func (r *OrderRepo) ListTriggeredOrders(ctx context.Context, from, to models.Currency, rate models.Decimal, limit int) ([]*models.LimitOrder, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+orderColumns+`
+		FROM limit_orders
+		WHERE status = 'open' AND from_currency = $1 AND to_currency = $2
+			AND target_rate <= $3::NUMERIC AND expires_at > NOW()
+		ORDER BY created_at
+		LIMIT $4`,
+		string(from), string(to), rate.String(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOrders(rows)
+}
+
+// ClaimOrder блокирует заявку для исполнения по курсу rate и возвращает её
+// актуальное состояние. Возвращает nil, если заявка уже закрыта, истекла,
+// курс до неё не дошёл или её исполняет другой экземпляр сервиса.
+// Вызывается в транзакции из ctx.
+func (r *OrderRepo) ClaimOrder(ctx context.Context, id uuid.UUID, rate models.Decimal) (*models.LimitOrder, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+orderColumns+`
+		FROM limit_orders
+		WHERE id = $1 AND status = 'open' AND target_rate <= $2::NUMERIC AND expires_at > NOW()
+		FOR UPDATE SKIP LOCKED`,
+		id, rate.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := scanOrders(rows)
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+
+	return orders[0], nil
+}
+
+// FinishOrder сохраняет итоговый статус заявки и событие о нём.
+func (r *OrderRepo) FinishOrder(ctx context.Context, order *models.LimitOrder, evt *models.EventMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var filledRate *string
+	var converted *int64
+	if order.FilledRate != nil && order.Converted != nil {
+		rate := order.FilledRate.String()
+		filledRate, converted = &rate, &order.Converted.Amount
+	}
+	var orderError *string
+	if order.Error != "" {
+		orderError = &order.Error
+	}
+
+	err = tx.QueryRow(ctx,
+		`UPDATE limit_orders
+		SET status = $2, filled_rate = $3, to_amount = $4, error = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		order.ID, string(order.Status), filledRate, converted, orderError,
+	).Scan(&order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertOrderEvent(ctx, tx, evt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ExpireOrders закрывает не более limit открытых заявок с истёкшим сроком
+// и возвращает их количество. Резерв заявки освобождается вместе с её холдом,
+// который истекает в то же время.
+func (r *OrderRepo) ExpireOrders(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`UPDATE limit_orders SET status = 'expired', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM limit_orders
+			WHERE status = 'open' AND expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+orderColumns,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, order := range orders {
+		if err := insertOrderEvent(ctx, tx, models.NewOrderEvent(order)); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return len(orders), nil
+}
+
+func insertOrderEvent(ctx context.Context, q querier, evt *models.EventMessage) error {
+	if evt == nil {
+		return nil
+	}
+
+	return insertOutbox(ctx, q, evt.UserID.String(), evt.EventID, evt)
+}
+
+func scanOrders(rows pgx.Rows) ([]*models.LimitOrder, error) {
+	defer rows.Close()
+
+	orders := make([]*models.LimitOrder, 0)
+	for rows.Next() {
+		var o models.LimitOrder
+		var from, to, targetRate string
+		var filledRate *string
+		var converted *int64
+
+		err := rows.Scan(&o.ID, &o.UserID, &o.WalletID, &o.HoldID, &from, &to,
+			&o.Amount.Amount, &targetRate, &o.Fee.Amount, &o.Status, &filledRate, &converted,
+			&o.Error, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		o.Amount.Currency = models.Currency(from)
+		o.Fee.Currency = o.Amount.Currency
+		o.ToCurrency = models.Currency(to)
+
+		o.TargetRate, err = models.ParseDecimal(targetRate)
+		if err != nil {
+			return nil, err
+		}
+		if filledRate != nil && converted != nil {
+			rate, err := models.ParseDecimal(*filledRate)
+			if err != nil {
+				return nil, err
+			}
+			o.FilledRate = &rate
+			o.Converted = &models.Money{Currency: o.ToCurrency, Amount: *converted}
+		}
+
+		orders = append(orders, &o)
+	}
+
+	return orders, rows.Err()
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxRepo struct {
+	db storages.DB
+}
+
+func NewOutboxRepo(db storages.DB) storages.OutboxStorage {
+	return &OutboxRepo{db: db}
+}
+
+// ClaimOutbox выбирает до limit готовых к отправке сообщений в порядке создания
+// и откладывает их повторную выдачу на lease, чтобы параллельные релеи не брали
+// одни и те же строки. Сообщение не выбирается, пока более раннее сообщение
+// с тем же ключом ждёт повторной попытки или отправляется другим релеем:
+// так события одной партиции не обгоняют друг друга.
+func (r *OutboxRepo) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE outbox SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox o
+			WHERE sent_at IS NULL AND next_attempt_at <= NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox e
+					WHERE e.message_key = o.message_key AND e.sent_at IS NULL
+						AND e.created_at < o.created_at AND e.next_attempt_at > NOW()
+				)
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, message_key, payload, attempts, created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*models.OutboxMessage
+	for rows.Next() {
+		var m models.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.Key, &m.Payload, &m.Attempts, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxSent отмечает сообщение как доставленное.
+func (r *OutboxRepo) MarkOutboxSent(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE outbox SET sent_at = NOW(), last_error = NULL WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+// MarkOutboxFailed сохраняет ошибку отправки и время следующей попытки.
+func (r *OutboxRepo) MarkOutboxFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1`,
+		id, reason, nextAttemptAt,
+	)
+	return err
+}
+
+// PostponeOutbox переносит следующую попытку отправки сообщения на
+// nextAttemptAt, не считая её неудачной.
+func (r *OutboxRepo) PostponeOutbox(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE outbox SET next_attempt_at = $2 WHERE id = $1`,
+		id, nextAttemptAt,
+	)
+	return err
+}
+
+// insertOutbox сохраняет событие в outbox через q, в той же транзакции,
+// что и изменение, которое оно описывает.
+func insertOutbox(ctx context.Context, q querier, key string, id uuid.UUID, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx,
+		`INSERT INTO outbox (id, message_key, payload) VALUES ($1, $2, $3)`,
+		id, key, data,
+	)
+	return err
+}
+
+// insertEvent дополняет событие данными кошелька и кладёт его в outbox.
+func insertEvent(ctx context.Context, q querier, evt *models.EventMessage, wallet *models.Wallet) error {
+	if evt == nil {
+		return nil
+	}
+
+	evt.WalletID = wallet.ID
+	evt.UserID = wallet.UserID
+	if evt.EventID == uuid.Nil {
+		evt.EventID = uuid.New()
+	}
+
+	return insertOutbox(ctx, q, evt.UserID.String(), evt.EventID, evt)
+}
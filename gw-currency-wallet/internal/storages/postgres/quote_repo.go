@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type QuoteRepo struct {
+	db storages.DB
+}
+
+func NewQuoteRepo(db storages.DB) storages.QuoteStorage {
+	return &QuoteRepo{db: db}
+}
+
+// CreateQuote сохраняет котировку со сроком действия ttl. Время истечения
+// считается по часам БД, с которыми оно потом и сравнивается.
+func (r *QuoteRepo) CreateQuote(ctx context.Context, quote *models.ExchangeQuote, ttl time.Duration) error {
+	if quote.ID == uuid.Nil {
+		quote.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(ctx,
+		`INSERT INTO exchange_quotes (id, user_id, wallet_id, from_currency, to_currency,
+			amount, to_amount, rate, fee, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW() + make_interval(secs => $10))
+		RETURNING created_at, expires_at`,
+		quote.ID, quote.UserID, quote.WalletID,
+		string(quote.Amount.Currency), string(quote.Converted.Currency),
+		quote.Amount.Amount, quote.Converted.Amount, quote.Rate.String(), quote.Fee.Amount, ttl.Seconds(),
+	).Scan(&quote.CreatedAt, &quote.ExpiresAt)
+}
+
+// GetQuote возвращает котировку пользователя. Чужие котировки не видны.
+func (r *QuoteRepo) GetQuote(ctx context.Context, id, userID uuid.UUID) (*models.ExchangeQuote, error) {
+	var quote models.ExchangeQuote
+	var from, to, rate string
+
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, wallet_id, from_currency, to_currency, amount, to_amount,
+			rate::TEXT, fee, expires_at, used_at, created_at
+		FROM exchange_quotes
+		WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	).Scan(&quote.ID, &quote.UserID, &quote.WalletID, &from, &to,
+		&quote.Amount.Amount, &quote.Converted.Amount,
+		&rate, &quote.Fee.Amount, &quote.ExpiresAt, &quote.UsedAt, &quote.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Amount.Currency = models.Currency(from)
+	quote.Fee.Currency = quote.Amount.Currency
+	quote.Converted.Currency = models.Currency(to)
+
+	quote.Rate, err = models.ParseDecimal(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+// useQuote помечает котировку использованной в транзакции обмена.
+// Одновременные попытки исполнить одну котировку сериализуются на строке,
+// и успешна только первая из них.
+func useQuote(ctx context.Context, q querier, quoteID, walletID uuid.UUID) error {
+	tag, err := q.Exec(ctx,
+		`UPDATE exchange_quotes SET used_at = NOW()
+		WHERE id = $1 AND wallet_id = $2 AND used_at IS NULL AND expires_at > NOW()`,
+		quoteID, walletID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	var used bool
+	err = q.QueryRow(ctx,
+		`SELECT used_at IS NOT NULL FROM exchange_quotes WHERE id = $1 AND wallet_id = $2`,
+		quoteID, walletID,
+	).Scan(&used)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.ErrQuoteNotFound
+	case err != nil:
+		return err
+	case used:
+		return models.ErrQuoteUsed
+	default:
+		return models.ErrQuoteExpired
+	}
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScheduleRepo struct {
+	db storages.DB
+}
+
+func NewScheduleRepo(db storages.DB) storages.ScheduleStorage {
+	return &ScheduleRepo{db: db}
+}
+
+const scheduleColumns = `id, user_id, kind, wallet_id, to_wallet_id, currency, amount, COALESCE(to_currency, ''),
+	cron, status, failures, next_run_at, last_run_at, created_at, updated_at`
+
+// CreateSchedule сохраняет новое постоянное поручение. Число поручений
+// пользователя проверяется под его блокировкой, чтобы параллельные запросы
+// не превысили maxSchedules.
+func (r *ScheduleRepo) CreateSchedule(ctx context.Context, schedule *models.ScheduledTransfer, maxSchedules int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockUser(ctx, tx, schedule.UserID); err != nil {
+		return err
+	}
+
+	var count int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM scheduled_transfers WHERE user_id = $1`, schedule.UserID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count >= maxSchedules {
+		return models.ErrTooManySchedules
+	}
+
+	var toCurrency *string
+	if schedule.ToCurrency != "" {
+		s := string(schedule.ToCurrency)
+		toCurrency = &s
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO scheduled_transfers (id, user_id, kind, wallet_id, to_wallet_id, currency, amount, to_currency,
+			cron, status, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`,
+		schedule.ID, schedule.UserID, string(schedule.Kind), schedule.WalletID, schedule.ToWalletID,
+		string(schedule.Amount.Currency), schedule.Amount.Amount, toCurrency,
+		schedule.Cron, string(schedule.Status), schedule.NextRunAt,
+	).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *ScheduleRepo) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+scheduleColumns+`
+		FROM scheduled_transfers
+		WHERE user_id = $1
+		ORDER BY created_at, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSchedules(rows)
+}
+
+func (r *ScheduleRepo) GetSchedule(ctx context.Context, userID, id uuid.UUID) (*models.ScheduledTransfer, error) {
+	return r.getSchedule(ctx, userID, id, "")
+}
+
+// LockSchedule возвращает поручение пользователя и блокирует его строку до
+// конца транзакции из ctx, чтобы изменение не разошлось с его исполнением.
+func (r *ScheduleRepo) LockSchedule(ctx context.Context, userID, id uuid.UUID) (*models.ScheduledTransfer, error) {
+	return r.getSchedule(ctx, userID, id, " FOR UPDATE")
+}
+
+func (r *ScheduleRepo) getSchedule(ctx context.Context, userID, id uuid.UUID, lock string) (*models.ScheduledTransfer, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+scheduleColumns+`
+		FROM scheduled_transfers
+		WHERE id = $1 AND user_id = $2`+lock,
+		id, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := scanSchedules(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, models.ErrScheduleNotFound
+	}
+
+	return schedules[0], nil
+}
+
+// UpdateSchedule сохраняет сумму, расписание, статус и время следующего
+// исполнения поручения.
+func (r *ScheduleRepo) UpdateSchedule(ctx context.Context, schedule *models.ScheduledTransfer) error {
+	err := r.db.QueryRow(ctx,
+		`UPDATE scheduled_transfers
+		SET amount = $3, cron = $4, status = $5, failures = $6, next_run_at = $7, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at`,
+		schedule.ID, schedule.UserID, schedule.Amount.Amount, schedule.Cron, string(schedule.Status),
+		schedule.Failures, schedule.NextRunAt,
+	).Scan(&schedule.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrScheduleNotFound
+	}
+
+	return err
+}
+
+// DeleteSchedule удаляет поручение вместе с историей исполнений.
+func (r *ScheduleRepo) DeleteSchedule(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM scheduled_transfers WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// ListScheduleRuns возвращает последние limit исполнений поручения, начиная с новых.
+func (r *ScheduleRepo) ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*models.ScheduleRun, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, schedule_id, scheduled_for, status, COALESCE(error, ''), created_at
+		FROM schedule_runs
+		WHERE schedule_id = $1
+		ORDER BY scheduled_for DESC
+		LIMIT $2`,
+		scheduleID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*models.ScheduleRun, 0)
+	for rows.Next() {
+		var run models.ScheduleRun
+		err := rows.Scan(&run.ID, &run.ScheduleID, &run.ScheduledFor, &run.Status, &run.Error, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+// ListDueSchedules возвращает до limit активных поручений, время исполнения
+// которых наступило к now, начиная с самых просроченных.
+func (r *ScheduleRepo) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+scheduleColumns+`
+		FROM scheduled_transfers
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSchedules(rows)
+}
+
+// ClaimScheduleRun блокирует поручение для исполнения, запланированного на
+// scheduledFor, и возвращает его актуальное состояние. Возвращает nil, если
+// поручение приостановлено, удалено, уже исполнено за это время или его
+// исполняет другой экземпляр сервиса. Вызывается в транзакции из ctx.
+func (r *ScheduleRepo) ClaimScheduleRun(ctx context.Context, id uuid.UUID, scheduledFor time.Time) (*models.ScheduledTransfer, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+scheduleColumns+`
+		FROM scheduled_transfers
+		WHERE id = $1 AND status = 'active' AND next_run_at = $2
+		FOR UPDATE SKIP LOCKED`,
+		id, scheduledFor,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := scanSchedules(rows)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+
+	return schedules[0], nil
+}
+
+// FinishScheduleRun записывает исполнение и переносит поручение на следующее
+// время. Идентификатор исполнения уникален, поэтому повторная запись того же
+// исполнения завершается ошибкой и откатывает транзакцию вместе с операцией.
+func (r *ScheduleRepo) FinishScheduleRun(ctx context.Context, schedule *models.ScheduledTransfer, run *models.ScheduleRun) error {
+	var runError *string
+	if run.Error != "" {
+		runError = &run.Error
+	}
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO schedule_runs (id, schedule_id, scheduled_for, status, error)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`,
+		run.ID, run.ScheduleID, run.ScheduledFor, string(run.Status), runError,
+	).Scan(&run.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx,
+		`UPDATE scheduled_transfers
+		SET status = $2, failures = $3, next_run_at = $4, last_run_at = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		schedule.ID, string(schedule.Status), schedule.Failures, schedule.NextRunAt, schedule.LastRunAt,
+	).Scan(&schedule.UpdatedAt)
+
+	return err
+}
+
+func scanSchedules(rows pgx.Rows) ([]*models.ScheduledTransfer, error) {
+	defer rows.Close()
+
+	schedules := make([]*models.ScheduledTransfer, 0)
+	for rows.Next() {
+		var s models.ScheduledTransfer
+		var toCurrency string
+
+		err := rows.Scan(&s.ID, &s.UserID, &s.Kind, &s.WalletID, &s.ToWalletID,
+			&s.Amount.Currency, &s.Amount.Amount, &toCurrency,
+			&s.Cron, &s.Status, &s.Failures, &s.NextRunAt, &s.LastRunAt, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		s.ToCurrency = models.Currency(toCurrency)
+		schedules = append(schedules, &s)
+	}
+
+	return schedules, rows.Err()
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type TokenRepo struct {
+	db storages.DB
+}
+
+func NewTokenRepo(db storages.DB) storages.TokenStorage {
+	return &TokenRepo{db: db}
+}
+
+func (r *TokenRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+// RotateRefreshToken заменяет токен с хэшем tokenHash на next из того же семейства.
+// Повторное предъявление уже заменённого или отозванного токена отзывает всё
+// семейство вместе с выданными по нему access-токенами и возвращает ErrRefreshTokenReused.
+func (r *TokenRepo) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var current models.RefreshToken
+	var expired bool
+	err = tx.QueryRow(ctx,
+		`SELECT id, user_id, family_id, rotated_at, revoked_at, expires_at <= NOW()
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`,
+		tokenHash,
+	).Scan(&current.ID, &current.UserID, &current.FamilyID, &current.RotatedAt, &current.RevokedAt, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.RotatedAt != nil || current.RevokedAt != nil {
+		if err := revokeFamilies(ctx, tx, `family_id = $1`, current.FamilyID); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		return models.ErrRefreshTokenReused
+	}
+
+	if expired {
+		return models.ErrInvalidRefreshToken
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE refresh_tokens SET rotated_at = NOW() WHERE id = $1`, current.ID)
+	if err != nil {
+		return err
+	}
+
+	next.UserID, next.FamilyID = current.UserID, current.FamilyID
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeSession отзывает access-токен accessJTI и семейство refresh-токенов, по которому он выдан.
+func (r *TokenRepo) RevokeSession(ctx context.Context, userID, accessJTI uuid.UUID, accessExpiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := denyToken(ctx, tx, accessJTI, userID, accessExpiresAt); err != nil {
+		return err
+	}
+
+	err = revokeFamilies(ctx, tx,
+		`family_id IN (SELECT family_id FROM refresh_tokens WHERE access_jti = $1 AND user_id = $2)`,
+		accessJTI, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeAllSessions отзывает все refresh-токены пользователя и access-токены,
+// выданные по ещё действующим из них, а также текущий access-токен.
+func (r *TokenRepo) RevokeAllSessions(ctx context.Context, userID, accessJTI uuid.UUID, accessExpiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := denyToken(ctx, tx, accessJTI, userID, accessExpiresAt); err != nil {
+		return err
+	}
+
+	if err := revokeFamilies(ctx, tx, `user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TokenRepo) IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`,
+		jti,
+	).Scan(&revoked)
+
+	return revoked, err
+}
+
+// PurgeExpiredTokens удаляет не более limit истёкших записей из списка отзыва
+// и refresh-токенов и возвращает их количество. Истёкший токен отклоняется
+// и без записи, поэтому удаление не открывает доступ.
+func (r *TokenRepo) PurgeExpiredTokens(ctx context.Context, limit int) (int, error) {
+	tables := []struct{ name, key string }{
+		{"revoked_tokens", "jti"},
+		{"refresh_tokens", "id"},
+	}
+
+	purged := 0
+	for _, table := range tables {
+		if purged >= limit {
+			break
+		}
+
+		tag, err := r.db.Exec(ctx,
+			`DELETE FROM `+table.name+`
+			WHERE `+table.key+` IN (SELECT `+table.key+` FROM `+table.name+` WHERE expires_at < NOW() LIMIT $1)`,
+			limit-purged,
+		)
+		if err != nil {
+			return purged, err
+		}
+		purged += int(tag.RowsAffected())
+	}
+
+	return purged, nil
+}
+
+func insertRefreshToken(ctx context.Context, q querier, token *models.RefreshToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.FamilyID == uuid.Nil {
+		token.FamilyID = token.ID
+	}
+
+	// Сроки пересчитываются в часы БД, с которыми они потом сравниваются.
+	return q.QueryRow(ctx,
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6), NOW() + make_interval(secs => $7))
+		RETURNING access_expires_at, expires_at, created_at`,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.AccessJTI,
+		time.Until(token.AccessExpiresAt).Seconds(), time.Until(token.ExpiresAt).Seconds(),
+	).Scan(&token.AccessExpiresAt, &token.ExpiresAt, &token.CreatedAt)
+}
+
+// revokeFamilies отзывает refresh-токены, подходящие под условие where,
+// и вносит в список отзыва ещё не истёкшие access-токены, выданные по ним.
+func revokeFamilies(ctx context.Context, q querier, where string, args ...any) error {
+	_, err := q.Exec(ctx,
+		`WITH revoked AS (
+			UPDATE refresh_tokens SET revoked_at = NOW()
+			WHERE revoked_at IS NULL AND `+where+`
+			RETURNING user_id, access_jti, access_expires_at
+		)
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT access_jti, user_id, access_expires_at FROM revoked
+		WHERE access_expires_at > NOW()
+		ON CONFLICT (jti) DO NOTHING`,
+		args...,
+	)
+
+	return err
+}
+
+func denyToken(ctx context.Context, q querier, jti, userID uuid.UUID, expiresAt time.Time) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, time.Until(expiresAt).Seconds(),
+	)
+
+	return err
+}
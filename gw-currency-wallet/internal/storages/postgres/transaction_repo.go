@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/storages"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -18,22 +20,49 @@ func NewTransactionRepo(db *PostgresDB) storages.TransactionStorage {
 
 // CreateTransaction сохраняет транзакцию в БД.
 func (r *TransactionRepo) CreateTransaction(ctx context.Context, tx *models.Transaction) error {
-	_, err := r.db.Pool.Exec(ctx,
-		`INSERT INTO transactions (id, user_id, from_currency, to_currency, amount, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
-		tx.ID, tx.UserID, tx.FromCurrency, tx.ToCurrency, tx.Amount, tx.CreatedAt,
-	)
-	return err
+	return insertTransaction(ctx, r.db, tx)
 }
 
-// ListTransactionsByUser возвращает все транзакции пользователя.
-func (r *TransactionRepo) ListTransactionsByUser(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
-	rows, err := r.db.Pool.Query(ctx,
-		`SELECT id, user_id, from_currency, to_currency, amount, created_at
+// ListTransactionsByUser возвращает транзакции пользователя, начиная с самых новых,
+// с учётом фильтров и курсора. Возвращается не более filter.Limit записей.
+func (r *TransactionRepo) ListTransactionsByUser(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Type != "" {
+		addCondition("type = $%d", string(filter.Type))
+	}
+	if filter.Currency != "" {
+		addCondition("$%[1]d IN (from_currency, to_currency)", string(filter.Currency))
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(
+		`SELECT id, user_id, wallet_id, type, from_currency, amount, to_currency, to_amount,
+			trim_scale(rate)::TEXT, counterparty_user_id, fee, created_at
 		FROM transactions
-		WHERE user_id = $1 ORDER BY created_at DESC`,
-		userID,
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`,
+		strings.Join(conditions, " AND "), len(args),
 	)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +71,26 @@ func (r *TransactionRepo) ListTransactionsByUser(ctx context.Context, userID uui
 	var transactions []*models.Transaction
 	for rows.Next() {
 		var t models.Transaction
-		if err := rows.Scan(&t.ID, &t.UserID, &t.FromCurrency, &t.ToCurrency, &t.Amount, &t.CreatedAt); err != nil {
+		var fromCurrency string
+		var toCurrency *string
+		var toAmount *int64
+		var fee int64
+
+		err := rows.Scan(&t.ID, &t.UserID, &t.WalletID, &t.Type,
+			&fromCurrency, &t.Amount.Amount, &toCurrency, &toAmount,
+			&t.Rate, &t.CounterpartyUserID, &fee, &t.CreatedAt)
+		if err != nil {
 			return nil, err
 		}
+
+		t.Amount.Currency = models.Currency(fromCurrency)
+		if toCurrency != nil && toAmount != nil {
+			t.Converted = &models.Money{Currency: models.Currency(*toCurrency), Amount: *toAmount}
+		}
+		if fee > 0 {
+			t.Fee = &models.Money{Currency: t.Amount.Currency, Amount: fee}
+		}
+
 		transactions = append(transactions, &t)
 	}
 	if err := rows.Err(); err != nil {
@@ -53,3 +99,33 @@ func (r *TransactionRepo) ListTransactionsByUser(ctx context.Context, userID uui
 
 	return transactions, nil
 }
+
+// insertTransaction записывает операцию через q, чтобы её можно было сохранить
+// в той же транзакции, что и изменение баланса.
+func insertTransaction(ctx context.Context, q querier, t *models.Transaction) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	var toCurrency *string
+	var toAmount *int64
+	if t.Converted != nil {
+		currency := string(t.Converted.Currency)
+		toCurrency, toAmount = &currency, &t.Converted.Amount
+	}
+
+	var fee int64
+	if t.Fee != nil {
+		fee = t.Fee.Amount
+	}
+
+	return q.QueryRow(ctx,
+		`INSERT INTO transactions (id, user_id, wallet_id, type, from_currency, amount, to_currency, to_amount,
+			rate, counterparty_user_id, fee, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		RETURNING created_at`,
+		t.ID, t.UserID, t.WalletID, string(t.Type),
+		string(t.Amount.Currency), t.Amount.Amount, toCurrency, toAmount,
+		t.Rate, t.CounterpartyUserID, fee,
+	).Scan(&t.CreatedAt)
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/storages"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type TwoFactorRepo struct {
+	db storages.DB
+}
+
+func NewTwoFactorRepo(db storages.DB) storages.TwoFactorStorage {
+	return &TwoFactorRepo{db: db}
+}
+
+// SaveTOTPSecret сохраняет зашифрованный секрет нового, ещё не подтверждённого
+// приложения. Включённое приложение заменить нельзя: сначала его нужно отключить.
+func (r *TwoFactorRepo) SaveTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret []byte) error {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO user_totp (user_id, secret_encrypted) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = EXCLUDED.secret_encrypted, last_counter = 0,
+			failed_attempts = 0, locked_until = NULL, created_at = NOW()
+		WHERE user_totp.enabled_at IS NULL`,
+		userID, encryptedSecret,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepo) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTP, error) {
+	var t models.TOTP
+	err := r.db.QueryRow(ctx,
+		`SELECT user_id, secret_encrypted, enabled_at, last_counter, failed_attempts,
+			locked_until, created_at, COALESCE(locked_until > NOW(), FALSE)
+		FROM user_totp
+		WHERE user_id = $1`,
+		userID,
+	).Scan(&t.UserID, &t.EncryptedSecret, &t.EnabledAt, &t.LastCounter, &t.FailedAttempts,
+		&t.LockedUntil, &t.CreatedAt, &t.Locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// EnableTOTP включает приложение, подтверждённое кодом шага counter,
+// и заменяет коды восстановления пользователя.
+func (r *TwoFactorRepo) EnableTOTP(ctx context.Context, userID uuid.UUID, counter int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE user_totp SET enabled_at = NOW(), last_counter = $2, failed_attempts = 0
+		WHERE user_id = $1 AND enabled_at IS NULL AND last_counter < $2`,
+		userID, counter,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidTwoFactorCode
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO totp_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3)`,
+			uuid.New(), userID, hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordTOTPSuccess запоминает шаг counter принятого кода и сбрасывает
+// счётчик ошибок. Код уже использованного или более раннего шага
+// отклоняется с ErrInvalidTwoFactorCode, поэтому перехваченный код не повторить.
+func (r *TwoFactorRepo) RecordTOTPSuccess(ctx context.Context, userID uuid.UUID, counter int64) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE user_totp SET last_counter = $2, failed_attempts = 0, locked_until = NULL
+		WHERE user_id = $1 AND last_counter < $2
+			AND (locked_until IS NULL OR locked_until <= NOW())`,
+		userID, counter,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// RecordTOTPFailure учитывает неверный код. После maxAttempts ошибок подряд
+// проверка кодов блокируется на lockout, и счёт начинается заново.
+func (r *TwoFactorRepo) RecordTOTPFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockout time.Duration) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE user_totp SET
+			failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+			locked_until = CASE WHEN failed_attempts + 1 >= $2
+				THEN NOW() + make_interval(secs => $3) ELSE locked_until END
+		WHERE user_id = $1`,
+		userID, maxAttempts, lockout.Seconds(),
+	)
+	return err
+}
+
+// UseRecoveryCode гасит неиспользованный код восстановления с хэшем codeHash.
+func (r *TwoFactorRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx,
+		`WITH used AS (
+			UPDATE totp_recovery_codes SET used_at = NOW()
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			RETURNING user_id
+		)
+		UPDATE user_totp SET failed_attempts = 0
+		WHERE user_id IN (SELECT user_id FROM used)
+			AND (locked_until IS NULL OR locked_until <= NOW())
+		RETURNING user_id`,
+		userID, codeHash,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrInvalidTwoFactorCode
+	}
+
+	return err
+}
+
+func (r *TwoFactorRepo) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TwoFactorRepo) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge, ttl time.Duration) error {
+	if challenge.ID == uuid.Nil {
+		challenge.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(ctx,
+		`INSERT INTO two_factor_challenges (id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING expires_at, created_at`,
+		challenge.ID, challenge.UserID, challenge.TokenHash, ttl.Seconds(),
+	).Scan(&challenge.ExpiresAt, &challenge.CreatedAt)
+}
+
+// GetChallenge возвращает действующий вызов. Неверный код его не гасит:
+// пользователь может ошибиться при вводе.
+func (r *TwoFactorRepo) GetChallenge(ctx context.Context, tokenHash string) (*models.TwoFactorChallenge, error) {
+	var ch models.TwoFactorChallenge
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, token_hash, expires_at, created_at
+		FROM two_factor_challenges
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()`,
+		tokenHash,
+	).Scan(&ch.ID, &ch.UserID, &ch.TokenHash, &ch.ExpiresAt, &ch.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ch, nil
+}
+
+// UseChallenge гасит вызов. Из параллельных запросов с одним вызовом
+// успешен только один.
+func (r *TwoFactorRepo) UseChallenge(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE two_factor_challenges SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidChallenge
+	}
+
+	return nil
+}
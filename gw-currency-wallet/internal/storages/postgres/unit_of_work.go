@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"gw-currency-wallet/internal/storages"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// txKey — ключ контекста, под которым UnitOfWork хранит открытую транзакцию.
+type txKey struct{}
+
+// txFromContext возвращает транзакцию единицы работы, если она открыта.
+func txFromContext(ctx context.Context) pgx.Tx {
+	tx, _ := ctx.Value(txKey{}).(pgx.Tx)
+	return tx
+}
+
+type UnitOfWork struct {
+	db *PostgresDB
+}
+
+func NewUnitOfWork(db *PostgresDB) storages.UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do выполняет fn в транзакции. Репозитории, получившие контекст fn,
+// работают внутри неё, а их собственные транзакции становятся точками
+// сохранения. Транзакция фиксируется, только если fn вернула nil.
+// Вложенный вызов Do открывает точку сохранения во внешней транзакции.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -17,24 +17,34 @@ func NewUserRepo(db *PostgresDB) storages.UserStorage {
 }
 
 func (r *UserRepo) CreateUser(ctx context.Context, user *models.User) error {
-	_, err := r.db.Pool.Exec(ctx,
+	_, err := r.db.Exec(ctx,
 		`INSERT INTO users (id, username, email, password_hash)
 		VALUES ($1, $2, $3, $4)`,
 		user.ID, user.Username, user.Email, user.PasswordHash,
 	)
 
+	// Уникальность имени и почты гарантирует только БД: проверка перед
+	// вставкой не спасает от параллельной регистрации.
+	switch uniqueViolation(err) {
+	case "users_username_key":
+		return models.ErrUsernameTaken
+	case "users_email_key":
+		return models.ErrEmailTaken
+	}
+
 	return err
 }
 
 func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	row := r.db.Pool.QueryRow(ctx,
-		`SELECT id, username, email, password_hash, created_at
+	row := r.db.QueryRow(ctx,
+		`SELECT id, username, email, password_hash, role, status, email_verified_at, created_at
 		FROM users WHERE username = $1`,
 		username,
 	)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+		&user.Status, &user.EmailVerifiedAt, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -42,40 +52,34 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*mod
 	return &user, nil
 }
 
-func (r *UserRepo) ExistUserByUsername(ctx context.Context, username string) (bool, error) {
-	var exists bool
-	err := r.db.Pool.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`,
-		username,
-	).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-	return exists, nil
-}
-
-func (r *UserRepo) ExistUserByEmail(ctx context.Context, email string) (bool, error) {
-	var exists bool
-	err := r.db.Pool.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`,
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := r.db.QueryRow(ctx,
+		`SELECT id, username, email, password_hash, role, status, email_verified_at, created_at
+		FROM users WHERE email = $1`,
 		email,
-	).Scan(&exists)
+	)
+
+	var user models.User
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+		&user.Status, &user.EmailVerifiedAt, &user.CreatedAt)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return exists, nil
+
+	return &user, nil
 }
 
 func (r *UserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	row := r.db.Pool.QueryRow(ctx,
-		`SELECT id, username, email, password_hash, created_at
+	row := r.db.QueryRow(ctx,
+		`SELECT id, username, email, password_hash, role, status, email_verified_at, created_at
 		FROM users
 		WHERE id = $1`,
 		id,
 	)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+		&user.Status, &user.EmailVerifiedAt, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
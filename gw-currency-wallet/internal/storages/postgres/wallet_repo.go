@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/storages"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type WalletRepo struct {
@@ -17,10 +20,18 @@ func NewWalletRepo(db storages.DB) storages.WalletStorage {
 	return &WalletRepo{db: db}
 }
 
+const walletColumns = `id, user_id, name, is_default, status, created_at, updated_at`
+
+// CreateWallet сохраняет кошелёк без проверки числа и имён кошельков
+// пользователя; так создаётся основной кошелёк при регистрации.
 func (r *WalletRepo) CreateWallet(ctx context.Context, wallet *models.Wallet) error {
-	_, err := r.db.Exec(ctx, `INSERT INTO wallets (id, user_id, usd, rub, eur)
-	VALUES($1, $2, $3, $4, $5)`,
-		wallet.ID, wallet.UserID, wallet.USD, wallet.RUB, wallet.EUR)
+	if wallet.Name == "" {
+		wallet.Name = models.DefaultWalletName
+	}
+
+	_, err := r.db.Exec(ctx, `INSERT INTO wallets (id, user_id, name, is_default)
+	VALUES($1, $2, $3, $4)`,
+		wallet.ID, wallet.UserID, wallet.Name, wallet.Default)
 	if err != nil {
 		return err
 	}
@@ -28,71 +39,239 @@ func (r *WalletRepo) CreateWallet(ctx context.Context, wallet *models.Wallet) er
 	return err
 }
 
+// GetWalletByUserID возвращает основной кошелёк пользователя.
 func (r *WalletRepo) GetWalletByUserID(ctx context.Context, userID uuid.UUID) (*models.Wallet, error) {
-	var wallet models.Wallet
-	row := r.db.QueryRow(ctx, `SELECT id, user_id, usd, rub, eur, updated_at
+	row := r.db.QueryRow(ctx, `SELECT `+walletColumns+`
 	FROM wallets
-	WHERE user_id = $1`, userID)
+	WHERE user_id = $1 AND is_default`, userID)
+
+	return scanWalletWithBalances(ctx, r.db, row)
+}
+
+// GetWallet возвращает кошелёк walletID, если он принадлежит пользователю.
+func (r *WalletRepo) GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+walletColumns+`
+	FROM wallets
+	WHERE id = $1 AND user_id = $2`, walletID, userID)
+
+	wallet, err := scanWalletWithBalances(ctx, r.db, row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+
+	return wallet, err
+}
 
-	err := row.Scan(&wallet.ID, &wallet.UserID, &wallet.USD, &wallet.RUB, &wallet.EUR, &wallet.UpdatedAt)
+// ListWallets возвращает кошельки пользователя, включая закрытые:
+// основной первым, остальные в порядке открытия.
+func (r *WalletRepo) ListWallets(ctx context.Context, userID uuid.UUID) ([]*models.Wallet, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+walletColumns+`
+	FROM wallets
+	WHERE user_id = $1
+	ORDER BY is_default DESC, created_at, id`, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &wallet, nil
+	wallets := make([]*models.Wallet, 0)
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, wallet := range wallets {
+		wallet.Balances, wallet.Held, err = loadBalances(ctx, r.db, wallet.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return wallets, nil
 }
 
-func (r *WalletRepo) DepositWallet(ctx context.Context, walletID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error) {
-	if amount <= 0 {
-		return nil, models.ErrInvalidAmount
+// OpenWallet открывает пользователю дополнительный кошелёк, если у него
+// меньше maxWallets незакрытых кошельков и среди них нет кошелька с тем же
+// именем без учёта регистра.
+func (r *WalletRepo) OpenWallet(ctx context.Context, wallet *models.Wallet, maxWallets int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockUser(ctx, tx, wallet.UserID); err != nil {
+		return err
 	}
-	cents := int64(amount * float64(models.CurrencyFactor))
 
+	var open int
+	var taken bool
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(*), COALESCE(BOOL_OR(lower(name) = lower($2)), FALSE)
+		FROM wallets WHERE user_id = $1 AND status <> 'closed'`,
+		wallet.UserID, wallet.Name,
+	).Scan(&open, &taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return models.ErrWalletNameTaken
+	}
+	if open >= maxWallets {
+		return models.ErrTooManyWallets
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO wallets (id, user_id, name, is_default)
+		VALUES ($1, $2, $3, FALSE)
+		RETURNING status, created_at, updated_at`,
+		wallet.ID, wallet.UserID, wallet.Name,
+	).Scan(&wallet.Status, &wallet.CreatedAt, &wallet.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	wallet.Default = false
+	wallet.Balances, wallet.Held, err = loadBalances(ctx, tx, wallet.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RenameWallet переименовывает незакрытый кошелёк пользователя.
+func (r *WalletRepo) RenameWallet(ctx context.Context, userID, walletID uuid.UUID, name string) (*models.Wallet, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx,
-		fmt.Sprintf(`SELECT %s FROM wallets WHERE id = $1 FOR UPDATE`, currency),
-		walletID,
+	// Кошелёк блокируется раньше пользователя, как и в операциях с лимитами.
+	var status models.AccountStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM wallets WHERE id = $1 AND user_id = $2 FOR NO KEY UPDATE`,
+		walletID, userID,
+	).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == models.StatusClosed {
+		return nil, models.ErrWalletClosed
+	}
+
+	if err := lockUser(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	var taken bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM wallets
+		WHERE user_id = $1 AND id <> $2 AND status <> 'closed' AND lower(name) = lower($3))`,
+		userID, walletID, name,
+	).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, models.ErrWalletNameTaken
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE wallets SET name = $1, updated_at = NOW() WHERE id = $2`,
+		name, walletID,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	var balance int64
-	if err := row.Scan(&balance); err != nil {
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
 		return nil, err
 	}
 
-	var wallet models.Wallet
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// CloseWallet закрывает дополнительный кошелёк по просьбе владельца.
+// Закрыть можно только пустой кошелёк, который сейчас допускает списания:
+// владелец не может обойти заморозку или блокировку, наложенную сотрудником.
+func (r *WalletRepo) CloseWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var isDefault bool
 	err = tx.QueryRow(ctx,
-		fmt.Sprintf(`UPDATE wallets SET %s = $1, updated_at = NOW()
-		WHERE id = $2 RETURNING *`, currency),
-		balance+cents, walletID,
-	).Scan(
-		&wallet.ID,
-		&wallet.UserID,
-		&wallet.USD,
-		&wallet.RUB,
-		&wallet.EUR,
-		&wallet.UpdatedAt,
+		`SELECT is_default FROM wallets WHERE id = $1 AND user_id = $2`,
+		walletID, userID,
+	).Scan(&isDefault)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if isDefault {
+		return nil, models.ErrDefaultWallet
+	}
+
+	if err := lockWallet(ctx, tx, walletID, debitAccess); err != nil {
+		return nil, err
+	}
+	if err := checkWalletsEmpty(ctx, tx, walletID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE wallets SET status = $1, updated_at = NOW() WHERE id = $2`,
+		string(models.StatusClosed), walletID,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	err = insertStatusTransition(ctx, tx, &models.StatusTransition{
+		UserID: userID, WalletID: &walletID, From: models.StatusActive, To: models.StatusClosed,
+		ActorID: userID, Reason: "closed by the owner",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
-	return &wallet, nil
+	return wallet, nil
 }
 
-func (r *WalletRepo) WithdrawWallet(ctx context.Context, walletID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error) {
-	if amount <= 0 {
+func (r *WalletRepo) DepositWallet(ctx context.Context, walletID uuid.UUID, amount models.Money, evt *models.EventMessage) (*models.Wallet, error) {
+	if !amount.IsPositive() {
 		return nil, models.ErrInvalidAmount
 	}
-	cents := int64(amount * float64(models.CurrencyFactor))
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -100,41 +279,529 @@ func (r *WalletRepo) WithdrawWallet(ctx context.Context, walletID uuid.UUID, cur
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx,
-		fmt.Sprintf(`SELECT %s FROM wallets
-		WHERE id = $1 FOR UPDATE`, currency),
-		walletID,
-	)
+	if err := lockWallet(ctx, tx, walletID, creditAccess); err != nil {
+		return nil, err
+	}
+
+	if _, err := lockBalance(ctx, tx, walletID, amount.Currency); err != nil {
+		return nil, err
+	}
+
+	operation := &models.Transaction{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     models.TransactionDeposit,
+		Amount:   amount,
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          operation.Type,
+		TransactionID: operation.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(walletID, amount.Currency), Amount: amount.Amount},
+			{Account: models.SystemAccount(models.AccountCashIn, amount.Currency), Amount: -amount.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	operation.UserID = wallet.UserID
+	if err := insertTransaction(ctx, tx, operation); err != nil {
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, evt, wallet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 
-	var balance int64
-	if err := row.Scan(&balance); err != nil {
+	return wallet, nil
+}
+
+func (r *WalletRepo) WithdrawWallet(ctx context.Context, walletID uuid.UUID, amount models.Money, fee models.FeeCharge, evt *models.EventMessage) (*models.Wallet, error) {
+	if !amount.IsPositive() || fee.Amount.Amount < 0 || fee.Amount.Amount > 0 && fee.Amount.Currency != amount.Currency {
+		return nil, models.ErrInvalidAmount
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockWallet(ctx, tx, walletID, debitAccess); err != nil {
 		return nil, err
 	}
 
-	if balance < cents {
+	balance, err := lockBalance(ctx, tx, walletID, amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.available() < amount.Amount+fee.Amount.Amount {
 		return nil, models.ErrInsufficientFunds
 	}
 
-	var wallet models.Wallet
+	if err := checkLimits(ctx, tx, walletID, models.LimitWithdraw, amount); err != nil {
+		return nil, err
+	}
+
+	operation := &models.Transaction{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     models.TransactionWithdraw,
+		Amount:   amount,
+		Fee:      feeAmount(fee),
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          operation.Type,
+		TransactionID: operation.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(walletID, amount.Currency), Amount: -amount.Amount},
+			{Account: models.SystemAccount(models.AccountCashOut, amount.Currency), Amount: amount.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chargeFee(ctx, tx, walletID, fee, operation.Type, operation.ID); err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	operation.UserID = wallet.UserID
+	if err := insertTransaction(ctx, tx, operation); err != nil {
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, evt, wallet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// ExchangeWallet списывает debit и зачисляет credit в другой валюте
+// в рамках одной транзакции, сохраняя использованный курс в истории операций.
+// Комиссия fee списывается в валюте debit сверх неё.
+// Если quoteID не uuid.Nil, котировка помечается использованной в той же транзакции.
+// Если evt не nil, событие записывается в outbox в той же транзакции.
+func (r *WalletRepo) ExchangeWallet(ctx context.Context, walletID uuid.UUID, debit, credit models.Money, fee models.FeeCharge, rate models.Decimal, quoteID uuid.UUID, evt *models.EventMessage) (*models.Wallet, error) {
+	if !debit.IsPositive() || !credit.IsPositive() {
+		return nil, models.ErrInvalidAmount
+	}
+	if fee.Amount.Amount < 0 || fee.Amount.Amount > 0 && fee.Amount.Currency != debit.Currency {
+		return nil, models.ErrInvalidAmount
+	}
+	if debit.Currency == credit.Currency {
+		return nil, models.ErrSameCurrency
+	}
+
+	from, to := debit.Currency, credit.Currency
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if quoteID != uuid.Nil {
+		if err := useQuote(ctx, tx, quoteID, walletID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := lockWallet(ctx, tx, walletID, debitAccess); err != nil {
+		return nil, err
+	}
+
+	balances, err := lockBalances(ctx, tx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if balances[from].available() < debit.Amount+fee.Amount.Amount {
+		return nil, models.ErrInsufficientFunds
+	}
+
+	if err := checkLimits(ctx, tx, walletID, models.LimitExchange, debit); err != nil {
+		return nil, err
+	}
+
+	rateStr := rate.String()
+	operation := &models.Transaction{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Type:      models.TransactionExchange,
+		Amount:    debit,
+		Converted: &credit,
+		Rate:      &rateStr,
+		Fee:       feeAmount(fee),
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          operation.Type,
+		TransactionID: operation.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(walletID, from), Amount: -debit.Amount},
+			{Account: models.SystemAccount(models.AccountFX, from), Amount: debit.Amount},
+			{Account: models.SystemAccount(models.AccountFX, to), Amount: -credit.Amount},
+			{Account: models.WalletAccount(walletID, to), Amount: credit.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chargeFee(ctx, tx, walletID, fee, operation.Type, operation.ID); err != nil {
+		return nil, err
+	}
+
+	wallet, err := loadWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	operation.UserID = wallet.UserID
+	if err := insertTransaction(ctx, tx, operation); err != nil {
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, evt, wallet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// TransferWallet переводит amount из кошелька fromWalletID в кошелёк toWalletID.
+// Кошельки и их балансы блокируются в порядке идентификаторов кошельков, поэтому
+// встречные переводы не приводят к взаимной блокировке. Возвращает кошелёк отправителя.
+func (r *WalletRepo) TransferWallet(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Money, evt *models.EventMessage) (*models.Wallet, error) {
+	if !amount.IsPositive() {
+		return nil, models.ErrInvalidAmount
+	}
+	if fromWalletID == toWalletID {
+		return nil, models.ErrSelfTransfer
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ordered := []uuid.UUID{fromWalletID, toWalletID}
+	slices.SortFunc(ordered, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	for _, walletID := range ordered {
+		access := creditAccess
+		if walletID == fromWalletID {
+			access = debitAccess
+		}
+		if err := lockWallet(ctx, tx, walletID, access); err != nil {
+			return nil, err
+		}
+	}
+
+	balances := make(map[uuid.UUID]lockedBalance, len(ordered))
+	for _, walletID := range ordered {
+		balance, err := lockBalance(ctx, tx, walletID, amount.Currency)
+		if err != nil {
+			return nil, err
+		}
+		balances[walletID] = balance
+	}
+
+	if balances[fromWalletID].available() < amount.Amount {
+		return nil, models.ErrInsufficientFunds
+	}
+
+	// Перевод другому пользователю выводит деньги так же, как вывод, и
+	// считается в его лимит; переводы между своими кошельками лимитов не тратят.
+	var crossUser bool
 	err = tx.QueryRow(ctx,
-		fmt.Sprintf(`UPDATE wallets SET %s = $1, updated_at = NOW()
-		WHERE id = $2 RETURNING *`, currency),
-		balance-cents, walletID,
-	).Scan(
-		&wallet.ID,
-		&wallet.UserID,
-		&wallet.USD,
-		&wallet.RUB,
-		&wallet.EUR,
-		&wallet.UpdatedAt,
-	)
+		`SELECT (SELECT user_id FROM wallets WHERE id = $1) <> (SELECT user_id FROM wallets WHERE id = $2)`,
+		fromWalletID, toWalletID,
+	).Scan(&crossUser)
 	if err != nil {
 		return nil, err
 	}
+	if crossUser {
+		if err := checkLimits(ctx, tx, fromWalletID, models.LimitWithdraw, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	outgoing := &models.Transaction{
+		ID:       uuid.New(),
+		WalletID: fromWalletID,
+		Type:     models.TransactionTransferOut,
+		Amount:   amount,
+	}
+	incoming := &models.Transaction{
+		ID:       uuid.New(),
+		WalletID: toWalletID,
+		Type:     models.TransactionTransferIn,
+		Amount:   amount,
+	}
+
+	err = postJournal(ctx, tx, &models.JournalEntry{
+		Type:          outgoing.Type,
+		TransactionID: outgoing.ID,
+		Lines: []models.JournalLine{
+			{Account: models.WalletAccount(fromWalletID, amount.Currency), Amount: -amount.Amount},
+			{Account: models.WalletAccount(toWalletID, amount.Currency), Amount: amount.Amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := loadWallet(ctx, tx, fromWalletID)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := loadWallet(ctx, tx, toWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing.UserID, outgoing.CounterpartyUserID = sender.UserID, &recipient.UserID
+	if err := insertTransaction(ctx, tx, outgoing); err != nil {
+		return nil, err
+	}
+
+	incoming.UserID, incoming.CounterpartyUserID = recipient.UserID, &sender.UserID
+	if err := insertTransaction(ctx, tx, incoming); err != nil {
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, evt, sender); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
+	return sender, nil
+}
+
+// lockUser сериализует до конца транзакции изменения, которые касаются
+// нескольких кошельков пользователя: проверку лимитов и открытие кошельков.
+// Берётся после блокировки кошелька, если она нужна.
+func lockUser(ctx context.Context, q querier, userID uuid.UUID) error {
+	_, err := q.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, userID.String())
+	return err
+}
+
+// walletAccess — направление движения денег, которое проверяет lockWallet.
+type walletAccess int
+
+const (
+	creditAccess walletAccess = iota
+	debitAccess
+	// releaseAccess снимает резерв: деньги не движутся, статусы не проверяются.
+	releaseAccess
+)
+
+// lockWallet блокирует строку кошелька и проверяет, что статусы кошелька и
+// учётной записи владельца допускают движение денег в направлении access.
+// Кошелёк блокируется раньше строк балансов, поэтому смена статуса дожидается
+// завершения уже начатых операций, а новые видят её сразу. Для несуществующего
+// кошелька возвращается ErrWalletNotFound.
+func lockWallet(ctx context.Context, q querier, walletID uuid.UUID, access walletAccess) error {
+	var status models.AccountStatus
+	var userID uuid.UUID
+	err := q.QueryRow(ctx,
+		`SELECT status, user_id FROM wallets WHERE id = $1 FOR NO KEY UPDATE`,
+		walletID,
+	).Scan(&status, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrWalletNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Статус учётной записи читается отдельным запросом уже после блокировки:
+	// SetAccountStatus блокирует кошельки пользователя, поэтому новый снимок
+	// видит изменение, закоммиченное до получения блокировки.
+	var accountStatus models.AccountStatus
+	err = q.QueryRow(ctx,
+		`SELECT COALESCE((SELECT status FROM users WHERE id = $1), 'active')`,
+		userID,
+	).Scan(&accountStatus)
+	if err != nil {
+		return err
+	}
+
+	status = status.Stricter(accountStatus)
+	switch access {
+	case debitAccess:
+		return status.CheckDebit()
+	case creditAccess:
+		return status.CheckCredit()
+	}
+	return nil
+}
+
+// lockedBalance — заблокированная строка wallet_balances.
+type lockedBalance struct {
+	amount int64
+	held   int64
+}
+
+// available возвращает часть баланса, не зарезервированную холдами.
+func (b lockedBalance) available() int64 {
+	return b.amount - b.held
+}
+
+// lockBalance блокирует строку баланса кошелька в валюте currency,
+// создавая её при первом обращении к валюте из реестра.
+func lockBalance(ctx context.Context, q querier, walletID uuid.UUID, currency models.Currency) (lockedBalance, error) {
+	_, err := q.Exec(ctx,
+		`INSERT INTO wallet_balances (wallet_id, currency)
+		SELECT $1, code FROM currencies WHERE code = $2 AND enabled
+		ON CONFLICT (wallet_id, currency) DO NOTHING`,
+		walletID, string(currency),
+	)
+	if err != nil {
+		return lockedBalance{}, err
+	}
+
+	var balance lockedBalance
+	err = q.QueryRow(ctx,
+		`SELECT b.amount_minor, b.held_minor FROM wallet_balances b
+		JOIN currencies c ON c.code = b.currency
+		WHERE b.wallet_id = $1 AND b.currency = $2 AND c.enabled
+		FOR UPDATE OF b`,
+		walletID, string(currency),
+	).Scan(&balance.amount, &balance.held)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lockedBalance{}, models.ErrUnsupportedCurrency
+	}
+	if err != nil {
+		return lockedBalance{}, err
+	}
+
+	return balance, nil
+}
+
+// lockBalances блокирует несколько валют кошелька в порядке кодов валют,
+// чтобы встречные операции не приводили к взаимной блокировке.
+func lockBalances(ctx context.Context, q querier, walletID uuid.UUID, currencies ...models.Currency) (map[models.Currency]lockedBalance, error) {
+	ordered := append([]models.Currency(nil), currencies...)
+	slices.Sort(ordered)
+
+	balances := make(map[models.Currency]lockedBalance, len(ordered))
+	for _, currency := range ordered {
+		balance, err := lockBalance(ctx, q, walletID, currency)
+		if err != nil {
+			return nil, err
+		}
+		balances[currency] = balance
+	}
+
+	return balances, nil
+}
+
+// setHeld задаёт зарезервированную холдами часть баланса.
+func setHeld(ctx context.Context, q querier, walletID uuid.UUID, currency models.Currency, held int64) error {
+	_, err := q.Exec(ctx,
+		`UPDATE wallet_balances SET held_minor = $1, updated_at = NOW()
+		WHERE wallet_id = $2 AND currency = $3`,
+		held, walletID, string(currency),
+	)
+	return err
+}
+
+func loadWallet(ctx context.Context, q querier, walletID uuid.UUID) (*models.Wallet, error) {
+	row := q.QueryRow(ctx, `SELECT `+walletColumns+` FROM wallets WHERE id = $1`, walletID)
+	return scanWalletWithBalances(ctx, q, row)
+}
+
+func scanWallet(row pgx.Row) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := row.Scan(&wallet.ID, &wallet.UserID, &wallet.Name, &wallet.Default, &wallet.Status,
+		&wallet.CreatedAt, &wallet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	return &wallet, nil
 }
+
+func scanWalletWithBalances(ctx context.Context, q querier, row pgx.Row) (*models.Wallet, error) {
+	wallet, err := scanWallet(row)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.Balances, wallet.Held, err = loadBalances(ctx, q, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// loadBalances возвращает балансы и зарезервированные суммы по всем включённым
+// валютам реестра, включая нулевые, а также ненулевые остатки в отключённых валютах.
+func loadBalances(ctx context.Context, q querier, walletID uuid.UUID) (map[models.Currency]int64, map[models.Currency]int64, error) {
+	rows, err := q.Query(ctx,
+		`SELECT c.code, COALESCE(b.amount_minor, 0), COALESCE(b.held_minor, 0)
+		FROM currencies c
+		LEFT JOIN wallet_balances b ON b.currency = c.code AND b.wallet_id = $1
+		WHERE c.enabled OR b.amount_minor > 0
+		ORDER BY c.code`,
+		walletID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[models.Currency]int64)
+	held := make(map[models.Currency]int64)
+	for rows.Next() {
+		var currency string
+		var amount, reserved int64
+		if err := rows.Scan(&currency, &amount, &reserved); err != nil {
+			return nil, nil, err
+		}
+		balances[models.Currency(currency)] = amount
+		if reserved > 0 {
+			held[models.Currency(currency)] = reserved
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return balances, held, nil
+}
@@ -2,6 +2,7 @@ package storages
 
 import (
 	"context"
+	"time"
 
 	"gw-currency-wallet/internal/models"
 
@@ -18,22 +19,168 @@ type DB interface {
 	Close()
 }
 
+// UnitOfWork выполняет несколько вызовов репозиториев в одной транзакции:
+// репозитории, вызванные с контекстом, переданным в fn, работают внутри неё.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserStorage interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
-	ExistUserByUsername(ctx context.Context, username string) (bool, error)
-	ExistUserByEmail(ctx context.Context, email string) (bool, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 }
 
 type WalletStorage interface {
 	CreateWallet(ctx context.Context, wallet *models.Wallet) error
 	GetWalletByUserID(ctx context.Context, userID uuid.UUID) (*models.Wallet, error)
-	DepositWallet(ctx context.Context, walletID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error)
-	WithdrawWallet(ctx context.Context, walletID uuid.UUID, currency models.Currency, amount float64) (*models.Wallet, error)
+	GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error)
+	ListWallets(ctx context.Context, userID uuid.UUID) ([]*models.Wallet, error)
+	OpenWallet(ctx context.Context, wallet *models.Wallet, maxWallets int) error
+	RenameWallet(ctx context.Context, userID, walletID uuid.UUID, name string) (*models.Wallet, error)
+	CloseWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.Wallet, error)
+	DepositWallet(ctx context.Context, walletID uuid.UUID, amount models.Money, evt *models.EventMessage) (*models.Wallet, error)
+	WithdrawWallet(ctx context.Context, walletID uuid.UUID, amount models.Money, fee models.FeeCharge, evt *models.EventMessage) (*models.Wallet, error)
+	ExchangeWallet(ctx context.Context, walletID uuid.UUID, debit, credit models.Money, fee models.FeeCharge, rate models.Decimal, quoteID uuid.UUID, evt *models.EventMessage) (*models.Wallet, error)
+	TransferWallet(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Money, evt *models.EventMessage) (*models.Wallet, error)
+
+	CreateHold(ctx context.Context, hold *models.Hold, ttl time.Duration) error
+	GetHold(ctx context.Context, holdID, userID uuid.UUID) (*models.Hold, error)
+	CaptureHold(ctx context.Context, holdID, userID uuid.UUID, amount models.Money, fee models.FeeCharge, evt *models.EventMessage) (*models.Hold, error)
+	VoidHold(ctx context.Context, holdID, userID uuid.UUID) (*models.Hold, error)
+	VoidOrderHold(ctx context.Context, holdID, userID, orderID uuid.UUID) (*models.Hold, error)
+	ExpireHolds(ctx context.Context, limit int) (int, error)
+}
+
+type CurrencyStorage interface {
+	ListCurrencies(ctx context.Context) ([]*models.CurrencyInfo, error)
+	EnsureCurrencies(ctx context.Context, codes []models.Currency) error
+}
+
+type TokenDenylist interface {
+	IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+}
+
+type TokenStorage interface {
+	TokenDenylist
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken) error
+	RevokeSession(ctx context.Context, userID, accessJTI uuid.UUID, accessExpiresAt time.Time) error
+	RevokeAllSessions(ctx context.Context, userID, accessJTI uuid.UUID, accessExpiresAt time.Time) error
+	PurgeExpiredTokens(ctx context.Context, limit int) (int, error)
+}
+
+type EmailTokenStorage interface {
+	CreateEmailToken(ctx context.Context, token *models.EmailToken, ttl time.Duration) error
+	VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error)
+}
+
+type TwoFactorStorage interface {
+	SaveTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret []byte) error
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTP, error)
+	EnableTOTP(ctx context.Context, userID uuid.UUID, counter int64, recoveryCodeHashes []string) error
+	RecordTOTPSuccess(ctx context.Context, userID uuid.UUID, counter int64) error
+	RecordTOTPFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockout time.Duration) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	DisableTOTP(ctx context.Context, userID uuid.UUID) error
+
+	CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge, ttl time.Duration) error
+	GetChallenge(ctx context.Context, tokenHash string) (*models.TwoFactorChallenge, error)
+	UseChallenge(ctx context.Context, id uuid.UUID) error
+}
+
+type APIKeyStorage interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey, ttl time.Duration) error
+	GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+type LoginStorage interface {
+	GetLoginLockout(ctx context.Context, subjects ...models.LoginSubject) (*models.LoginLockout, error)
+	RecordLoginFailure(ctx context.Context, subject models.LoginSubject, rule models.LockoutRule, userID uuid.UUID) (*models.LoginLockout, error)
+	ResetLoginFailures(ctx context.Context, subject models.LoginSubject) error
+}
+
+type AdminStorage interface {
+	GetWallet(ctx context.Context, walletID uuid.UUID) (*models.Wallet, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.AccountStatus, entry *models.AuditEntry) (*models.Wallet, error)
+	SetAccountStatus(ctx context.Context, userID uuid.UUID, status models.AccountStatus, entry *models.AuditEntry) error
+	ListStatusHistory(ctx context.Context, userID uuid.UUID) ([]*models.StatusTransition, error)
+	AdjustWallet(ctx context.Context, walletID uuid.UUID, delta models.Money, entry *models.AuditEntry) (*models.Wallet, error)
+	SetUserRole(ctx context.Context, userID uuid.UUID, role models.Role, entry *models.AuditEntry) error
+	UnlockLogin(ctx context.Context, userID uuid.UUID, username string, entry *models.AuditEntry) (bool, error)
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error)
+	SetSpendingLimit(ctx context.Context, limit *models.SpendingLimit, entry *models.AuditEntry) error
+	RemoveSpendingLimit(ctx context.Context, userID *uuid.UUID, operation models.LimitOperation, currency models.Currency, period models.LimitPeriod, entry *models.AuditEntry) error
+	SetFeeRule(ctx context.Context, rule *models.FeeRule, entry *models.AuditEntry) error
+	DeleteFeeRule(ctx context.Context, id uuid.UUID, entry *models.AuditEntry) error
+}
+
+type FeeStorage interface {
+	ListFeeRules(ctx context.Context) ([]*models.FeeRule, error)
+	FindFeeRules(ctx context.Context, operation models.FeeOperation, from models.Currency) ([]*models.FeeRule, error)
+	MonthlyVolume(ctx context.Context, userID uuid.UUID, operation models.FeeOperation, currency models.Currency) (int64, error)
+	SettleFees(ctx context.Context, houseWalletID uuid.UUID) (int, error)
+}
+
+type ScheduleStorage interface {
+	CreateSchedule(ctx context.Context, schedule *models.ScheduledTransfer, maxSchedules int) error
+	ListSchedules(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error)
+	GetSchedule(ctx context.Context, userID, id uuid.UUID) (*models.ScheduledTransfer, error)
+	LockSchedule(ctx context.Context, userID, id uuid.UUID) (*models.ScheduledTransfer, error)
+	UpdateSchedule(ctx context.Context, schedule *models.ScheduledTransfer) error
+	DeleteSchedule(ctx context.Context, userID, id uuid.UUID) error
+	ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*models.ScheduleRun, error)
+	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error)
+	ClaimScheduleRun(ctx context.Context, id uuid.UUID, scheduledFor time.Time) (*models.ScheduledTransfer, error)
+	FinishScheduleRun(ctx context.Context, schedule *models.ScheduledTransfer, run *models.ScheduleRun) error
+}
+
+type OrderStorage interface {
+	CreateOrder(ctx context.Context, order *models.LimitOrder, evt *models.EventMessage) error
+	ListOrders(ctx context.Context, userID uuid.UUID, status models.OrderStatus) ([]*models.LimitOrder, error)
+	GetOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error)
+	LockOrder(ctx context.Context, userID, id uuid.UUID) (*models.LimitOrder, error)
+	ListTriggeredOrders(ctx context.Context, from, to models.Currency, rate models.Decimal, limit int) ([]*models.LimitOrder, error)
+	ClaimOrder(ctx context.Context, id uuid.UUID, rate models.Decimal) (*models.LimitOrder, error)
+	FinishOrder(ctx context.Context, order *models.LimitOrder, evt *models.EventMessage) error
+	ExpireOrders(ctx context.Context, limit int) (int, error)
+}
+
+type LimitStorage interface {
+	ListLimitUsage(ctx context.Context, userID uuid.UUID) ([]*models.LimitUsage, error)
+	ListSpendingLimits(ctx context.Context, userID *uuid.UUID) ([]*models.SpendingLimit, error)
+}
+
+type LedgerStorage interface {
+	FindBalanceDrift(ctx context.Context) ([]models.BalanceDrift, error)
+	FindUnbalancedEntries(ctx context.Context) ([]models.UnbalancedEntry, error)
+}
+
+type QuoteStorage interface {
+	CreateQuote(ctx context.Context, quote *models.ExchangeQuote, ttl time.Duration) error
+	GetQuote(ctx context.Context, id, userID uuid.UUID) (*models.ExchangeQuote, error)
 }
 
 type TransactionStorage interface {
 	CreateTransaction(ctx context.Context, tx *models.Transaction) error
-	ListTransactionsByUser(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error)
+	ListTransactionsByUser(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error)
+}
+
+type IdempotencyStorage interface {
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTimeout time.Duration) (*models.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration, limit int) (int, error)
+}
+
+type OutboxStorage interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error)
+	MarkOutboxSent(ctx context.Context, id uuid.UUID) error
+	MarkOutboxFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error
+	PostponeOutbox(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error
 }
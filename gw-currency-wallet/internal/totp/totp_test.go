@@ -0,0 +1,70 @@
+package totp_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/totp"
+)
+
+// Test vectors from RFC 6238, appendix B (SHA-1), truncated to 6 digits.
+func TestCodeRFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		counter := totp.Counter(time.Unix(tt.unix, 0))
+		if got := totp.Code(secret, counter); got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	counter := totp.Counter(now)
+
+	if got, ok := totp.Validate(secret, totp.Code(secret, counter), now, 1); !ok || got != counter {
+		t.Errorf("current code: got (%d, %v), want (%d, true)", got, ok, counter)
+	}
+	if got, ok := totp.Validate(secret, totp.Code(secret, counter-1), now, 1); !ok || got != counter-1 {
+		t.Errorf("previous code within skew: got (%d, %v), want (%d, true)", got, ok, counter-1)
+	}
+	if _, ok := totp.Validate(secret, totp.Code(secret, counter-2), now, 1); ok {
+		t.Error("code outside skew accepted")
+	}
+	if _, ok := totp.Validate(secret, "12345", now, 1); ok {
+		t.Error("short code accepted")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("Wallet", "alice", []byte("12345678901234567890"))
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("invalid URI %q: %v", uri, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Wallet:alice" {
+		t.Errorf("unexpected URI %q", uri)
+	}
+	if got := u.Query().Get("secret"); got != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+		t.Errorf("secret = %s", got)
+	}
+	if got := u.Query().Get("issuer"); got != "Wallet" {
+		t.Errorf("issuer = %s", got)
+	}
+}
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/transport/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type memoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]*models.APIKey
+}
+
+func (s *memoryAPIKeyStore) CreateAPIKey(ctx context.Context, key *models.APIKey, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.KeyHash] = key
+	return nil
+}
+
+func (s *memoryAPIKeyStore) GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return nil, models.ErrInvalidAPIKey
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *memoryAPIKeyStore) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	return nil, nil
+}
+
+func (s *memoryAPIKeyStore) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	return nil
+}
+
+func (s *memoryAPIKeyStore) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestRequireScope(t *testing.T) {
+	logger.Init()
+	gin.SetMode(gin.TestMode)
+
+	readOnly := &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeBalanceRead}}
+
+	tests := []struct {
+		name  string
+		key   *models.APIKey
+		scope models.APIKeyScope
+		want  int
+	}{
+		{"access token", nil, models.ScopeWalletWithdraw, http.StatusOK},
+		{"granted scope", readOnly, models.ScopeBalanceRead, http.StatusOK},
+		{"missing scope", readOnly, models.ScopeWalletWithdraw, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := gin.New()
+		r.GET("/wallet",
+			func(c *gin.Context) {
+				if tt.key != nil {
+					c.Set("api_key", tt.key)
+				}
+			},
+			middleware.RequireScope(tt.scope),
+			func(c *gin.Context) { c.Status(http.StatusOK) },
+		)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallet", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyOrJWT_AllowedIPs(t *testing.T) {
+	logger.Init()
+	gin.SetMode(gin.TestMode)
+
+	apiKeys := services.NewAPIKeyService(&memoryAPIKeyStore{keys: make(map[string]*models.APIKey)})
+	created, err := apiKeys.Create(context.Background(), uuid.New(), models.CreateAPIKeyRequest{
+		Name:       "export",
+		Scopes:     []models.APIKeyScope{models.ScopeBalanceRead},
+		AllowedIPs: []string{"203.0.113.7"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+
+	jwt := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           int
+	}{
+		{"allowed peer", nil, "203.0.113.7:40000", "", http.StatusOK},
+		{"disallowed peer", nil, "198.51.100.1:40000", "", http.StatusForbidden},
+		{"spoofed header", nil, "198.51.100.1:40000", "203.0.113.7", http.StatusForbidden},
+		{"header from trusted proxy", []string{"198.51.100.1"}, "198.51.100.1:40000", "203.0.113.7", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		r := gin.New()
+		if err := r.SetTrustedProxies(tt.trustedProxies); err != nil {
+			t.Fatalf("%s: failed to set trusted proxies: %v", tt.name, err)
+		}
+		r.GET("/balance",
+			middleware.APIKeyOrJWT(apiKeys, jwt),
+			func(c *gin.Context) { c.Status(http.StatusOK) },
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+		req.RemoteAddr = tt.remoteAddr
+		req.Header.Set(models.APIKeyHeader, created.Key)
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
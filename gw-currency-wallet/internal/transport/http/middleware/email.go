@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/storages"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireVerifiedEmail allows the request only if the authenticated user has
+// confirmed their email. The user is read on every request, so a confirmation
+// takes effect without a new token. It must run after JWT.
+func RequireVerifiedEmail(users storages.UserStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+				Success: false,
+				Error:   messages.MsgUnauthorized,
+			})
+			return
+		}
+
+		user, err := users.GetUserByID(c.Request.Context(), userID)
+		if err != nil {
+			logger.L.Errorw("failed to load user", "userID", userID, "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+			return
+		}
+
+		if !user.EmailVerified() {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+				Success: false,
+				Error:   messages.MsgEmailNotVerified,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
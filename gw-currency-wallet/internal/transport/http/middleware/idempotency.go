@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/storages"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// idempotencyReleaseKey marks a request refused before it changed anything.
+const idempotencyReleaseKey = "idempotency_release"
+
+// releaseIdempotencyKey tells Idempotency to free the key of a request refused
+// before it changed anything, so the client can repeat it under the same key.
+func releaseIdempotencyKey(c *gin.Context) {
+	c.Set(idempotencyReleaseKey, true)
+}
+
+// Idempotency replays the first stored response for requests repeated with the
+// same Idempotency-Key header. It must run after JWT so that keys are scoped per user.
+// Requests without the header are passed through unchanged. A key whose request
+// did not finish within lockTimeout is never run again: its outcome is unknown.
+// Middleware after it, such as StepUp, frees the key of a request it refuses.
+//
+// Any other response is stored, server errors included: a 5xx may come after
+// the operation has committed, and running it again could apply it twice. A
+// repeat of such a request gets the stored 5xx; to retry, the client sends a
+// new key once it has checked that the operation did not happen.
+func Idempotency(store storages.IdempotencyStorage, lockTimeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(models.IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidIdempotencyKey,
+			})
+			return
+		}
+
+		userIDStr, _ := c.Get("user_id")
+		userID, err := uuid.Parse(toString(userIDStr))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+				Success: false,
+				Error:   messages.MsgUnauthorized,
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Success: false,
+				Error:   messages.MsgInvalidRequest,
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(c.Request.Method, c.FullPath(), body)
+
+		record, reserved, err := store.ReserveIdempotencyKey(c, userID, key, hash, lockTimeout)
+		if err != nil {
+			logger.L.Errorw("Failed to reserve idempotency key", "userID", userID, "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.RequestHash != hash:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.Response{
+					Success: false,
+					Error:   messages.MsgIdempotencyKeyReused,
+				})
+			case record.Abandoned:
+				logger.L.Warnw("Idempotency key abandoned by an unfinished request", "userID", userID, "key", key)
+				c.AbortWithStatusJSON(http.StatusConflict, models.Response{
+					Success: false,
+					Error:   messages.MsgIdempotencyAbandoned,
+				})
+			case !record.Completed():
+				c.AbortWithStatusJSON(http.StatusConflict, models.Response{
+					Success: false,
+					Error:   messages.MsgIdempotencyInProgress,
+				})
+			default:
+				logger.L.Infow("Replaying idempotent response", "userID", userID, "key", key)
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Результат сохраняется даже если клиент уже отключился.
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := recorder.Status()
+
+		if c.GetBool(idempotencyReleaseKey) {
+			if err := store.ReleaseIdempotencyKey(ctx, userID, key); err != nil {
+				logger.L.Errorw("Failed to release idempotency key", "userID", userID, "error", err.Error())
+			}
+			return
+		}
+
+		if err := store.CompleteIdempotencyKey(ctx, userID, key, status, recorder.body.Bytes()); err != nil {
+			logger.L.Errorw("Failed to save idempotent response", "userID", userID, "error", err.Error())
+		}
+	}
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash fingerprints the request. JSON bodies are canonicalized so that
+// retries differing only in formatting or key order are treated as equal.
+func requestHash(method, path string, body []byte) string {
+	if canonical, err := canonicalJSON(body); err == nil {
+		body = canonical
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func canonicalJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+func toString(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,165 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/transport/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyRecord
+}
+
+func (s *memoryIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, requestHash string, lockTimeout time.Duration) (*models.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := userID.String() + key
+	if record, ok := s.records[id]; ok {
+		copied := *record
+		copied.Abandoned = !record.Completed() && time.Since(record.CreatedAt) > lockTimeout
+		return &copied, false, nil
+	}
+
+	s.records[id] = &models.IdempotencyRecord{UserID: userID, Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, statusCode int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	record := s.records[userID.String()+key]
+	record.StatusCode, record.ResponseBody, record.CompletedAt = statusCode, body, &now
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, userID.String()+key)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration, limit int) (int, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	logger.Init()
+	gin.SetMode(gin.TestMode)
+
+	store := &memoryIdempotencyStore{records: make(map[string]*models.IdempotencyRecord)}
+	userID := uuid.New().String()
+
+	var calls, failures atomic.Int64
+	release := make(chan struct{})
+
+	r := gin.New()
+	r.POST("/deposit",
+		func(c *gin.Context) { c.Set("user_id", userID) },
+		middleware.Idempotency(store, time.Minute),
+		func(c *gin.Context) {
+			<-release
+			n := calls.Add(1)
+			c.JSON(http.StatusOK, gin.H{"call": n})
+		},
+	)
+	r.POST("/withdraw",
+		func(c *gin.Context) { c.Set("user_id", userID) },
+		middleware.Idempotency(store, time.Minute),
+		func(c *gin.Context) {
+			n := failures.Add(1)
+			c.JSON(http.StatusInternalServerError, gin.H{"failure": n})
+		},
+	)
+
+	sendTo := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(models.IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	send := func(key, body string) *httptest.ResponseRecorder { return sendTo("/deposit", key, body) }
+
+	// Конкурентные запросы с одним ключом: выполняется только один.
+	const concurrent = 10
+	codes := make(chan int, concurrent)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- send("key-1", `{"currency":"USD","amount":"10"}`).Code
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	for code := range codes {
+		if code != http.StatusOK && code != http.StatusConflict {
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+
+	w := send("key-1", `{"amount": "10", "currency": "USD"}`)
+	if w.Code != http.StatusOK || w.Body.String() != `{"call":1}` || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay = %d %s, want stored response", w.Code, w.Body.String())
+	}
+
+	if w := send("key-1", `{"currency":"USD","amount":"11"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different body with same key = %d, want 422", w.Code)
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+
+	// Запрос, оборвавшийся до сохранения ответа, не выполняется повторно.
+	uid := uuid.MustParse(userID)
+	store.records[userID+"key-2"] = &models.IdempotencyRecord{
+		UserID: uid, Key: "key-2", RequestHash: store.records[userID+"key-1"].RequestHash,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	if w := send("key-2", `{"currency":"USD","amount":"10"}`); w.Code != http.StatusConflict {
+		t.Errorf("abandoned key = %d, want 409", w.Code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times after abandoned key, want 1", calls.Load())
+	}
+
+	// Ошибка сервера могла случиться уже после записи, поэтому ключ не
+	// освобождается и повтор получает сохранённый ответ.
+	if w := sendTo("/withdraw", "key-3", `{"currency":"USD","amount":"10"}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("failing request = %d, want 500", w.Code)
+	}
+	w = sendTo("/withdraw", "key-3", `{"currency":"USD","amount":"10"}`)
+	if w.Code != http.StatusInternalServerError || w.Body.String() != `{"failure":1}` || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("repeated failed request = %d %s, want stored 500", w.Code, w.Body.String())
+	}
+	if failures.Load() != 1 {
+		t.Errorf("failing handler called %d times, want 1", failures.Load())
+	}
+}
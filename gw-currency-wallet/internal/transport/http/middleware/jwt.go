@@ -4,13 +4,16 @@ import (
 	"gw-currency-wallet/internal/models"
 	"gw-currency-wallet/internal/pkg/logger"
 	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/storages"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-func JWT(secret string) gin.HandlerFunc {
+// JWT authenticates requests by the bearer access token. Tokens whose jti
+// is on the denylist are rejected even if they have not expired yet.
+func JWT(verifier *services.JWTVerifier, denylist storages.TokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr := c.GetHeader("Authorization")
 		if len(tokenStr) < 8 || tokenStr[:7] != "Bearer " {
@@ -24,10 +27,8 @@ func JWT(secret string) gin.HandlerFunc {
 
 		tokenStr = tokenStr[7:]
 
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
+		claims, err := verifier.Verify(tokenStr)
+		if err != nil {
 			logger.L.Warnf("invalid token: %v, ip=%s", err, c.ClientIP())
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
 				Success: false,
@@ -37,19 +38,26 @@ func JWT(secret string) gin.HandlerFunc {
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			logger.L.Error("failed to parse JWT claims")
+		revoked, err := denylist.IsTokenRevoked(c.Request.Context(), claims.JTI)
+		if err != nil {
+			logger.L.Errorw("failed to check token revocation", "jti", claims.JTI, "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+			return
+		}
+		if revoked {
+			logger.L.Warnf("revoked token used: jti=%s, ip=%s", claims.JTI, c.ClientIP())
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
 				Success: false,
-				Error:   messages.MsgInvalidToken,
+				Error:   messages.MsgTokenRevoked,
 			})
 			return
 		}
 
-		if v, ok := claims["user_id"].(string); ok {
-			c.Set("user_id", v)
-		}
+		c.Set("user_id", claims.UserID)
+		c.Set("access_claims", claims)
 
 		c.Next()
 	}
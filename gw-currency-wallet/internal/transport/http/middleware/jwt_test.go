@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gw-currency-wallet/internal/jwks"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/services"
+	"gw-currency-wallet/internal/transport/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+type memoryDenylist struct {
+	mu      sync.Mutex
+	revoked map[uuid.UUID]bool
+}
+
+func (d *memoryDenylist) IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.revoked[jti], nil
+}
+
+func TestJWT(t *testing.T) {
+	logger.Init()
+	gin.SetMode(gin.TestMode)
+
+	keys := newKeySet(t, "RS256")
+	verifier := services.NewJWTVerifier(keys)
+	denylist := &memoryDenylist{revoked: make(map[uuid.UUID]bool)}
+	userID := uuid.New().String()
+
+	r := gin.New()
+	r.GET("/me", middleware.JWT(verifier, denylist), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	token, claims, err := services.NewJWTManager(keys, verifier, time.Minute).Generate(userID, models.RoleUser)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	if w := send(token); w.Code != http.StatusOK || w.Body.String() != userID {
+		t.Fatalf("valid token: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := send(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: expected 401, got %d", w.Code)
+	}
+
+	otherKeys := newKeySet(t, "EdDSA")
+	other, _, _ := services.NewJWTManager(otherKeys, services.NewJWTVerifier(otherKeys), time.Minute).Generate(userID, models.RoleUser)
+	if w := send(other); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key: expected 401, got %d", w.Code)
+	}
+
+	// Открытый ключ известен всем, поэтому HMAC-подпись им не должна приниматься.
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"jti":     uuid.NewString(),
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	})
+	hmac.Header["kid"] = keys.SigningKey().ID
+	forged, _ := hmac.SignedString([]byte(keys.SigningKey().JWK().N))
+	if w := send(forged); w.Code != http.StatusUnauthorized {
+		t.Errorf("HMAC token: expected 401, got %d", w.Code)
+	}
+
+	expired, _, _ := services.NewJWTManager(keys, verifier, -time.Minute).Generate(userID, models.RoleUser)
+	if w := send(expired); w.Code != http.StatusUnauthorized {
+		t.Errorf("expired token: expected 401, got %d", w.Code)
+	}
+
+	denylist.revoked[claims.JTI] = true
+	if w := send(token); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: expected 401, got %d", w.Code)
+	}
+}
+
+func newKeySet(t *testing.T, algorithm string) *jwks.KeySet {
+	t.Helper()
+
+	keys, err := jwks.NewKeySet(t.TempDir(), algorithm, time.Hour, 2*time.Hour, time.Minute)
+	if err != nil {
+		t.Fatalf("create key set: %v", err)
+	}
+
+	return keys
+}
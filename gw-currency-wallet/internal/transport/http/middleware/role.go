@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the authenticated user has at least
+// the min role. It must run after JWT.
+func RequireRole(min models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get("access_claims")
+		claims, _ := value.(*models.AccessClaims)
+		if !ok || claims == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+				Success: false,
+				Error:   messages.MsgUnauthorized,
+			})
+			return
+		}
+
+		if !claims.Role.AtLeast(min) {
+			logger.L.Warnw("forbidden request", "userID", claims.UserID, "role", claims.Role,
+				"required", min, "path", c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+				Success: false,
+				Error:   messages.MsgForbidden,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/transport/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRole(t *testing.T) {
+	logger.Init()
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		role models.Role
+		min  models.Role
+		want int
+	}{
+		{models.RoleUser, models.RoleUser, http.StatusOK},
+		{models.RoleUser, models.RoleSupport, http.StatusForbidden},
+		{models.RoleSupport, models.RoleSupport, http.StatusOK},
+		{models.RoleSupport, models.RoleAdmin, http.StatusForbidden},
+		{models.RoleAdmin, models.RoleSupport, http.StatusOK},
+		{models.Role("root"), models.RoleUser, http.StatusForbidden},
+		{"", models.RoleUser, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		r := gin.New()
+		r.GET("/admin",
+			func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("access_claims", &models.AccessClaims{Role: tt.role})
+				}
+			},
+			middleware.RequireRole(tt.min),
+			func(c *gin.Context) { c.Status(http.StatusOK) },
+		)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		if w.Code != tt.want {
+			t.Errorf("role %q, required %q: got %d, want %d", tt.role, tt.min, w.Code, tt.want)
+		}
+	}
+}
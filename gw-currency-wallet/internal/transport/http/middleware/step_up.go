@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"gw-currency-wallet/internal/models"
+	"gw-currency-wallet/internal/pkg/logger"
+	"gw-currency-wallet/internal/pkg/messages"
+	"gw-currency-wallet/internal/services"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StepUp asks for a TOTP code in the X-2FA-Code header when the request body
+// moves an amount at or above the step-up threshold of its currency. It runs
+// after JWT and Idempotency: a repeated request gets its stored response without
+// a new code, and a refusal frees the idempotency key instead of being stored.
+// Bodies without a valid amount are left to the handler.
+func StepUp(twoFactor *services.TwoFactorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, body, ok := stepUpRequest(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Currency string          `json:"currency"`
+			Amount   *models.Decimal `json:"amount"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Amount == nil {
+			c.Next()
+			return
+		}
+
+		amount, err := models.NewMoney(*req.Amount, models.Currency(strings.ToUpper(req.Currency)))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		requireStepUp(c, twoFactor, userID, amount)
+	}
+}
+
+// HoldCaptureStepUp is StepUp for hold capture, where the currency comes from
+// the hold and an omitted amount captures the whole remaining reserve.
+// Unknown holds and holds of limit orders are left to the handler.
+func HoldCaptureStepUp(twoFactor *services.TwoFactorService, holds *services.HoldService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, body, ok := stepUpRequest(c)
+		if !ok {
+			return
+		}
+
+		holdID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		var req models.CaptureHoldRequest
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				c.Next()
+				return
+			}
+		}
+
+		amount, err := holds.CaptureAmount(c.Request.Context(), userID, holdID, req.Amount)
+		switch {
+		case errors.Is(err, models.ErrHoldNotFound), errors.Is(err, models.ErrHoldOwnedByOrder),
+			errors.Is(err, models.ErrInvalidAmount), errors.Is(err, models.ErrTooPrecise),
+			errors.Is(err, models.ErrUnsupportedCurrency):
+			c.Next()
+			return
+		case err != nil:
+			logger.L.Errorw("Step-up hold lookup failed", "userID", userID, "holdID", holdID, "error", err.Error())
+			releaseIdempotencyKey(c)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Error:   messages.MsgInternalError,
+			})
+			return
+		}
+
+		requireStepUp(c, twoFactor, userID, amount)
+	}
+}
+
+// RequireTwoFactor asks for a TOTP code in the X-2FA-Code header on every
+// request, for actions that hand out lasting access such as creating an API
+// key. Users without an authenticator app are refused until they set one up.
+func RequireTwoFactor(twoFactor *services.TwoFactorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+				Success: false,
+				Error:   messages.MsgUnauthorized,
+			})
+			return
+		}
+
+		err = twoFactor.Verify(c.Request.Context(), userID, c.GetHeader(models.TwoFactorCodeHeader), false)
+		respondStepUp(c, userID, err)
+	}
+}
+
+// stepUpRequest reads the caller and the request body, restoring the body
+// for the handlers that follow.
+func stepUpRequest(c *gin.Context) (uuid.UUID, []byte, bool) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		releaseIdempotencyKey(c)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+			Success: false,
+			Error:   messages.MsgUnauthorized,
+		})
+		return uuid.Nil, nil, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		releaseIdempotencyKey(c)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Error:   messages.MsgInvalidRequest,
+		})
+		return uuid.Nil, nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return userID, body, true
+}
+
+func requireStepUp(c *gin.Context, twoFactor *services.TwoFactorService, userID uuid.UUID, amount models.Money) {
+	err := twoFactor.RequireStepUp(c.Request.Context(), userID, amount, c.GetHeader(models.TwoFactorCodeHeader))
+	respondStepUp(c, userID, err)
+}
+
+// respondStepUp passes the request on if the code was accepted and refuses it otherwise.
+func respondStepUp(c *gin.Context, userID uuid.UUID, err error) {
+	if err == nil {
+		c.Next()
+		return
+	}
+
+	var statusCode int
+	var errorMsg string
+
+	switch {
+	case errors.Is(err, models.ErrTwoFactorRequired):
+		statusCode = http.StatusForbidden
+		errorMsg = messages.MsgTwoFactorRequired
+	case errors.Is(err, models.ErrInvalidTwoFactorCode):
+		logger.L.Warnw("Invalid step-up code", "userID", userID, "ip", c.ClientIP())
+		statusCode = http.StatusForbidden
+		errorMsg = messages.MsgInvalidTwoFactorCode
+	case errors.Is(err, models.ErrTwoFactorLocked):
+		statusCode = http.StatusTooManyRequests
+		errorMsg = messages.MsgTwoFactorLocked
+	case errors.Is(err, models.ErrTwoFactorNotEnabled):
+		statusCode = http.StatusForbidden
+		errorMsg = messages.MsgTwoFactorSetupRequired
+	default:
+		logger.L.Errorw("Step-up verification failed", "userID", userID, "error", err.Error())
+		statusCode = http.StatusInternalServerError
+		errorMsg = messages.MsgInternalError
+	}
+
+	releaseIdempotencyKey(c)
+	c.AbortWithStatusJSON(statusCode, models.Response{
+		Success: false,
+		Error:   errorMsg,
+	})
+}
@@ -0,0 +1,2 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS counterparty_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS exchange_quotes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    from_currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    to_currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    to_amount BIGINT NOT NULL CHECK (to_amount > 0),
+    rate NUMERIC NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_quotes_expires
+    ON exchange_quotes (expires_at)
+    WHERE used_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS holds;
+
+ALTER TABLE wallet_balances
+    DROP CONSTRAINT IF EXISTS wallet_balances_held_check,
+    DROP COLUMN IF EXISTS held_minor;
@@ -0,0 +1,20 @@
+ALTER TABLE wallet_balances
+    ADD COLUMN IF NOT EXISTS held_minor BIGINT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT wallet_balances_held_check CHECK (held_minor >= 0 AND held_minor <= amount_minor);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured BIGINT NOT NULL DEFAULT 0 CHECK (captured >= 0 AND captured <= amount),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_active_expires
+    ON holds (expires_at)
+    WHERE status = 'active';
@@ -0,0 +1,84 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL,
+    wallet_id UUID REFERENCES wallets(id) ON DELETE RESTRICT,
+    currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((kind = 'wallet') = (wallet_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ledger_accounts_wallet
+    ON ledger_accounts (wallet_id, currency)
+    WHERE wallet_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ledger_accounts_system
+    ON ledger_accounts (kind, currency)
+    WHERE wallet_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id UUID PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    transaction_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS journal_lines (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    currency VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_lines_entry ON journal_lines (entry_id);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_account ON journal_lines (account_id);
+
+-- Проводка должна быть сбалансирована по каждой валюте к моменту фиксации транзакции.
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM journal_lines
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_entry_balanced ON journal_lines;
+CREATE CONSTRAINT TRIGGER trg_journal_entry_balanced
+    AFTER INSERT ON journal_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Существующие остатки переносятся в журнал вступительными проводками
+-- против системного счёта пополнений.
+INSERT INTO ledger_accounts (kind, currency)
+SELECT 'cash_in', code FROM currencies
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ledger_accounts (kind, wallet_id, currency)
+SELECT 'wallet', wallet_id, currency FROM wallet_balances
+ON CONFLICT DO NOTHING;
+
+CREATE TEMP TABLE opening_entries AS
+SELECT uuid_generate_v4() AS entry_id, b.wallet_id, b.currency, b.amount_minor
+FROM wallet_balances b
+WHERE b.amount_minor > 0;
+
+INSERT INTO journal_entries (id, type)
+SELECT entry_id, 'opening_balance' FROM opening_entries;
+
+INSERT INTO journal_lines (entry_id, account_id, currency, amount)
+SELECT o.entry_id, a.id, o.currency, o.amount_minor
+FROM opening_entries o
+JOIN ledger_accounts a ON a.wallet_id = o.wallet_id AND a.currency = o.currency
+UNION ALL
+SELECT o.entry_id, a.id, o.currency, -o.amount_minor
+FROM opening_entries o
+JOIN ledger_accounts a ON a.kind = 'cash_in' AND a.wallet_id IS NULL AND a.currency = o.currency;
+
+DROP TABLE opening_entries;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_log;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,20 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('user', 'support', 'admin'));
+
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    action VARCHAR(32) NOT NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    wallet_id UUID REFERENCES wallets(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at DESC);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS login_failures (
+    id BIGSERIAL PRIMARY KEY,
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_subject ON login_failures (scope, subject, created_at);
+
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    level INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, subject)
+);
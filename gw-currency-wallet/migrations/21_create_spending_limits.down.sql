@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_user_type_created;
+DROP TABLE IF EXISTS spending_limits;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS spending_limits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    operation VARCHAR(20) NOT NULL,
+    currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    period VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Limits without user_id are system defaults.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spending_limits_default
+    ON spending_limits (operation, currency, period) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spending_limits_user
+    ON spending_limits (user_id, operation, currency, period) WHERE user_id IS NOT NULL;
+
+-- Spent amounts are summed over the user's transactions of the period.
+CREATE INDEX IF NOT EXISTS idx_transactions_user_type_created
+    ON transactions (user_id, type, from_currency, created_at);
+
+-- Defaults in minor units: 10 000 USD/EUR and 1 000 000 RUB of withdrawals a day.
+INSERT INTO spending_limits (operation, currency, period, amount)
+SELECT d.operation, d.currency, d.period, d.amount
+FROM (VALUES
+    ('withdraw', 'USD', 'daily', 1000000),
+    ('withdraw', 'USD', 'monthly', 10000000),
+    ('withdraw', 'EUR', 'daily', 1000000),
+    ('withdraw', 'EUR', 'monthly', 10000000),
+    ('withdraw', 'RUB', 'daily', 100000000),
+    ('withdraw', 'RUB', 'monthly', 1000000000),
+    ('exchange', 'USD', 'daily', 5000000),
+    ('exchange', 'EUR', 'daily', 5000000),
+    ('exchange', 'RUB', 'daily', 500000000)
+) AS d (operation, currency, period, amount)
+JOIN currencies c ON c.code = d.currency
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS status_history;
+
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE wallets SET frozen = TRUE WHERE status <> 'active';
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS status;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
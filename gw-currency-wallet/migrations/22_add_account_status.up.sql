@@ -0,0 +1,25 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'debit_blocked', 'frozen', 'closed'));
+
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'debit_blocked', 'frozen', 'closed'));
+
+UPDATE wallets SET status = 'frozen' WHERE frozen;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen;
+
+-- wallet_id is NULL for changes of the account status.
+CREATE TABLE IF NOT EXISTS status_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID REFERENCES wallets(id) ON DELETE CASCADE,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_user ON status_history (user_id, created_at DESC);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_wallets_name;
+DROP INDEX IF EXISTS idx_wallets_default;
+
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS is_default,
+    DROP COLUMN IF EXISTS name;
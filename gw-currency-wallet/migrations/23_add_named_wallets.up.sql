@@ -0,0 +1,11 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS name VARCHAR(64) NOT NULL DEFAULT 'main',
+    ADD COLUMN IF NOT EXISTS is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- The wallet every user already has becomes their default one.
+UPDATE wallets SET is_default = TRUE
+WHERE id IN (SELECT DISTINCT ON (user_id) id FROM wallets ORDER BY user_id, updated_at, id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_default ON wallets (user_id) WHERE is_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_name ON wallets (user_id, lower(name)) WHERE status <> 'closed';
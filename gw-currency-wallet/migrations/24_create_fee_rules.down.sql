@@ -0,0 +1,3 @@
+ALTER TABLE exchange_quotes DROP COLUMN IF EXISTS fee;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee;
+DROP TABLE IF EXISTS fee_rules;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    operation VARCHAR(20) NOT NULL,
+    from_currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    to_currency VARCHAR(10) REFERENCES currencies(code),
+    percent NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (percent >= 0 AND percent < 100),
+    fixed BIGINT NOT NULL DEFAULT 0 CHECK (fixed >= 0),
+    min_fee BIGINT NOT NULL DEFAULT 0 CHECK (min_fee >= 0),
+    max_fee BIGINT CHECK (max_fee >= min_fee),
+    min_volume BIGINT NOT NULL DEFAULT 0 CHECK (min_volume >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- One rule per volume tier; rules without to_currency match any target currency.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fee_rules_tier
+    ON fee_rules (operation, from_currency, COALESCE(to_currency, ''), min_volume);
+
+-- Fees in minor units of the debited currency.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE exchange_quotes ADD COLUMN IF NOT EXISTS fee BIGINT NOT NULL DEFAULT 0;
+
+-- Fees are credited to the wallet of a system user that cannot log in:
+-- '!' is never a valid bcrypt hash.
+INSERT INTO users (id, username, email, password_hash, email_verified_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'system:house', 'house@wallet.invalid', '!', NOW())
+ON CONFLICT DO NOTHING;
+
+INSERT INTO wallets (id, user_id, name, is_default)
+VALUES ('00000000-0000-0000-0000-000000000001', '00000000-0000-0000-0000-000000000001', 'revenue', TRUE)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('transfer', 'exchange')),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id UUID REFERENCES wallets(id) ON DELETE CASCADE,
+    currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    to_currency VARCHAR(10) REFERENCES currencies(code),
+    cron VARCHAR(100) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused')),
+    failures INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_user ON scheduled_transfers (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (next_run_at) WHERE status = 'active';
+
+-- The id is the execution id derived from the schedule and the planned time:
+-- a run is recorded in the same transaction as its money movement, so after a
+-- restart the same run is never executed again.
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_schedule ON schedule_runs (schedule_id, scheduled_for DESC);
@@ -0,0 +1,31 @@
+-- A limit order reserves its amount and the fee with a hold that expires
+-- together with the order; filling the order releases the hold and runs the
+-- exchange in one transaction.
+CREATE TABLE IF NOT EXISTS limit_orders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    hold_id UUID NOT NULL REFERENCES holds(id) ON DELETE CASCADE,
+    from_currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    to_currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    target_rate NUMERIC NOT NULL CHECK (target_rate > 0),
+    fee BIGINT NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'filled', 'cancelled', 'expired', 'failed')),
+    filled_rate NUMERIC,
+    to_amount BIGINT,
+    error TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (from_currency <> to_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_limit_orders_user ON limit_orders (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_limit_orders_open
+    ON limit_orders (from_currency, to_currency, target_rate)
+    WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_limit_orders_expires
+    ON limit_orders (expires_at)
+    WHERE status = 'open';
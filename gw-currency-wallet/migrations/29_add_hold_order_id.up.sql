@@ -0,0 +1,6 @@
+-- A hold that reserves a limit order belongs to the order: the hold API can
+-- neither capture nor void it, only the order service releases it. The hold is
+-- created before its order, so the column has no foreign key.
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS order_id UUID;
+
+UPDATE holds h SET order_id = o.id FROM limit_orders o WHERE o.hold_id = h.id;
@@ -0,0 +1,12 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS usd BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rub BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS eur BIGINT NOT NULL DEFAULT 0;
+
+UPDATE wallets w SET
+    usd = COALESCE((SELECT amount_minor FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'USD'), 0),
+    rub = COALESCE((SELECT amount_minor FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'RUB'), 0),
+    eur = COALESCE((SELECT amount_minor FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'EUR'), 0);
+
+DROP TABLE IF EXISTS wallet_balances;
+DROP TABLE IF EXISTS currencies;
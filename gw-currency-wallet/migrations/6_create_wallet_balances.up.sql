@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS currencies (
+    code VARCHAR(10) PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+INSERT INTO currencies (code)
+VALUES ('USD'), ('RUB'), ('EUR')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS wallet_balances (
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    currency VARCHAR(10) NOT NULL REFERENCES currencies(code),
+    amount_minor BIGINT NOT NULL DEFAULT 0 CHECK (amount_minor >= 0),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, currency)
+);
+
+INSERT INTO wallet_balances (wallet_id, currency, amount_minor)
+SELECT id, 'USD', usd FROM wallets
+UNION ALL
+SELECT id, 'RUB', rub FROM wallets
+UNION ALL
+SELECT id, 'EUR', eur FROM wallets
+ON CONFLICT (wallet_id, currency) DO NOTHING;
+
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS usd,
+    DROP COLUMN IF EXISTS rub,
+    DROP COLUMN IF EXISTS eur;
+
+ALTER TABLE transactions
+    ALTER COLUMN from_currency TYPE VARCHAR(10),
+    ALTER COLUMN to_currency TYPE VARCHAR(10);
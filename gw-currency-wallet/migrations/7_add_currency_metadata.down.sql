@@ -0,0 +1,4 @@
+ALTER TABLE currencies
+    DROP COLUMN IF EXISTS minor_units,
+    DROP COLUMN IF EXISTS symbol,
+    DROP COLUMN IF EXISTS enabled;
@@ -0,0 +1,16 @@
+ALTER TABLE currencies
+    ADD COLUMN IF NOT EXISTS minor_units SMALLINT NOT NULL DEFAULT 2 CHECK (minor_units BETWEEN 0 AND 18),
+    ADD COLUMN IF NOT EXISTS symbol VARCHAR(8) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE currencies SET symbol = '$' WHERE code = 'USD';
+UPDATE currencies SET symbol = '€' WHERE code = 'EUR';
+UPDATE currencies SET symbol = '₽' WHERE code = 'RUB';
+
+INSERT INTO currencies (code, minor_units, symbol)
+VALUES
+    ('JPY', 0, '¥'),
+    ('KWD', 3, 'KD'),
+    ('BTC', 8, '₿')
+ON CONFLICT (code) DO UPDATE
+SET minor_units = EXCLUDED.minor_units, symbol = EXCLUDED.symbol;
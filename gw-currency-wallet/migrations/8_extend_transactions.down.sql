@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_user_created;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS wallet_id,
+    DROP COLUMN IF EXISTS type,
+    DROP COLUMN IF EXISTS to_amount;
@@ -0,0 +1,21 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS wallet_id UUID REFERENCES wallets(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS type VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS to_amount BIGINT;
+
+UPDATE transactions t
+SET wallet_id = w.id
+FROM wallets w
+WHERE w.user_id = t.user_id AND t.wallet_id IS NULL;
+
+UPDATE transactions
+SET type = CASE WHEN to_currency IS NULL THEN 'deposit' ELSE 'exchange' END
+WHERE type IS NULL;
+
+ALTER TABLE transactions
+    ALTER COLUMN wallet_id SET NOT NULL,
+    ALTER COLUMN type SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created
+    ON transactions (user_id, created_at DESC, id DESC);
@@ -8,6 +8,7 @@ import (
 	"gw-notification/internal/repository"
 
 	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type Consumer struct {
@@ -49,10 +50,21 @@ func (c *Consumer) Start(ctx context.Context) error {
 		}
 
 		if err := c.repository.SaveEvents(ctx, evt); err != nil {
+			// Кошелёк доставляет события как минимум один раз: повтор уже сохранён.
+			if mongo.IsDuplicateKeyError(err) {
+				logger.L.Infow("Duplicate event skipped", "event_id", evt.EventID)
+				continue
+			}
+
 			logger.L.Errorw("Mongo save error", "event_id", evt.EventID, "error", err.Error())
 			continue
 		}
 
+		if evt.Event == models.LoginLockout {
+			logger.L.Warnw("Security event saved", "event_id", evt.EventID, "user_id", evt.UserID, "details", evt.Details)
+			continue
+		}
+
 		logger.L.Infow("Event saved", "event_id", evt.EventID, "user_id", evt.UserID)
 	}
 }
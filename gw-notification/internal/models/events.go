@@ -12,6 +12,15 @@ const (
 	Deposit  EventType = "deposit"
 	Withdraw EventType = "withdraw"
 	Exchange EventType = "exchange"
+	Transfer EventType = "transfer"
+
+	LoginLockout EventType = "login_lockout"
+
+	OrderPlaced    EventType = "order_placed"
+	OrderFilled    EventType = "order_filled"
+	OrderCancelled EventType = "order_cancelled"
+	OrderExpired   EventType = "order_expired"
+	OrderFailed    EventType = "order_failed"
 )
 
 type EventMessage struct {
@@ -19,8 +28,15 @@ type EventMessage struct {
 	Event     EventType `json:"event" bson:"event"`
 	UserID    uuid.UUID `json:"user_id" bson:"user_id"`
 	WalletID  uuid.UUID `json:"wallet_id" bson:"wallet_id"`
-	Amount    float64   `json:"amount" bson:"amount"`
+	Amount    Money     `json:"amount" bson:"amount"`
 	Currency  string    `json:"currency" bson:"currency"`
 	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
 	Details   string    `json:"details,omitempty" bson:"details,omitempty"`
 }
+
+// Money is an amount as sent by gw-currency-wallet: the amount is an exact
+// decimal string and is stored as is.
+type Money struct {
+	Currency string `json:"currency" bson:"currency"`
+	Amount   string `json:"amount" bson:"amount"`
+}